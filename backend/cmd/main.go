@@ -15,6 +15,7 @@ import (
 	"chaladshare_backend/internal/connect"
 	"chaladshare_backend/internal/connectdb"
 	"chaladshare_backend/internal/middleware"
+	"chaladshare_backend/internal/pagination"
 
 	AuthHandler "chaladshare_backend/internal/auth/handlers"
 	AuthRepo "chaladshare_backend/internal/auth/repository"
@@ -36,9 +37,56 @@ import (
 	FriendsRepo "chaladshare_backend/internal/friends/repository"
 	FriendsService "chaladshare_backend/internal/friends/service"
 
+	FeatureHandler "chaladshare_backend/internal/docfeatures/handlers"
 	FeatureRepo "chaladshare_backend/internal/docfeatures/repository"
 	FeatureService "chaladshare_backend/internal/docfeatures/service"
 
+	TopicHandler "chaladshare_backend/internal/topics/handlers"
+	TopicRepo "chaladshare_backend/internal/topics/repository"
+	TopicService "chaladshare_backend/internal/topics/service"
+
+	TagHandler "chaladshare_backend/internal/tags/handlers"
+	TagRepo "chaladshare_backend/internal/tags/repository"
+	TagService "chaladshare_backend/internal/tags/service"
+
+	AnalyticsHandler "chaladshare_backend/internal/analytics/handlers"
+	AnalyticsModels "chaladshare_backend/internal/analytics/models"
+	AnalyticsRepo "chaladshare_backend/internal/analytics/repository"
+	AnalyticsService "chaladshare_backend/internal/analytics/service"
+
+	TrendingHandler "chaladshare_backend/internal/trending/handlers"
+	TrendingRepo "chaladshare_backend/internal/trending/repository"
+	TrendingService "chaladshare_backend/internal/trending/service"
+
+	ReportHandler "chaladshare_backend/internal/reports/handlers"
+	ReportRepo "chaladshare_backend/internal/reports/repository"
+	ReportService "chaladshare_backend/internal/reports/service"
+
+	NotificationHandler "chaladshare_backend/internal/notifications/handlers"
+	NotificationRepo "chaladshare_backend/internal/notifications/repository"
+	NotificationService "chaladshare_backend/internal/notifications/service"
+
+	AnnotationHandler "chaladshare_backend/internal/annotations/handlers"
+	AnnotationRepo "chaladshare_backend/internal/annotations/repository"
+	AnnotationService "chaladshare_backend/internal/annotations/service"
+
+	CommentHandler "chaladshare_backend/internal/comments/handlers"
+	CommentRepo "chaladshare_backend/internal/comments/repository"
+	CommentService "chaladshare_backend/internal/comments/service"
+
+	AudienceHandler "chaladshare_backend/internal/audiences/handlers"
+	AudienceRepo "chaladshare_backend/internal/audiences/repository"
+	AudienceService "chaladshare_backend/internal/audiences/service"
+
+	CollectionHandler "chaladshare_backend/internal/collections/handlers"
+	CollectionRepo "chaladshare_backend/internal/collections/repository"
+	CollectionService "chaladshare_backend/internal/collections/service"
+
+	ExperimentHandler "chaladshare_backend/internal/experiments/handlers"
+	ExperimentModels "chaladshare_backend/internal/experiments/models"
+	ExperimentRepo "chaladshare_backend/internal/experiments/repository"
+	ExperimentService "chaladshare_backend/internal/experiments/service"
+
 	RecommendHandler "chaladshare_backend/internal/recommend/handlers"
 	RecommendRepo "chaladshare_backend/internal/recommend/repository"
 	RecommendService "chaladshare_backend/internal/recommend/service"
@@ -124,16 +172,52 @@ func main() {
 	}
 
 	featureRepository := FeatureRepo.NewFeatureRepo(db.GetDB())
-	featureService := FeatureService.NewFeatureService(featureRepository, aiClient)
+	featureService := FeatureService.NewFeatureService(featureRepository, aiClient, cfg.ClusterK)
+	featureHandler := FeatureHandler.NewFeatureHandler(featureService)
+
+	// k-means clustering ของเอกสาร (content_embedding → cluster_id) เป็นระยะ
+	go func() {
+		interval := time.Duration(cfg.ClusterRefreshMinutes) * time.Minute
+		if interval <= 0 {
+			interval = 6 * time.Hour
+		}
+		for {
+			if runs, err := featureService.RunClustering(); err != nil {
+				log.Printf("clustering failed: %v", err)
+			} else {
+				log.Printf("clustering done: %d label(s)", len(runs))
+			}
+			time.Sleep(interval)
+		}
+	}()
 
 	// file
 	fileRepository := FileRepo.NewFileRepository(db.GetDB())
 	fileService := FileService.NewFileService(fileRepository, featureService)
 	fileHandler := FileHandler.NewFileHandler(fileService)
 
+	// A/B experiments (ranking strategies)
+	experimentRepository := ExperimentRepo.NewExperimentRepository(db.GetDB())
+	experimentService := ExperimentService.NewExperimentService(experimentRepository)
+	experimentHandler := ExperimentHandler.NewExperimentHandler(experimentService)
+
+	// annotation บนหน้าเอกสาร
+	annotationRepository := AnnotationRepo.NewAnnotationRepository(db.GetDB())
+	annotationService := AnnotationService.NewAnnotationService(annotationRepository)
+	annotationHandler := AnnotationHandler.NewAnnotationHandler(annotationService)
+
+	// audience lists (โพสต์ visibility = list)
+	audienceRepository := AudienceRepo.NewAudienceRepository(db.GetDB())
+	audienceService := AudienceService.NewAudienceService(audienceRepository)
+	audienceHandler := AudienceHandler.NewAudienceHandler(audienceService)
+
 	// post like save
 	postRepository := PostRepo.NewPostRepository(db.GetDB())
-	postService := PostService.NewPostService(postRepository, friendsService)
+	cursorCodec := pagination.NewCodec(cfg.CursorSecret)
+	postService := PostService.NewPostService(postRepository, friendsService, cursorCodec)
+
+	feedRepository := PostRepo.NewFeedRepository(db.GetDB())
+	feedService := PostService.NewFeedService(feedRepository, postRepository, cursorCodec)
 
 	likeRepository := PostRepo.NewLikeRepository(db.GetDB())
 	likeService := PostService.NewLikeService(likeRepository)
@@ -141,17 +225,185 @@ func main() {
 	saveRepository := PostRepo.NewSaveRepository(db.GetDB())
 	saveService := PostService.NewSaveService(saveRepository)
 
-	postHandler := PostHandler.NewPostHandler(postService, likeService, saveService)
+	repostRepository := PostRepo.NewRepostRepository(db.GetDB())
+	repostService := PostService.NewRepostService(repostRepository, postService, cursorCodec)
+
+	// reaction (like = ค่าเริ่มต้น ใช้ตาราง likes)
+	reactionRepository := PostRepo.NewReactionRepository(db.GetDB())
+	reactionService := PostService.NewReactionService(reactionRepository, likeRepository, postService)
+
+	postHandler := PostHandler.NewPostHandler(postService, likeService, saveService, repostService, reactionService, feedService, experimentService)
+
+	// เผยแพร่โพสต์ scheduled ที่ถึงเวลา ทุกนาที
+	go func() {
+		for {
+			if n, err := postService.PublishDue(); err != nil {
+				log.Printf("publish scheduled posts failed: %v", err)
+			} else if n > 0 {
+				log.Printf("published %d scheduled post(s)", n)
+			}
+			time.Sleep(time.Minute)
+		}
+	}()
+
+	// ลบโพสต์ / ไฟล์ที่อยู่ในถังขยะครบกำหนดจริง ทุกชั่วโมง
+	go func() {
+		for {
+			if n, err := postService.PurgeTrash(); err != nil {
+				log.Printf("purge trashed posts failed: %v", err)
+			} else if n > 0 {
+				log.Printf("purged %d trashed post(s)", n)
+			}
+			n, err := fileService.PurgeTrash()
+			if err != nil {
+				log.Printf("purge trashed files failed: %v", err)
+			}
+			if n > 0 {
+				log.Printf("purged %d trashed file(s)", n)
+			}
+			time.Sleep(time.Hour)
+		}
+	}()
+
+	// ซ่อมตัวนับใน post_stats ที่เพี้ยน (like / save / comment / repost) ทุกชั่วโมง
+	go func() {
+		for {
+			time.Sleep(time.Hour)
+			if n, err := postService.ReconcileStats(); err != nil {
+				log.Printf("reconcile post_stats failed: %v", err)
+			} else if n > 0 {
+				log.Printf("reconciled post_stats of %d post(s)", n)
+			}
+		}
+	}()
+
+	// comments + replies
+	commentRepository := CommentRepo.NewCommentRepository(db.GetDB())
+	commentService := CommentService.NewCommentService(commentRepository, postService, cursorCodec)
+	commentHandler := CommentHandler.NewCommentHandler(commentService)
+
+	// collection ของโพสต์ที่บันทึก
+	collectionRepository := CollectionRepo.NewCollectionRepository(db.GetDB())
+	collectionService := CollectionService.NewCollectionService(collectionRepository, postService, cursorCodec)
+	collectionHandler := CollectionHandler.NewCollectionHandler(collectionService)
 
 	// user
 	userRepository := UserRepo.NewUserRepository(db.GetDB())
 	userService := UserService.NewUserService(userRepository)
 	userHandler := UserHandler.NewUserHandler(userService, postService, friendsService)
 
+	// topics + interests (onboarding)
+	topicRepository := TopicRepo.NewTopicRepository(db.GetDB())
+	topicService := TopicService.NewTopicService(topicRepository)
+	topicHandler := TopicHandler.NewTopicHandler(topicService)
+
+	// หน้าแท็ก + ติดตามแท็ก (แท็กที่ติดตามเป็นแหล่งโพสต์ของ ranked feed)
+	tagRepository := TagRepo.NewTagRepository(db.GetDB())
+	tagService := TagService.NewTagService(tagRepository, postService, cursorCodec)
+	tagHandler := TagHandler.NewTagHandler(tagService)
+
+	// สถิติโพสต์สำหรับผู้เขียน (view / download events + rollup รายวัน)
+	analyticsRepository := AnalyticsRepo.NewAnalyticsRepository(db.GetDB())
+	analyticsService := AnalyticsService.NewAnalyticsService(analyticsRepository, postService)
+	analyticsHandler := AnalyticsHandler.NewAnalyticsHandler(analyticsService)
+	trackView := analyticsHandler.TrackViewOnSuccess()
+
+	// rollup ย้อนหลังเต็มช่วงเก็บ event ตอนเริ่ม จากนั้นรีเฟรช 2 วันล่าสุดทุก 15 นาที
+	go func() {
+		days := AnalyticsModels.EventRetentionDays
+		for {
+			if err := analyticsService.Rollup(days); err != nil {
+				log.Printf("rollup post analytics failed: %v", err)
+			} else {
+				days = 2
+			}
+			if n, err := analyticsService.PurgeEvents(); err != nil {
+				log.Printf("purge view events failed: %v", err)
+			} else if n > 0 {
+				log.Printf("purged %d old view event(s)", n)
+			}
+			time.Sleep(15 * time.Minute)
+		}
+	}()
+
+	// trending posts / tags (คะแนนลดตามเวลา รีเฟรชลง summary table)
+	trendingRepository := TrendingRepo.NewTrendingRepository(db.GetDB())
+	trendingService := TrendingService.NewTrendingService(trendingRepository, postService, cursorCodec)
+	trendingHandler := TrendingHandler.NewTrendingHandler(trendingService)
+
+	go func() {
+		interval := time.Duration(cfg.TrendingRefreshMinutes) * time.Minute
+		if interval <= 0 {
+			interval = 15 * time.Minute
+		}
+		for {
+			if n, err := trendingService.RefreshAll(); err != nil {
+				log.Printf("refresh trending failed: %v", err)
+			} else if n > 0 {
+				log.Printf("refresh trending: %d post score(s)", n)
+			}
+			time.Sleep(interval)
+		}
+	}()
+
+	// รายงานเนื้อหา + คิว moderation (ผลการพิจารณาแจ้งผู้รายงานผ่าน notifications)
+	reportRepository := ReportRepo.NewReportRepository(db.GetDB())
+	reportService := ReportService.NewReportService(reportRepository, postService, cursorCodec, cfg.ReportAutoHideThreshold)
+	reportHandler := ReportHandler.NewReportHandler(reportService)
+
+	notificationRepository := NotificationRepo.NewNotificationRepository(db.GetDB())
+	notificationService := NotificationService.NewNotificationService(notificationRepository, cursorCodec)
+	notificationHandler := NotificationHandler.NewNotificationHandler(notificationService)
+
 	// recommend
 	recommendRepo := RecommendRepo.NewRecommendRepo(db.GetDB())
-	recommendService := RecommendService.NewRecommendService(recommendRepo)
-	recommendHandler := RecommendHandler.NewRecommendHandler(recommendService)
+	recommendService := RecommendService.NewRecommendService(recommendRepo, RecommendService.BlendWeights{
+		Content:       cfg.RecommendContentWeight,
+		Collaborative: cfg.RecommendCFWeight,
+	}, time.Duration(cfg.RecommendCacheTTLMinutes)*time.Minute, func(userID int) (RecommendService.BlendWeights, bool) {
+		// variant config: {"content_weight": 0.5, "cf_weight": 0.5}
+		a := experimentService.Assign(userID, ExperimentModels.SurfaceRecommend)
+		content, okContent := a.Float("content_weight")
+		cf, okCF := a.Float("cf_weight")
+		if !okContent && !okCF {
+			return RecommendService.BlendWeights{}, false
+		}
+		if !okContent {
+			content = cfg.RecommendContentWeight
+		}
+		if !okCF {
+			cf = cfg.RecommendCFWeight
+		}
+		return RecommendService.BlendWeights{Content: content, Collaborative: cf}, true
+	}, cursorCodec)
+	recommendHandler := RecommendHandler.NewRecommendHandler(recommendService, experimentService)
+	invalidateRecommend := recommendHandler.InvalidateOnSuccess()
+
+	// เริ่ม/หยุด experiment บน recommend: ผลใน cache จัดอันดับด้วยน้ำหนักเดิม ต้องสร้างใหม่
+	experimentService.OnStatusChange(func(surface string) {
+		if surface == ExperimentModels.SurfaceRecommend {
+			recommendService.InvalidateAll()
+		}
+	})
+
+	// refresh cache ผลแนะนำที่ถูก invalidate / หมดอายุ
+	go recommendService.RunCacheWorker()
+
+	// refresh post_similarity (collaborative filtering) เป็นระยะ
+	go func() {
+		interval := time.Duration(cfg.RecommendCFRefreshMinutes) * time.Minute
+		if interval <= 0 {
+			interval = 30 * time.Minute
+		}
+		for {
+			if n, err := recommendService.RefreshSimilarity(); err != nil {
+				log.Printf("refresh post_similarity failed: %v", err)
+			} else if n > 0 {
+				log.Printf("refresh post_similarity: %d pair(s)", n)
+			}
+			time.Sleep(interval)
+		}
+	}()
 
 	go func() {
 		for {
@@ -237,33 +489,94 @@ func main() {
 	}
 	// Protected (ต้องมี JWT)
 	protected := v1.Group("/")
-	protected.Use(middleware.JWT([]byte(cfg.JWTSecret), cfg.CookieName))
+	protected.Use(middleware.JWT([]byte(cfg.JWTSecret), cfg.CookieName), middleware.RequireActive(db.GetDB()))
 	{
 		posts := protected.Group("/posts")
 		{
 			posts.GET("", postHandler.GetAllPosts)
-			posts.GET("/:id", postHandler.GetPostByID)
+			posts.GET("/:id", trackView, postHandler.GetPostByID)
+			posts.POST("/:id/download", analyticsHandler.Download)
+			posts.GET("/:id/similar", postHandler.GetSimilarPosts)
+			posts.GET("/:id/comments", commentHandler.ListComments)
+			posts.POST("/:id/comments", commentHandler.CreateComment)
 
 			posts.POST("", postHandler.CreatePost)
 			posts.PUT("/:id", postHandler.UpdatePost)
 			posts.DELETE("/:id", postHandler.DeletePost)
-
-			posts.POST("/:id/like", postHandler.ToggleLike)
-			posts.POST("/:id/save", postHandler.ToggleSave)
+			posts.GET("/:id/revisions", postHandler.ListRevisions)
+			posts.POST("/:id/revisions/:revision_id/restore", postHandler.RestoreRevision)
+
+			posts.POST("/:id/like", invalidateRecommend, postHandler.ToggleLike)
+			posts.PUT("/:id/like", invalidateRecommend, postHandler.LikePost)
+			posts.DELETE("/:id/like", invalidateRecommend, postHandler.UnlikePost)
+			posts.POST("/:id/save", invalidateRecommend, postHandler.ToggleSave)
+			posts.PUT("/:id/save", invalidateRecommend, postHandler.SavePost)
+			posts.DELETE("/:id/save", invalidateRecommend, postHandler.UnsavePost)
+			posts.PUT("/:id/reactions/:type", invalidateRecommend, postHandler.AddReaction)
+			posts.DELETE("/:id/reactions/:type", invalidateRecommend, postHandler.RemoveReaction)
+			posts.POST("/:id/repost", postHandler.Repost)
+			posts.DELETE("/:id/repost", postHandler.Unrepost)
+			posts.GET("/:id/reposts", postHandler.ListReposts)
 			posts.GET("/save", postHandler.GetSavedPosts)
+			posts.GET("/drafts", postHandler.GetDraftPosts)
+			posts.GET("/trash", postHandler.GetTrashedPosts)
+			posts.POST("/:id/restore", postHandler.RestorePost)
 			/* 20-02 by ploy */
 			posts.GET("/popular", postHandler.GetPopularPosts)
+			posts.GET("/trending", trendingHandler.ListPosts)
 			posts.GET("/search", postHandler.SearchPosts)
 			/* 20-02 by ploy */
 
 		}
 
+		audiences := protected.Group("/audience-lists")
+		{
+			audiences.GET("", audienceHandler.ListLists)
+			audiences.POST("", audienceHandler.CreateList)
+			audiences.PUT("/:id", audienceHandler.RenameList)
+			audiences.DELETE("/:id", audienceHandler.DeleteList)
+			audiences.GET("/:id/members", audienceHandler.ListMembers)
+			audiences.PUT("/:id/members/:user_id", audienceHandler.AddMember)
+			audiences.DELETE("/:id/members/:user_id", audienceHandler.RemoveMember)
+		}
+
+		collections := protected.Group("/collections")
+		{
+			collections.GET("", collectionHandler.ListMine)
+			collections.POST("", collectionHandler.CreateCollection)
+			collections.GET("/user/:user_id", collectionHandler.ListByUser)
+			collections.GET("/:id", collectionHandler.GetCollection)
+			collections.PUT("/:id", collectionHandler.UpdateCollection)
+			collections.DELETE("/:id", collectionHandler.DeleteCollection)
+			collections.GET("/:id/posts", collectionHandler.ListPosts)
+			collections.PUT("/:id/posts/:post_id", collectionHandler.MoveItem)
+			collections.DELETE("/:id/posts/:post_id", collectionHandler.RemoveItem)
+		}
+
+		comments := protected.Group("/comments")
+		{
+			comments.GET("/:id/replies", commentHandler.ListReplies)
+			comments.PUT("/:id", commentHandler.UpdateComment)
+			comments.DELETE("/:id", commentHandler.DeleteComment)
+		}
+
+		annotations := protected.Group("/annotations")
+		{
+			annotations.POST("/:id/replies", annotationHandler.Reply)
+			annotations.PUT("/:id", annotationHandler.UpdateAnnotation)
+			annotations.DELETE("/:id", annotationHandler.DeleteAnnotation)
+		}
+
 		files := protected.Group("/files")
 		{
 			files.POST("/doc", fileHandler.UploadFile)
 			files.GET("/user/:id", fileHandler.GetFilesByUserID)
 			files.GET("/:document_id/summary", fileHandler.GetSummaryByDocumentID)
 			files.DELETE("/:document_id", fileHandler.DeleteFile)
+			files.GET("/trash", fileHandler.GetTrash)
+			files.POST("/:document_id/restore", fileHandler.RestoreFile)
+			files.GET("/:document_id/annotations", annotationHandler.ListPage)
+			files.POST("/:document_id/annotations", annotationHandler.CreateAnnotation)
 
 			files.POST("/cover", fileHandler.UploadCover)
 			files.POST("/avatar", fileHandler.UploadAvatar)
@@ -278,8 +591,8 @@ func main() {
 
 		social := protected.Group("/social")
 		{
-			social.POST("/follow", friendsHandler.FollowUser)
-			social.DELETE("/follow/:id", friendsHandler.UnfollowUser)
+			social.POST("/follow", invalidateRecommend, friendsHandler.FollowUser)
+			social.DELETE("/follow/:id", invalidateRecommend, friendsHandler.UnfollowUser)
 
 			social.GET("/friends/:id", friendsHandler.ListFriends)
 			social.GET("/followers/:id", friendsHandler.ListFollowers)
@@ -304,6 +617,78 @@ func main() {
 		recommend := protected.Group("/recommend")
 		{
 			recommend.GET("", recommendHandler.GetRecommend)
+			recommend.POST("/:post_id/feedback", invalidateRecommend, recommendHandler.AddFeedback)
+			recommend.GET("/feedback", recommendHandler.ListFeedback)
+			recommend.DELETE("/feedback/:id", invalidateRecommend, recommendHandler.DeleteFeedback)
+		}
+
+		protected.GET("/topics", topicHandler.ListTopics)
+
+		analytics := protected.Group("/analytics")
+		{
+			analytics.GET("/posts", analyticsHandler.ListMyPosts)
+			analytics.GET("/posts/:id", analyticsHandler.GetPost)
+		}
+
+		tags := protected.Group("/tags")
+		{
+			tags.GET("/trending", trendingHandler.ListTags)
+			tags.GET("/:name", tagHandler.GetTag)
+			tags.PUT("/:name/follow", tagHandler.FollowTag)
+			tags.DELETE("/:name/follow", tagHandler.UnfollowTag)
+		}
+
+		protected.POST("/experiments/:key/click", experimentHandler.RecordClick)
+
+		interests := protected.Group("/interests")
+		{
+			interests.GET("", topicHandler.ListMyInterests)
+			interests.PUT("", invalidateRecommend, topicHandler.SetMyInterests)
+			interests.GET("/tags", tagHandler.ListFollowedTags)
+		}
+
+		reports := protected.Group("/reports")
+		{
+			reports.POST("", reportHandler.CreateReport)
+			reports.GET("", reportHandler.ListMyReports)
+		}
+
+		notifications := protected.Group("/notifications")
+		{
+			notifications.GET("", notificationHandler.ListNotifications)
+			notifications.POST("/read-all", notificationHandler.MarkAllRead)
+			notifications.POST("/:id/read", notificationHandler.MarkRead)
+		}
+
+		// admin only
+		admin := protected.Group("/admin")
+		admin.Use(middleware.RequireAdmin(db.GetDB()))
+		{
+			admin.POST("/topics", topicHandler.CreateTopic)
+			admin.PUT("/topics/:id", topicHandler.UpdateTopic)
+			admin.DELETE("/topics/:id", topicHandler.DeleteTopic)
+
+			admin.POST("/clusters/run", featureHandler.RunClustering)
+			admin.GET("/clusters/runs", featureHandler.ListClusterRuns)
+
+			admin.GET("/experiments", experimentHandler.ListExperiments)
+			admin.POST("/experiments", experimentHandler.CreateExperiment)
+			admin.POST("/experiments/:id/start", experimentHandler.StartExperiment)
+			admin.POST("/experiments/:id/stop", experimentHandler.StopExperiment)
+			admin.GET("/experiments/:id/report", experimentHandler.GetReport)
+
+			admin.GET("/tags/aliases", tagHandler.ListAliases)
+			admin.PUT("/tags/aliases/:alias", tagHandler.SetAlias)
+			admin.DELETE("/tags/aliases/:alias", tagHandler.DeleteAlias)
+			admin.POST("/tags/:name/merge", tagHandler.MergeTags)
+			admin.GET("/tags/banned", tagHandler.ListBanned)
+			admin.PUT("/tags/banned/:name", tagHandler.BanTag)
+			admin.DELETE("/tags/banned/:name", tagHandler.UnbanTag)
+
+			admin.GET("/reports", reportHandler.ListQueue)
+			admin.GET("/reports/:type/:id", reportHandler.GetQueueItem)
+			admin.POST("/reports/:type/:id/resolve", reportHandler.Resolve)
+			admin.POST("/users/:id/reinstate", reportHandler.ReinstateUser)
 		}
 	}
 
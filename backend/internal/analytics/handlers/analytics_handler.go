@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"chaladshare_backend/internal/analytics/models"
+	"chaladshare_backend/internal/analytics/service"
+)
+
+type AnalyticsHandler struct {
+	analyticsService service.AnalyticsService
+}
+
+func NewAnalyticsHandler(analyticsService service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{analyticsService: analyticsService}
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrPostNotFound), errors.Is(err, models.ErrNoDocument):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func paramPostID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// ?days= (ไม่ถูกต้อง = ค่า default)
+func daysQuery(c *gin.Context) int {
+	days, err := strconv.Atoi(c.Query("days"))
+	if err != nil {
+		return 0
+	}
+	return days
+}
+
+// ใส่หน้า route เปิดดูโพสต์ ถ้า handler ตอบสำเร็จจะนับการเปิดดู (?src= = ที่มา)
+func (h *AnalyticsHandler) TrackViewOnSuccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if st := c.Writer.Status(); st >= 200 && st < 300 {
+			if id, err := strconv.Atoi(c.Param("id")); err == nil {
+				h.analyticsService.RecordView(c.GetInt("user_id"), id, c.Query("src"))
+			}
+		}
+	}
+}
+
+// POST /api/v1/posts/:id/download?src=
+// นับการดาวน์โหลดแล้วคืน URL ของไฟล์หลัก
+func (h *AnalyticsHandler) Download(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := paramPostID(c)
+	if !ok {
+		return
+	}
+	dl, err := h.analyticsService.RecordDownload(uid, postID, c.Query("src"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": dl})
+}
+
+// GET /api/v1/analytics/posts?days=30
+// ยอดรวมของทุกโพสต์ของตัวเอง
+func (h *AnalyticsHandler) ListMyPosts(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	posts, err := h.analyticsService.ListPostSummaries(uid, daysQuery(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": posts})
+}
+
+// GET /api/v1/analytics/posts/:id?days=30
+// รายวัน + ที่มาของการเปิดดู (เฉพาะผู้เขียน)
+func (h *AnalyticsHandler) GetPost(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := paramPostID(c)
+	if !ok {
+		return
+	}
+	stats, err := h.analyticsService.GetPostAnalytics(uid, postID, daysQuery(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
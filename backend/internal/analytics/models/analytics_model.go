@@ -0,0 +1,85 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPostNotFound = errors.New("post not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNoDocument   = errors.New("post has no document")
+)
+
+const (
+	KindView     = "view"
+	KindDownload = "download"
+)
+
+// ที่มาของการเปิดดู (?src=) ค่าอื่นนับเป็น other, ไม่ส่ง = direct
+const (
+	SourceDirect = "direct"
+	SourceOther  = "other"
+)
+
+var Sources = map[string]struct{}{
+	"feed": {}, "search": {}, "profile": {}, "tag": {}, "trending": {}, "popular": {},
+	"recommend": {}, "collection": {}, "share": {}, SourceDirect: {}, SourceOther: {},
+}
+
+const (
+	DefaultDays = 30
+	MaxDays     = 90
+
+	// เก็บ event ดิบไว้เท่านี้ (rollup เก็บถาวร)
+	EventRetentionDays = 90
+)
+
+type Totals struct {
+	Views        int `json:"views"`
+	Downloads    int `json:"downloads"`
+	Likes        int `json:"likes"`
+	Saves        int `json:"saves"`
+	FollowerGain int `json:"follower_gain"`
+}
+
+func (t *Totals) Add(o Totals) {
+	t.Views += o.Views
+	t.Downloads += o.Downloads
+	t.Likes += o.Likes
+	t.Saves += o.Saves
+	t.FollowerGain += o.FollowerGain
+}
+
+type DailyStat struct {
+	Day string `json:"day"` // YYYY-MM-DD
+	Totals
+}
+
+type SourceStat struct {
+	Source string `json:"source"`
+	Views  int    `json:"views"`
+}
+
+// สถิติของโพสต์เดียวในช่วง days วันล่าสุด (รวมวันนี้)
+type PostAnalytics struct {
+	PostID  int          `json:"post_id"`
+	Days    int          `json:"days"`
+	Totals  Totals       `json:"totals"`
+	Daily   []DailyStat  `json:"daily"`
+	Sources []SourceStat `json:"sources"`
+}
+
+// สรุปต่อโพสต์ของผู้เขียน
+type PostSummary struct {
+	PostID    int       `json:"post_id"`
+	Title     string    `json:"post_title"`
+	CreatedAt time.Time `json:"post_created_at"`
+	Totals
+}
+
+type Download struct {
+	DocumentID   *int    `json:"document_id"`
+	DocumentName *string `json:"document_name"`
+	FileURL      *string `json:"file_url"`
+}
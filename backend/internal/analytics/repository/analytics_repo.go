@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"chaladshare_backend/internal/analytics/models"
+)
+
+type AnalyticsRepository interface {
+	RecordEvent(userID, postID int, kind, source string) error
+	Rollup(days int) error
+	PurgeEvents(retentionDays int) (int, error)
+
+	ListPostSummaries(authorID, days int) ([]models.PostSummary, error)
+	GetDailyStats(postID, days int) ([]models.DailyStat, error)
+	GetSources(postID, days int) ([]models.SourceStat, error)
+}
+
+type analyticsRepository struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepository(db *sql.DB) AnalyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+// ไม่นับผู้เขียนเอง, ซ้ำในวันเดียวกันไม่นับ
+func (r *analyticsRepository) RecordEvent(userID, postID int, kind, source string) error {
+	if _, err := r.db.Exec(`
+		INSERT INTO post_view_events (event_post_id, event_user_id, event_kind, event_source)
+		SELECT p.post_id, $2, $3, $4
+		FROM posts p
+		WHERE p.post_id = $1 AND p.post_author_user_id <> $2
+		ON CONFLICT (event_post_id, event_user_id, event_kind, event_day) DO NOTHING;`,
+		postID, userID, kind, source); err != nil {
+		return fmt.Errorf("record %s event: %w", kind, err)
+	}
+	return nil
+}
+
+// $1 = วันแรกที่คำนวณใหม่
+// likes / saves นับตามแถวที่ยังอยู่ (เลิกไลก์แล้วหายจากวันที่ไลก์)
+const qRollupStats = `
+	INSERT INTO post_daily_stats (stat_post_id, stat_day, stat_views, stat_downloads, stat_likes, stat_saves, stat_follower_gain)
+	SELECT post_id, day, SUM(views), SUM(downloads), SUM(likes), SUM(saves), SUM(follows)
+	FROM (
+		SELECT e.event_post_id AS post_id, e.event_day AS day,
+			COUNT(*) FILTER (WHERE e.event_kind = 'view') AS views,
+			COUNT(*) FILTER (WHERE e.event_kind = 'download') AS downloads,
+			0 AS likes, 0 AS saves, 0 AS follows
+		FROM post_view_events e
+		WHERE e.event_day >= $1::date
+		GROUP BY 1, 2
+
+		UNION ALL
+		SELECT like_post_id, like_created_at::date, 0, 0, COUNT(*), 0, 0
+		FROM likes WHERE like_created_at >= $1::date
+		GROUP BY 1, 2
+
+		UNION ALL
+		SELECT save_post_id, save_created_at::date, 0, 0, 0, COUNT(*), 0
+		FROM saved_posts WHERE save_created_at >= $1::date
+		GROUP BY 1, 2
+
+		UNION ALL
+		SELECT e.event_post_id, f.follow_created_at::date, 0, 0, 0, 0, COUNT(DISTINCT f.follower_user_id)
+		FROM follows f
+		JOIN posts p ON p.post_author_user_id = f.followed_user_id
+		JOIN post_view_events e
+		  ON e.event_post_id = p.post_id
+		 AND e.event_user_id = f.follower_user_id
+		 AND e.event_kind = 'view'
+		 AND e.event_created_at <= f.follow_created_at
+		 AND e.event_created_at > f.follow_created_at - interval '1 day'
+		WHERE f.follow_created_at >= $1::date
+		GROUP BY 1, 2
+	) x
+	GROUP BY post_id, day;`
+
+const qRollupSources = `
+	INSERT INTO post_daily_sources (stat_post_id, stat_day, stat_source, stat_views)
+	SELECT event_post_id, event_day, event_source, COUNT(*)
+	FROM post_view_events
+	WHERE event_kind = 'view' AND event_day >= $1::date
+	GROUP BY 1, 2, 3;`
+
+// สร้าง rollup ของ days วันล่าสุด (รวมวันนี้) ใหม่ใน transaction เดียว
+func (r *analyticsRepository) Rollup(days int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var from string
+	if err := tx.QueryRow(`SELECT (current_date - $1::int + 1)::text`, days).Scan(&from); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM post_daily_stats WHERE stat_day >= $1`, from); err != nil {
+		return fmt.Errorf("clear post_daily_stats: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM post_daily_sources WHERE stat_day >= $1`, from); err != nil {
+		return fmt.Errorf("clear post_daily_sources: %w", err)
+	}
+	if _, err := tx.Exec(qRollupStats, from); err != nil {
+		return fmt.Errorf("rollup post_daily_stats: %w", err)
+	}
+	if _, err := tx.Exec(qRollupSources, from); err != nil {
+		return fmt.Errorf("rollup post_daily_sources: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+func (r *analyticsRepository) PurgeEvents(retentionDays int) (int, error) {
+	res, err := r.db.Exec(`DELETE FROM post_view_events WHERE event_day < current_date - $1::int`, retentionDays)
+	if err != nil {
+		return 0, fmt.Errorf("purge view events: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// โพสต์ที่เผยแพร่แล้วของผู้เขียน + ยอดรวมในช่วง days วันล่าสุด
+func (r *analyticsRepository) ListPostSummaries(authorID, days int) ([]models.PostSummary, error) {
+	rows, err := r.db.Query(`
+		SELECT p.post_id, p.post_title, p.post_created_at,
+			COALESCE(SUM(s.stat_views), 0), COALESCE(SUM(s.stat_downloads), 0),
+			COALESCE(SUM(s.stat_likes), 0), COALESCE(SUM(s.stat_saves), 0),
+			COALESCE(SUM(s.stat_follower_gain), 0)
+		FROM posts p
+		LEFT JOIN post_daily_stats s
+		  ON s.stat_post_id = p.post_id AND s.stat_day > current_date - $2::int
+		WHERE p.post_author_user_id = $1
+		  AND p.post_status = 'published'
+		  AND p.post_deleted_at IS NULL
+		GROUP BY p.post_id
+		ORDER BY 4 DESC, p.post_created_at DESC, p.post_id DESC;`, authorID, days)
+	if err != nil {
+		return nil, fmt.Errorf("list post analytics: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]models.PostSummary, 0)
+	for rows.Next() {
+		var s models.PostSummary
+		if err := rows.Scan(&s.PostID, &s.Title, &s.CreatedAt,
+			&s.Views, &s.Downloads, &s.Likes, &s.Saves, &s.FollowerGain); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// หนึ่งแถวต่อวัน วันที่ไม่มีข้อมูลเป็น 0
+func (r *analyticsRepository) GetDailyStats(postID, days int) ([]models.DailyStat, error) {
+	rows, err := r.db.Query(`
+		SELECT to_char(d, 'YYYY-MM-DD'),
+			COALESCE(s.stat_views, 0), COALESCE(s.stat_downloads, 0),
+			COALESCE(s.stat_likes, 0), COALESCE(s.stat_saves, 0),
+			COALESCE(s.stat_follower_gain, 0)
+		FROM generate_series(current_date - $2::int + 1, current_date, interval '1 day') d
+		LEFT JOIN post_daily_stats s ON s.stat_post_id = $1 AND s.stat_day = d::date
+		ORDER BY d;`, postID, days)
+	if err != nil {
+		return nil, fmt.Errorf("daily post analytics: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]models.DailyStat, 0, days)
+	for rows.Next() {
+		var d models.DailyStat
+		if err := rows.Scan(&d.Day, &d.Views, &d.Downloads, &d.Likes, &d.Saves, &d.FollowerGain); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *analyticsRepository) GetSources(postID, days int) ([]models.SourceStat, error) {
+	rows, err := r.db.Query(`
+		SELECT stat_source, SUM(stat_views)::int
+		FROM post_daily_sources
+		WHERE stat_post_id = $1 AND stat_day > current_date - $2::int
+		GROUP BY stat_source
+		ORDER BY 2 DESC, stat_source;`, postID, days)
+	if err != nil {
+		return nil, fmt.Errorf("post view sources: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]models.SourceStat, 0)
+	for rows.Next() {
+		var s models.SourceStat
+		if err := rows.Scan(&s.Source, &s.Views); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
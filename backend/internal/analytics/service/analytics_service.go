@@ -0,0 +1,135 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+
+	"chaladshare_backend/internal/analytics/models"
+	"chaladshare_backend/internal/analytics/repository"
+	postservice "chaladshare_backend/internal/posts/service"
+)
+
+type AnalyticsService interface {
+	RecordView(userID, postID int, source string)
+	RecordDownload(userID, postID int, source string) (*models.Download, error)
+
+	Rollup(days int) error
+	PurgeEvents() (int, error)
+
+	ListPostSummaries(authorID, days int) ([]models.PostSummary, error)
+	GetPostAnalytics(userID, postID, days int) (*models.PostAnalytics, error)
+}
+
+type analyticsService struct {
+	repo        repository.AnalyticsRepository
+	postService postservice.PostService
+}
+
+func NewAnalyticsService(repo repository.AnalyticsRepository, postService postservice.PostService) AnalyticsService {
+	return &analyticsService{repo: repo, postService: postService}
+}
+
+func normalizeSource(source string) string {
+	s := strings.ToLower(strings.TrimSpace(source))
+	if s == "" {
+		return models.SourceDirect
+	}
+	if _, ok := models.Sources[s]; !ok {
+		return models.SourceOther
+	}
+	return s
+}
+
+func clampDays(days int) int {
+	if days <= 0 {
+		return models.DefaultDays
+	}
+	if days > models.MaxDays {
+		return models.MaxDays
+	}
+	return days
+}
+
+// บันทึกเบื้องหลัง ไม่ให้ request เปิดโพสต์ช้าลง
+func (s *analyticsService) RecordView(userID, postID int, source string) {
+	if userID <= 0 || postID <= 0 {
+		return
+	}
+	source = normalizeSource(source)
+	go func() {
+		if err := s.repo.RecordEvent(userID, postID, models.KindView, source); err != nil {
+			log.Printf("record view (post %d): %v", postID, err)
+		}
+	}()
+}
+
+// ผู้ชมต้องเห็นโพสต์ได้ คืนไฟล์หลักของโพสต์ให้ดาวน์โหลด
+func (s *analyticsService) RecordDownload(userID, postID int, source string) (*models.Download, error) {
+	ok, reason, err := s.postService.ViewPost(userID, postID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if reason == "not_found" {
+			return nil, models.ErrPostNotFound
+		}
+		return nil, models.ErrForbidden
+	}
+
+	post, err := s.postService.GetPostByIDForViewer(userID, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, models.ErrPostNotFound
+	}
+	if post.DocumentID == nil || post.FileURL == nil {
+		return nil, models.ErrNoDocument
+	}
+
+	if err := s.repo.RecordEvent(userID, postID, models.KindDownload, normalizeSource(source)); err != nil {
+		return nil, err
+	}
+	return &models.Download{DocumentID: post.DocumentID, DocumentName: post.DocumentName, FileURL: post.FileURL}, nil
+}
+
+func (s *analyticsService) Rollup(days int) error {
+	return s.repo.Rollup(days)
+}
+
+func (s *analyticsService) PurgeEvents() (int, error) {
+	return s.repo.PurgeEvents(models.EventRetentionDays)
+}
+
+func (s *analyticsService) ListPostSummaries(authorID, days int) ([]models.PostSummary, error) {
+	return s.repo.ListPostSummaries(authorID, clampDays(days))
+}
+
+// เฉพาะผู้เขียนโพสต์
+func (s *analyticsService) GetPostAnalytics(userID, postID int, days int) (*models.PostAnalytics, error) {
+	owner, err := s.postService.IsOwner(postID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrPostNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !owner {
+		return nil, models.ErrForbidden
+	}
+
+	days = clampDays(days)
+	out := &models.PostAnalytics{PostID: postID, Days: days}
+	if out.Daily, err = s.repo.GetDailyStats(postID, days); err != nil {
+		return nil, err
+	}
+	if out.Sources, err = s.repo.GetSources(postID, days); err != nil {
+		return nil, err
+	}
+	for _, d := range out.Daily {
+		out.Totals.Add(d.Totals)
+	}
+	return out, nil
+}
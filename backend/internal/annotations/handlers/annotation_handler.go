@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"chaladshare_backend/internal/annotations/models"
+	"chaladshare_backend/internal/annotations/service"
+	"chaladshare_backend/internal/middleware"
+)
+
+type AnnotationHandler struct {
+	annotationService service.AnnotationService
+}
+
+func NewAnnotationHandler(annotationService service.AnnotationService) *AnnotationHandler {
+	return &AnnotationHandler{annotationService: annotationService}
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrAnnotationNotFound), errors.Is(err, models.ErrDocumentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrInvalidAnchor), errors.Is(err, models.ErrInvalidBody),
+		errors.Is(err, models.ErrInvalidVisibility), errors.Is(err, models.ErrReplyDepth):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func paramID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return id, true
+}
+
+// GET /api/v1/files/:document_id/annotations?page=3
+func (h *AnnotationHandler) ListPage(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	docID, ok := paramID(c, "document_id")
+	if !ok {
+		return
+	}
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+		return
+	}
+
+	items, err := h.annotationService.ListPage(uid, docID, page)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// POST /api/v1/files/:document_id/annotations
+func (h *AnnotationHandler) CreateAnnotation(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	docID, ok := paramID(c, "document_id")
+	if !ok {
+		return
+	}
+	var req models.CreateAnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	a, err := h.annotationService.CreateAnnotation(uid, docID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": a})
+}
+
+// POST /api/v1/annotations/:id/replies
+func (h *AnnotationHandler) Reply(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req models.ReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	a, err := h.annotationService.Reply(uid, id, req.Body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": a})
+}
+
+// PUT /api/v1/annotations/:id
+func (h *AnnotationHandler) UpdateAnnotation(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req models.UpdateAnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	a, err := h.annotationService.UpdateAnnotation(uid, id, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": a})
+}
+
+// DELETE /api/v1/annotations/:id
+func (h *AnnotationHandler) DeleteAnnotation(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.annotationService.DeleteAnnotation(uid, id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
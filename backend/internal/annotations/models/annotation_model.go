@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	VisibilityPublic  = "public"
+	VisibilityFriends = "friends"
+	VisibilityPrivate = "private"
+)
+
+var (
+	ErrAnnotationNotFound = errors.New("annotation not found")
+	ErrDocumentNotFound   = errors.New("document not found")
+	ErrForbidden          = errors.New("forbidden")
+	ErrInvalidAnchor      = errors.New("annotation needs a page and a bbox or text range")
+	ErrInvalidBody        = errors.New("annotation must be 1-2000 characters")
+	ErrInvalidVisibility  = errors.New("visibility must be public, friends or private")
+	ErrReplyDepth         = errors.New("replies can only be one level deep")
+)
+
+const MaxBodyLen = 2000
+
+// กรอบบนหน้า (normalized 0..1 จากมุมซ้ายบน)
+type BBox struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	W float64 `json:"w"`
+	H float64 `json:"h"`
+}
+
+// ช่วงข้อความใน text layer ของหน้า
+type TextRange struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Quote string `json:"quote,omitempty"`
+}
+
+type Annotation struct {
+	AnnotationID int          `json:"annotation_id"`
+	DocumentID   int          `json:"document_id"`
+	ParentID     *int         `json:"parent_id"`
+	Page         int          `json:"page"`
+	BBox         *BBox        `json:"bbox,omitempty"`
+	TextRange    *TextRange   `json:"text_range,omitempty"`
+	Body         string       `json:"body"`
+	Visibility   string       `json:"visibility"`
+	AuthorID     int          `json:"author_id"`
+	AuthorName   string       `json:"author_name"`
+	AvatarURL    *string      `json:"avatar_url"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	Replies      []Annotation `json:"replies,omitempty"`
+}
+
+type CreateAnnotationRequest struct {
+	Page       int        `json:"page" binding:"required"`
+	BBox       *BBox      `json:"bbox"`
+	TextRange  *TextRange `json:"text_range"`
+	Body       string     `json:"body" binding:"required"`
+	Visibility string     `json:"visibility"`
+}
+
+type ReplyRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// แก้ไขได้เฉพาะข้อความและ visibility (ตำแหน่งคงเดิม)
+type UpdateAnnotationRequest struct {
+	Body       string `json:"body" binding:"required"`
+	Visibility string `json:"visibility"`
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"chaladshare_backend/internal/annotations/models"
+	"chaladshare_backend/internal/visibility"
+)
+
+type AnnotationRepository interface {
+	GetDocumentOwner(documentID int) (int, error)
+	CanViewDocument(viewerID, documentID int) (bool, error)
+
+	CreateAnnotation(a *models.Annotation) (int, error)
+	GetAnnotation(annotationID int) (*models.Annotation, error)
+	UpdateAnnotation(annotationID int, body, visibility string) error
+	DeleteAnnotation(annotationID int) error
+
+	CanViewAnnotation(viewerID, annotationID int) (bool, error)
+	ListPage(viewerID, documentID, page int) ([]models.Annotation, error)
+}
+
+type annotationRepository struct {
+	db *sql.DB
+}
+
+func NewAnnotationRepository(db *sql.DB) AnnotationRepository {
+	return &annotationRepository{db: db}
+}
+
+func (r *annotationRepository) GetDocumentOwner(documentID int) (int, error) {
+	var ownerID int
+	err := r.db.QueryRow(`SELECT document_user_id FROM documents WHERE document_id = $1 AND document_deleted_at IS NULL`, documentID).Scan(&ownerID)
+	if err == sql.ErrNoRows {
+		return 0, models.ErrDocumentNotFound
+	}
+	return ownerID, err
+}
+
+// เห็นเอกสารได้ถ้าเป็นเจ้าของ หรือเห็นโพสต์ (ที่เผยแพร่แล้ว) ที่แนบเอกสารนี้อย่างน้อยหนึ่งโพสต์
+// เอกสารในถังขยะไม่มีใครเห็น
+func (r *annotationRepository) CanViewDocument(viewerID, documentID int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM documents d
+			WHERE d.document_id = $2 AND d.document_user_id = $1 AND d.document_deleted_at IS NULL
+		) OR EXISTS (
+			SELECT 1 FROM post_documents pd
+			JOIN documents d ON d.document_id = pd.attachment_document_id
+			JOIN posts p ON p.post_id = pd.attachment_post_id
+			WHERE pd.attachment_document_id = $2
+			  AND d.document_deleted_at IS NULL
+			  AND ` + visibility.PostPublished + `
+			  AND ` + visibility.PostVisible + `
+		);`
+	var ok bool
+	err := r.db.QueryRow(query, viewerID, documentID).Scan(&ok)
+	return ok, err
+}
+
+const qAnnotationSelect = `
+	SELECT a.annotation_id, a.annotation_document_id, a.annotation_parent_id, a.annotation_page,
+		a.annotation_bbox_x, a.annotation_bbox_y, a.annotation_bbox_w, a.annotation_bbox_h,
+		a.annotation_text_start, a.annotation_text_end, a.annotation_quote,
+		a.annotation_body, a.annotation_visibility,
+		a.annotation_user_id, u.username, up.avatar_url,
+		a.annotation_created_at, a.annotation_updated_at
+	FROM annotations a
+	JOIN users u ON u.user_id = a.annotation_user_id
+	LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
+`
+
+func scanAnnotation(row interface{ Scan(...any) error }) (*models.Annotation, error) {
+	var (
+		a                  models.Annotation
+		parentID           sql.NullInt64
+		bx, by, bw, bh     sql.NullFloat64
+		textStart, textEnd sql.NullInt64
+		quote, avatar      sql.NullString
+	)
+	if err := row.Scan(&a.AnnotationID, &a.DocumentID, &parentID, &a.Page,
+		&bx, &by, &bw, &bh,
+		&textStart, &textEnd, &quote,
+		&a.Body, &a.Visibility,
+		&a.AuthorID, &a.AuthorName, &avatar,
+		&a.CreatedAt, &a.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		v := int(parentID.Int64)
+		a.ParentID = &v
+	}
+	if bx.Valid && by.Valid && bw.Valid && bh.Valid {
+		a.BBox = &models.BBox{X: bx.Float64, Y: by.Float64, W: bw.Float64, H: bh.Float64}
+	}
+	if textStart.Valid && textEnd.Valid {
+		a.TextRange = &models.TextRange{Start: int(textStart.Int64), End: int(textEnd.Int64), Quote: quote.String}
+	}
+	if avatar.Valid {
+		a.AvatarURL = &avatar.String
+	}
+	return &a, nil
+}
+
+func (r *annotationRepository) CreateAnnotation(a *models.Annotation) (int, error) {
+	var (
+		parentArg, bx, by, bw, bh interface{}
+		textStart, textEnd, quote interface{}
+	)
+	if a.ParentID != nil {
+		parentArg = *a.ParentID
+	}
+	if a.BBox != nil {
+		bx, by, bw, bh = a.BBox.X, a.BBox.Y, a.BBox.W, a.BBox.H
+	}
+	if a.TextRange != nil {
+		textStart, textEnd = a.TextRange.Start, a.TextRange.End
+		if a.TextRange.Quote != "" {
+			quote = a.TextRange.Quote
+		}
+	}
+
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO annotations (annotation_document_id, annotation_user_id, annotation_parent_id,
+			annotation_page, annotation_bbox_x, annotation_bbox_y, annotation_bbox_w, annotation_bbox_h,
+			annotation_text_start, annotation_text_end, annotation_quote,
+			annotation_body, annotation_visibility)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING annotation_id;`,
+		a.DocumentID, a.AuthorID, parentArg,
+		a.Page, bx, by, bw, bh,
+		textStart, textEnd, quote,
+		a.Body, a.Visibility).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("create annotation: %w", err)
+	}
+	return id, nil
+}
+
+func (r *annotationRepository) GetAnnotation(annotationID int) (*models.Annotation, error) {
+	a, err := scanAnnotation(r.db.QueryRow(qAnnotationSelect+` WHERE a.annotation_id = $1;`, annotationID))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrAnnotationNotFound
+	}
+	return a, err
+}
+
+// เปลี่ยน visibility ของ annotation แม่ จะเปลี่ยนของ reply ทั้งหมดตามไปด้วย
+func (r *annotationRepository) UpdateAnnotation(annotationID int, body, visibility string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE annotations
+		SET annotation_body = $2, annotation_visibility = $3, annotation_updated_at = now()
+		WHERE annotation_id = $1;`, annotationID, body, visibility)
+	if err != nil {
+		return fmt.Errorf("update annotation: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrAnnotationNotFound
+	}
+	if _, err := tx.Exec(`
+		UPDATE annotations SET annotation_visibility = $2
+		WHERE annotation_parent_id = $1;`, annotationID, visibility); err != nil {
+		return fmt.Errorf("update reply visibility: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *annotationRepository) DeleteAnnotation(annotationID int) error {
+	res, err := r.db.Exec(`DELETE FROM annotations WHERE annotation_id = $1`, annotationID)
+	if err != nil {
+		return fmt.Errorf("delete annotation: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrAnnotationNotFound
+	}
+	return nil
+}
+
+// เงื่อนไขมองเห็น annotation ต้นเรื่อง (ใช้ร่วมกับ alias a + pa และ $1 = viewer)
+const qAnnotationVisible = `
+	(
+		COALESCE(pa.annotation_user_id, a.annotation_user_id) = $1
+		OR COALESCE(pa.annotation_visibility, a.annotation_visibility) = 'public'
+		OR ( COALESCE(pa.annotation_visibility, a.annotation_visibility) = 'friends'
+			AND EXISTS (
+				SELECT 1
+				FROM friendships f
+				WHERE
+					f.user_id  = LEAST(COALESCE(pa.annotation_user_id, a.annotation_user_id), $1)
+					AND f.friend_id = GREATEST(COALESCE(pa.annotation_user_id, a.annotation_user_id), $1)
+			)
+		)
+	)`
+
+func (r *annotationRepository) CanViewAnnotation(viewerID, annotationID int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM annotations a
+			LEFT JOIN annotations pa ON pa.annotation_id = a.annotation_parent_id
+			WHERE a.annotation_id = $2 AND` + qAnnotationVisible + `
+		);`
+	var ok bool
+	err := r.db.QueryRow(query, viewerID, annotationID).Scan(&ok)
+	return ok, err
+}
+
+// annotation ทั้งหมดบนหน้าที่ผู้ชมเห็นได้ (แม่ก่อน reply เรียงตามเวลา)
+// สิทธิ์ดูตัดสินจาก annotation ต้นเรื่อง (reply ใช้ของแม่):
+// private = ผู้เขียนเท่านั้น, friends = ผู้เขียนและเพื่อนของผู้เขียน
+func (r *annotationRepository) ListPage(viewerID, documentID, page int) ([]models.Annotation, error) {
+	query := qAnnotationSelect + `
+		LEFT JOIN annotations pa ON pa.annotation_id = a.annotation_parent_id
+		WHERE a.annotation_document_id = $2
+		  AND a.annotation_page = $3
+		  AND` + qAnnotationVisible + `
+		ORDER BY (a.annotation_parent_id IS NOT NULL), a.annotation_created_at, a.annotation_id;`
+
+	rows, err := r.db.Query(query, viewerID, documentID, page)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.Annotation, 0, 16)
+	for rows.Next() {
+		a, err := scanAnnotation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *a)
+	}
+	return out, rows.Err()
+}
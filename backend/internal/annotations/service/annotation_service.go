@@ -0,0 +1,232 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"chaladshare_backend/internal/annotations/models"
+	"chaladshare_backend/internal/annotations/repository"
+)
+
+type AnnotationService interface {
+	ListPage(viewerID, documentID, page int) ([]models.Annotation, error)
+	CreateAnnotation(viewerID, documentID int, req models.CreateAnnotationRequest) (*models.Annotation, error)
+	Reply(viewerID, annotationID int, body string) (*models.Annotation, error)
+	UpdateAnnotation(viewerID, annotationID int, req models.UpdateAnnotationRequest) (*models.Annotation, error)
+	DeleteAnnotation(viewerID, annotationID int) error
+}
+
+type annotationService struct {
+	repo repository.AnnotationRepository
+}
+
+func NewAnnotationService(repo repository.AnnotationRepository) AnnotationService {
+	return &annotationService{repo: repo}
+}
+
+func normalizeBody(body string) (string, error) {
+	b := strings.TrimSpace(body)
+	if b == "" || utf8.RuneCountInString(b) > models.MaxBodyLen {
+		return "", models.ErrInvalidBody
+	}
+	return b, nil
+}
+
+// ค่าว่าง = public
+func normalizeVisibility(v string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", models.VisibilityPublic:
+		return models.VisibilityPublic, nil
+	case models.VisibilityFriends:
+		return models.VisibilityFriends, nil
+	case models.VisibilityPrivate:
+		return models.VisibilityPrivate, nil
+	}
+	return "", models.ErrInvalidVisibility
+}
+
+// ต้องมีหน้า และ bbox (normalized 0..1) หรือช่วงข้อความอย่างน้อยหนึ่งอย่าง
+func validateAnchor(req models.CreateAnnotationRequest) error {
+	if req.Page < 1 || (req.BBox == nil && req.TextRange == nil) {
+		return models.ErrInvalidAnchor
+	}
+	if b := req.BBox; b != nil {
+		if b.X < 0 || b.Y < 0 || b.W <= 0 || b.H <= 0 || b.X+b.W > 1 || b.Y+b.H > 1 {
+			return models.ErrInvalidAnchor
+		}
+	}
+	if t := req.TextRange; t != nil {
+		if t.Start < 0 || t.End < t.Start {
+			return models.ErrInvalidAnchor
+		}
+	}
+	return nil
+}
+
+func (s *annotationService) canViewDocument(viewerID, documentID int) error {
+	if _, err := s.repo.GetDocumentOwner(documentID); err != nil {
+		return err
+	}
+	ok, err := s.repo.CanViewDocument(viewerID, documentID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return models.ErrForbidden
+	}
+	return nil
+}
+
+// annotation ที่ผู้ชมไม่มีสิทธิ์เห็น ตอบเหมือนไม่มีอยู่
+func (s *annotationService) getVisible(viewerID, annotationID int) (*models.Annotation, error) {
+	a, err := s.repo.GetAnnotation(annotationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canViewDocument(viewerID, a.DocumentID); err != nil {
+		return nil, err
+	}
+	ok, err := s.repo.CanViewAnnotation(viewerID, annotationID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, models.ErrAnnotationNotFound
+	}
+	return a, nil
+}
+
+// คืนเป็นรายการ annotation ระดับบน โดยมี reply ซ้อนอยู่ใน Replies
+func (s *annotationService) ListPage(viewerID, documentID, page int) ([]models.Annotation, error) {
+	if page < 1 {
+		return nil, models.ErrInvalidAnchor
+	}
+	if err := s.canViewDocument(viewerID, documentID); err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.ListPage(viewerID, documentID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	// repo เรียงแม่ก่อน reply เสมอ
+	out := make([]models.Annotation, 0, len(rows))
+	index := make(map[int]int, len(rows))
+	for _, a := range rows {
+		if a.ParentID == nil {
+			index[a.AnnotationID] = len(out)
+			out = append(out, a)
+			continue
+		}
+		if i, ok := index[*a.ParentID]; ok {
+			out[i].Replies = append(out[i].Replies, a)
+		}
+	}
+	return out, nil
+}
+
+func (s *annotationService) CreateAnnotation(viewerID, documentID int, req models.CreateAnnotationRequest) (*models.Annotation, error) {
+	body, err := normalizeBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	vis, err := normalizeVisibility(req.Visibility)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAnchor(req); err != nil {
+		return nil, err
+	}
+	if err := s.canViewDocument(viewerID, documentID); err != nil {
+		return nil, err
+	}
+
+	if req.TextRange != nil {
+		req.TextRange.Quote = strings.TrimSpace(req.TextRange.Quote)
+	}
+	id, err := s.repo.CreateAnnotation(&models.Annotation{
+		DocumentID: documentID,
+		AuthorID:   viewerID,
+		Page:       req.Page,
+		BBox:       req.BBox,
+		TextRange:  req.TextRange,
+		Body:       body,
+		Visibility: vis,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetAnnotation(id)
+}
+
+// reply ใช้หน้าและ visibility เดียวกับ annotation แม่
+func (s *annotationService) Reply(viewerID, annotationID int, body string) (*models.Annotation, error) {
+	b, err := normalizeBody(body)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := s.getVisible(viewerID, annotationID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.ParentID != nil {
+		return nil, models.ErrReplyDepth
+	}
+
+	id, err := s.repo.CreateAnnotation(&models.Annotation{
+		DocumentID: parent.DocumentID,
+		ParentID:   &parent.AnnotationID,
+		AuthorID:   viewerID,
+		Page:       parent.Page,
+		Body:       b,
+		Visibility: parent.Visibility,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetAnnotation(id)
+}
+
+// ผู้เขียนเท่านั้น; visibility เปลี่ยนได้เฉพาะ annotation ระดับบน
+func (s *annotationService) UpdateAnnotation(viewerID, annotationID int, req models.UpdateAnnotationRequest) (*models.Annotation, error) {
+	body, err := normalizeBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.getVisible(viewerID, annotationID)
+	if err != nil {
+		return nil, err
+	}
+	if a.AuthorID != viewerID {
+		return nil, models.ErrForbidden
+	}
+
+	vis := a.Visibility
+	if req.Visibility != "" && a.ParentID == nil {
+		if vis, err = normalizeVisibility(req.Visibility); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.UpdateAnnotation(annotationID, body, vis); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAnnotation(annotationID)
+}
+
+// ผู้เขียน หรือเจ้าของเอกสาร (moderation) ลบได้; reply ถูกลบตามแม่
+func (s *annotationService) DeleteAnnotation(viewerID, annotationID int) error {
+	a, err := s.repo.GetAnnotation(annotationID)
+	if err != nil {
+		return err
+	}
+	if a.AuthorID != viewerID {
+		ownerID, err := s.repo.GetDocumentOwner(a.DocumentID)
+		if err != nil {
+			return err
+		}
+		if ownerID != viewerID {
+			return models.ErrForbidden
+		}
+	}
+	return s.repo.DeleteAnnotation(annotationID)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"chaladshare_backend/internal/audiences/models"
+	"chaladshare_backend/internal/audiences/service"
+	"chaladshare_backend/internal/middleware"
+)
+
+type AudienceHandler struct {
+	audienceService service.AudienceService
+}
+
+func NewAudienceHandler(audienceService service.AudienceService) *AudienceHandler {
+	return &AudienceHandler{audienceService: audienceService}
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrListNotFound), errors.Is(err, models.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrListNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrInvalidName), errors.Is(err, models.ErrSelfMember),
+		errors.Is(err, models.ErrTooManyMembers):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func paramID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return id, true
+}
+
+// GET /api/v1/audience-lists
+func (h *AudienceHandler) ListLists(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	lists, err := h.audienceService.ListLists(uid)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": lists})
+}
+
+// POST /api/v1/audience-lists
+func (h *AudienceHandler) CreateList(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.CreateListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	l, err := h.audienceService.CreateList(uid, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": l})
+}
+
+// PUT /api/v1/audience-lists/:id
+func (h *AudienceHandler) RenameList(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req models.RenameListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	l, err := h.audienceService.RenameList(uid, id, req.Name)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": l})
+}
+
+// DELETE /api/v1/audience-lists/:id (โพสต์ที่ใช้ list นี้จะกลายเป็น private)
+func (h *AudienceHandler) DeleteList(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.audienceService.DeleteList(uid, id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /api/v1/audience-lists/:id/members
+func (h *AudienceHandler) ListMembers(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	members, err := h.audienceService.ListMembers(uid, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+// PUT /api/v1/audience-lists/:id/members/:user_id
+func (h *AudienceHandler) AddMember(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := paramID(c, "user_id")
+	if !ok {
+		return
+	}
+	if err := h.audienceService.AddMember(uid, id, memberID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DELETE /api/v1/audience-lists/:id/members/:user_id
+func (h *AudienceHandler) RemoveMember(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := paramID(c, "user_id")
+	if !ok {
+		return
+	}
+	if err := h.audienceService.RemoveMember(uid, id, memberID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrListNotFound   = errors.New("audience list not found")
+	ErrListNameTaken  = errors.New("you already have an audience list with this name")
+	ErrInvalidName    = errors.New("list name must be 1-60 characters")
+	ErrUserNotFound   = errors.New("user not found")
+	ErrSelfMember     = errors.New("cannot add yourself to an audience list")
+	ErrTooManyMembers = errors.New("audience list is full")
+)
+
+const (
+	MaxNameLen = 60  // ตาม audience_lists.list_name
+	MaxMembers = 500 // ต่อหนึ่ง list
+)
+
+// กลุ่มผู้ชมที่ผู้เขียนตั้งเอง (เช่น "กลุ่มติว Calculus") ใช้กับโพสต์ visibility = list
+type AudienceList struct {
+	ListID      int       `json:"list_id"`
+	OwnerID     int       `json:"-"`
+	Name        string    `json:"list_name"`
+	MemberCount int       `json:"member_count"`
+	PostCount   int       `json:"post_count"`
+	CreatedAt   time.Time `json:"list_created_at"`
+}
+
+type Member struct {
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username"`
+	AvatarURL *string   `json:"avatar_url"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+// body: {"list_name": "...", "member_ids": [3, 7]}
+type CreateListRequest struct {
+	Name      string `json:"list_name" binding:"required"`
+	MemberIDs []int  `json:"member_ids"`
+}
+
+type RenameListRequest struct {
+	Name string `json:"list_name" binding:"required"`
+}
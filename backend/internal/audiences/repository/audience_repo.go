@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"chaladshare_backend/internal/audiences/models"
+)
+
+type AudienceRepository interface {
+	ListLists(ownerID int) ([]models.AudienceList, error)
+	GetList(listID int) (*models.AudienceList, error)
+	CreateList(ownerID int, name string, memberIDs []int) (int, error)
+	RenameList(listID int, name string) error
+	DeleteList(listID int) error
+
+	ListMembers(listID int) ([]models.Member, error)
+	AddMember(listID, userID int) error
+	RemoveMember(listID, userID int) error
+}
+
+type audienceRepository struct {
+	db *sql.DB
+}
+
+func NewAudienceRepository(db *sql.DB) AudienceRepository {
+	return &audienceRepository{db: db}
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+const qListSelect = `
+	SELECT l.list_id, l.list_owner_user_id, l.list_name,
+		(SELECT COUNT(*) FROM audience_list_members m WHERE m.member_list_id = l.list_id) AS member_count,
+		(SELECT COUNT(*) FROM posts p WHERE p.post_audience_list_id = l.list_id AND p.post_deleted_at IS NULL) AS post_count,
+		l.list_created_at
+	FROM audience_lists l
+`
+
+func scanList(row interface{ Scan(...any) error }) (*models.AudienceList, error) {
+	var l models.AudienceList
+	if err := row.Scan(&l.ListID, &l.OwnerID, &l.Name, &l.MemberCount, &l.PostCount, &l.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *audienceRepository) ListLists(ownerID int) ([]models.AudienceList, error) {
+	rows, err := r.db.Query(qListSelect+` WHERE l.list_owner_user_id = $1 ORDER BY l.list_name;`, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.AudienceList, 0)
+	for rows.Next() {
+		l, err := scanList(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *l)
+	}
+	return out, rows.Err()
+}
+
+func (r *audienceRepository) GetList(listID int) (*models.AudienceList, error) {
+	l, err := scanList(r.db.QueryRow(qListSelect+` WHERE l.list_id = $1;`, listID))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrListNotFound
+	}
+	return l, err
+}
+
+// สร้าง list พร้อมสมาชิกเริ่มต้น (id ที่ไม่มีอยู่จริงหรือเป็นเจ้าของเองจะถูกข้าม)
+func (r *audienceRepository) CreateList(ownerID int, name string, memberIDs []int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var listID int
+	if err := tx.QueryRow(`
+		INSERT INTO audience_lists (list_owner_user_id, list_name)
+		VALUES ($1, $2) RETURNING list_id;`, ownerID, name).Scan(&listID); err != nil {
+		if isUniqueViolation(err) {
+			return 0, models.ErrListNameTaken
+		}
+		return 0, fmt.Errorf("create audience list: %w", err)
+	}
+
+	if len(memberIDs) > 0 {
+		ids := make([]int64, len(memberIDs))
+		for i, id := range memberIDs {
+			ids[i] = int64(id)
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO audience_list_members (member_list_id, member_user_id)
+			SELECT $1, u.user_id FROM users u
+			WHERE u.user_id = ANY($2::int[]) AND u.user_id <> $3
+			ON CONFLICT DO NOTHING;`, listID, pq.Array(ids), ownerID); err != nil {
+			return 0, fmt.Errorf("add audience members: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return listID, nil
+}
+
+func (r *audienceRepository) RenameList(listID int, name string) error {
+	res, err := r.db.Exec(`UPDATE audience_lists SET list_name = $2 WHERE list_id = $1`, listID, name)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrListNameTaken
+		}
+		return fmt.Errorf("rename audience list: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrListNotFound
+	}
+	return nil
+}
+
+// โพสต์ที่ใช้ list นี้กลายเป็น private (ไม่เปิดให้คนอื่นเห็นเพิ่มโดยไม่ตั้งใจ)
+func (r *audienceRepository) DeleteList(listID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE posts SET post_visibility = 'private', post_audience_list_id = NULL
+		WHERE post_audience_list_id = $1;`, listID); err != nil {
+		return fmt.Errorf("make list posts private: %w", err)
+	}
+	res, err := tx.Exec(`DELETE FROM audience_lists WHERE list_id = $1`, listID)
+	if err != nil {
+		return fmt.Errorf("delete audience list: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrListNotFound
+	}
+	return tx.Commit()
+}
+
+func (r *audienceRepository) ListMembers(listID int) ([]models.Member, error) {
+	rows, err := r.db.Query(`
+		SELECT u.user_id, u.username, up.avatar_url, m.member_added_at
+		FROM audience_list_members m
+		JOIN users u ON u.user_id = m.member_user_id
+		LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
+		WHERE m.member_list_id = $1
+		ORDER BY u.username;`, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.Member, 0)
+	for rows.Next() {
+		var (
+			m      models.Member
+			avatar sql.NullString
+		)
+		if err := rows.Scan(&m.UserID, &m.Username, &avatar, &m.AddedAt); err != nil {
+			return nil, err
+		}
+		if avatar.Valid {
+			m.AvatarURL = &avatar.String
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// เพิ่มซ้ำได้ (idempotent)
+func (r *audienceRepository) AddMember(listID, userID int) error {
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM users WHERE user_id = $1)`, userID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return models.ErrUserNotFound
+	}
+	if _, err := r.db.Exec(`
+		INSERT INTO audience_list_members (member_list_id, member_user_id)
+		VALUES ($1, $2) ON CONFLICT DO NOTHING;`, listID, userID); err != nil {
+		return fmt.Errorf("add audience member: %w", err)
+	}
+	return nil
+}
+
+func (r *audienceRepository) RemoveMember(listID, userID int) error {
+	if _, err := r.db.Exec(`
+		DELETE FROM audience_list_members
+		WHERE member_list_id = $1 AND member_user_id = $2;`, listID, userID); err != nil {
+		return fmt.Errorf("remove audience member: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"chaladshare_backend/internal/audiences/models"
+	"chaladshare_backend/internal/audiences/repository"
+)
+
+type AudienceService interface {
+	ListLists(ownerID int) ([]models.AudienceList, error)
+	CreateList(ownerID int, req models.CreateListRequest) (*models.AudienceList, error)
+	RenameList(ownerID, listID int, name string) (*models.AudienceList, error)
+	DeleteList(ownerID, listID int) error
+
+	ListMembers(ownerID, listID int) ([]models.Member, error)
+	AddMember(ownerID, listID, userID int) error
+	RemoveMember(ownerID, listID, userID int) error
+}
+
+type audienceService struct {
+	repo repository.AudienceRepository
+}
+
+func NewAudienceService(repo repository.AudienceRepository) AudienceService {
+	return &audienceService{repo: repo}
+}
+
+func normalizeName(name string) (string, error) {
+	n := strings.TrimSpace(name)
+	if n == "" || utf8.RuneCountInString(n) > models.MaxNameLen {
+		return "", models.ErrInvalidName
+	}
+	return n, nil
+}
+
+// list ของคนอื่นตอบเหมือนไม่มีอยู่
+func (s *audienceService) getOwned(ownerID, listID int) (*models.AudienceList, error) {
+	l, err := s.repo.GetList(listID)
+	if err != nil {
+		return nil, err
+	}
+	if l.OwnerID != ownerID {
+		return nil, models.ErrListNotFound
+	}
+	return l, nil
+}
+
+func (s *audienceService) ListLists(ownerID int) ([]models.AudienceList, error) {
+	return s.repo.ListLists(ownerID)
+}
+
+func (s *audienceService) CreateList(ownerID int, req models.CreateListRequest) (*models.AudienceList, error) {
+	name, err := normalizeName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.MemberIDs) > models.MaxMembers {
+		return nil, models.ErrTooManyMembers
+	}
+	id, err := s.repo.CreateList(ownerID, name, req.MemberIDs)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetList(id)
+}
+
+func (s *audienceService) RenameList(ownerID, listID int, name string) (*models.AudienceList, error) {
+	n, err := normalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getOwned(ownerID, listID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RenameList(listID, n); err != nil {
+		return nil, err
+	}
+	return s.repo.GetList(listID)
+}
+
+func (s *audienceService) DeleteList(ownerID, listID int) error {
+	if _, err := s.getOwned(ownerID, listID); err != nil {
+		return err
+	}
+	return s.repo.DeleteList(listID)
+}
+
+func (s *audienceService) ListMembers(ownerID, listID int) ([]models.Member, error) {
+	if _, err := s.getOwned(ownerID, listID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(listID)
+}
+
+func (s *audienceService) AddMember(ownerID, listID, userID int) error {
+	if userID == ownerID {
+		return models.ErrSelfMember
+	}
+	l, err := s.getOwned(ownerID, listID)
+	if err != nil {
+		return err
+	}
+	if l.MemberCount >= models.MaxMembers {
+		return models.ErrTooManyMembers
+	}
+	return s.repo.AddMember(listID, userID)
+}
+
+func (s *audienceService) RemoveMember(ownerID, listID, userID int) error {
+	if _, err := s.getOwned(ownerID, listID); err != nil {
+		return err
+	}
+	return s.repo.RemoveMember(listID, userID)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -150,6 +151,10 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	user, err := h.authService.Login(req.Email, req.Password)
+	if errors.Is(err, service.ErrAccountSuspended) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 		return
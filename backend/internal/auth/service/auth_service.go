@@ -19,6 +19,9 @@ import (
 	"chaladshare_backend/internal/mail"
 )
 
+// บัญชีถูกระงับโดยผู้ดูแล (จากคิว moderation)
+var ErrAccountSuspended = errors.New("account suspended")
+
 type AuthService interface {
 	GetAllUsers() ([]models.User, error)
 	GetUserByID(id int) (*models.User, error)
@@ -168,6 +171,10 @@ func (s *authService) Login(email, password string) (*models.User, error) {
 		return nil, errors.New("invalid password")
 	}
 
+	if user.Status == "suspended" {
+		return nil, ErrAccountSuspended
+	}
+
 	return user, nil
 }
 func (s *authService) ForgotPassword(email string) error {
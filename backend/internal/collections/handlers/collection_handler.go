@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"chaladshare_backend/internal/collections/models"
+	"chaladshare_backend/internal/collections/service"
+	"chaladshare_backend/internal/middleware"
+	"chaladshare_backend/internal/pagination"
+)
+
+type CollectionHandler struct {
+	collectionService service.CollectionService
+}
+
+func NewCollectionHandler(collectionService service.CollectionService) *CollectionHandler {
+	return &CollectionHandler{collectionService: collectionService}
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrCollectionNotFound), errors.Is(err, models.ErrItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrCollectionNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrInvalidName), errors.Is(err, models.ErrInvalidVisibility),
+		errors.Is(err, models.ErrNotSaved), errors.Is(err, models.ErrTooManyCollections),
+		errors.Is(err, pagination.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func paramID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return id, true
+}
+
+// GET /api/v1/collections (ของตัวเอง)
+func (h *CollectionHandler) ListMine(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	cols, err := h.collectionService.ListCollections(uid, uid)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": cols})
+}
+
+// GET /api/v1/collections/user/:user_id (เฉพาะที่แชร์ให้ผู้ชม)
+func (h *CollectionHandler) ListByUser(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	ownerID, ok := paramID(c, "user_id")
+	if !ok {
+		return
+	}
+	cols, err := h.collectionService.ListCollections(ownerID, uid)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": cols})
+}
+
+// POST /api/v1/collections
+func (h *CollectionHandler) CreateCollection(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.CreateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	col, err := h.collectionService.CreateCollection(uid, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": col})
+}
+
+// GET /api/v1/collections/:id
+func (h *CollectionHandler) GetCollection(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	col, err := h.collectionService.GetCollection(uid, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": col})
+}
+
+// PUT /api/v1/collections/:id (เปลี่ยนชื่อ / การแชร์)
+func (h *CollectionHandler) UpdateCollection(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req models.UpdateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	col, err := h.collectionService.UpdateCollection(uid, id, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": col})
+}
+
+// DELETE /api/v1/collections/:id (โพสต์ข้างในยังถูกบันทึกอยู่)
+func (h *CollectionHandler) DeleteCollection(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.collectionService.DeleteCollection(uid, id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /api/v1/collections/:id/posts?cursor=&limit=
+func (h *CollectionHandler) ListPosts(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+	page, err := h.collectionService.ListPosts(uid, id, c.Query("cursor"), limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": page.Items, "next_cursor": page.NextCursor})
+}
+
+// PUT /api/v1/collections/:id/posts/:post_id (ย้ายเข้า collection / เปลี่ยนลำดับ)
+func (h *CollectionHandler) MoveItem(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	postID, ok := paramID(c, "post_id")
+	if !ok {
+		return
+	}
+	var req models.MoveItemRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+	}
+	if err := h.collectionService.MoveItem(uid, id, postID, req.Position); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DELETE /api/v1/collections/:id/posts/:post_id (ยังบันทึกอยู่ แค่เอาออกจาก collection)
+func (h *CollectionHandler) RemoveItem(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	postID, ok := paramID(c, "post_id")
+	if !ok {
+		return
+	}
+	if err := h.collectionService.RemoveItem(uid, id, postID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
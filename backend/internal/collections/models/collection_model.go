@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	postmodels "chaladshare_backend/internal/posts/models"
+)
+
+var (
+	ErrCollectionNotFound  = errors.New("collection not found")
+	ErrCollectionNameTaken = errors.New("you already have a collection with this name")
+	ErrInvalidName         = errors.New("collection name must be 1-60 characters")
+	ErrInvalidVisibility   = errors.New("collection_visibility must be private, friends or public")
+	ErrNotSaved            = errors.New("save the post before adding it to a collection")
+	ErrItemNotFound        = errors.New("post is not in this collection")
+	ErrTooManyCollections  = errors.New("collection limit reached")
+)
+
+const (
+	MaxNameLen     = 60  // ตาม save_collections.collection_name
+	MaxCollections = 100 // ต่อผู้ใช้
+)
+
+// ใครเห็น collection (โพสต์ข้างในยังต้องผ่าน visibility ของแต่ละโพสต์อีกชั้น)
+const (
+	VisibilityPrivate = "private"
+	VisibilityFriends = "friends"
+	VisibilityPublic  = "public"
+)
+
+type Collection struct {
+	CollectionID int       `json:"collection_id"`
+	OwnerID      int       `json:"owner_id"`
+	Name         string    `json:"collection_name"`
+	Visibility   string    `json:"collection_visibility"`
+	PostCount    int       `json:"post_count"` // นับเฉพาะโพสต์ที่ผู้ชมเห็นได้
+	CreatedAt    time.Time `json:"collection_created_at"`
+	UpdatedAt    time.Time `json:"collection_updated_at"`
+}
+
+// body: {"collection_name": "Midterm", "collection_visibility": "friends"}
+type CreateCollectionRequest struct {
+	Name       string `json:"collection_name" binding:"required"`
+	Visibility string `json:"collection_visibility"`
+}
+
+// ไม่ส่ง field ไหน = คงค่าเดิม
+type UpdateCollectionRequest struct {
+	Name       *string `json:"collection_name"`
+	Visibility *string `json:"collection_visibility"`
+}
+
+// body: {"position": 0} (ไม่ส่ง = ต่อท้าย)
+type MoveItemRequest struct {
+	Position *int `json:"position"`
+}
+
+// cursor ของรายการโพสต์ใน collection (เรียงตาม save_position, post_id)
+type ItemCursor struct {
+	Position int `json:"p"`
+	PostID   int `json:"id"`
+}
+
+type ItemRef struct {
+	PostID   int
+	Position int
+}
+
+type CollectionPostPage struct {
+	Items      []postmodels.PostResponse `json:"items"`
+	NextCursor string                    `json:"next_cursor"`
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"chaladshare_backend/internal/collections/models"
+	"chaladshare_backend/internal/visibility"
+)
+
+type CollectionRepository interface {
+	ListCollections(ownerID, viewerID int) ([]models.Collection, error)
+	GetCollection(collectionID, viewerID int) (*models.Collection, error)
+	CountCollections(ownerID int) (int, error)
+	CreateCollection(ownerID int, name, vis string) (int, error)
+	UpdateCollection(collectionID int, name, vis string) error
+	DeleteCollection(collectionID int) error
+
+	ListItems(collectionID, viewerID int, after *models.ItemCursor, limit int) ([]models.ItemRef, error)
+	MoveItem(userID, collectionID, postID int, position *int) error
+	RemoveItem(userID, collectionID, postID int) error
+}
+
+type collectionRepository struct {
+	db *sql.DB
+}
+
+func NewCollectionRepository(db *sql.DB) CollectionRepository {
+	return &collectionRepository{db: db}
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// ผู้ชม ($1) เห็น collection c ได้ไหม: เจ้าของ, public, หรือ friends และเป็นเพื่อนกับเจ้าของ
+const qCollectionVisible = `(
+	c.collection_owner_user_id = $1
+	OR c.collection_visibility = 'public'
+	OR ( c.collection_visibility = 'friends'
+		AND EXISTS (
+			SELECT 1
+			FROM friendships f
+			WHERE
+				f.user_id  = LEAST(c.collection_owner_user_id, $1)
+				AND f.friend_id = GREATEST(c.collection_owner_user_id, $1)
+		)
+	)
+)`
+
+// post_count นับเฉพาะโพสต์ที่ผู้ชม ($1) เห็นได้
+const qCollectionSelect = `
+	SELECT c.collection_id, c.collection_owner_user_id, c.collection_name, c.collection_visibility,
+		(
+			SELECT COUNT(*) FROM saved_posts sp
+			JOIN posts p ON p.post_id = sp.save_post_id
+			WHERE sp.save_collection_id = c.collection_id
+			  AND ` + visibility.PostPublished + `
+			  AND ` + visibility.PostVisible + `
+		) AS post_count,
+		c.collection_created_at, c.collection_updated_at
+	FROM save_collections c
+`
+
+func scanCollection(row interface{ Scan(...any) error }) (*models.Collection, error) {
+	var c models.Collection
+	if err := row.Scan(&c.CollectionID, &c.OwnerID, &c.Name, &c.Visibility,
+		&c.PostCount, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *collectionRepository) ListCollections(ownerID, viewerID int) ([]models.Collection, error) {
+	rows, err := r.db.Query(qCollectionSelect+`
+		WHERE c.collection_owner_user_id = $2 AND `+qCollectionVisible+`
+		ORDER BY c.collection_name;`, viewerID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.Collection, 0)
+	for rows.Next() {
+		c, err := scanCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *c)
+	}
+	return out, rows.Err()
+}
+
+// collection ที่ผู้ชมไม่มีสิทธิ์เห็นตอบเหมือนไม่มีอยู่
+func (r *collectionRepository) GetCollection(collectionID, viewerID int) (*models.Collection, error) {
+	c, err := scanCollection(r.db.QueryRow(qCollectionSelect+`
+		WHERE c.collection_id = $2 AND `+qCollectionVisible+`;`, viewerID, collectionID))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrCollectionNotFound
+	}
+	return c, err
+}
+
+func (r *collectionRepository) CountCollections(ownerID int) (int, error) {
+	var n int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM save_collections WHERE collection_owner_user_id = $1`, ownerID).Scan(&n)
+	return n, err
+}
+
+func (r *collectionRepository) CreateCollection(ownerID int, name, vis string) (int, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO save_collections (collection_owner_user_id, collection_name, collection_visibility)
+		VALUES ($1, $2, $3) RETURNING collection_id;`, ownerID, name, vis).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, models.ErrCollectionNameTaken
+		}
+		return 0, fmt.Errorf("create collection: %w", err)
+	}
+	return id, nil
+}
+
+func (r *collectionRepository) UpdateCollection(collectionID int, name, vis string) error {
+	res, err := r.db.Exec(`
+		UPDATE save_collections
+		SET collection_name = $2, collection_visibility = $3, collection_updated_at = now()
+		WHERE collection_id = $1;`, collectionID, name, vis)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrCollectionNameTaken
+		}
+		return fmt.Errorf("update collection: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrCollectionNotFound
+	}
+	return nil
+}
+
+// โพสต์ข้างในยังถูกบันทึกอยู่ แค่กลับไปเป็น "ยังไม่จัดเข้า collection" (FK on delete set null)
+func (r *collectionRepository) DeleteCollection(collectionID int) error {
+	res, err := r.db.Exec(`DELETE FROM save_collections WHERE collection_id = $1`, collectionID)
+	if err != nil {
+		return fmt.Errorf("delete collection: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrCollectionNotFound
+	}
+	return nil
+}
+
+// โพสต์ใน collection ที่ผู้ชม ($1) เห็นได้ เรียงตามลำดับที่เจ้าของจัด
+func (r *collectionRepository) ListItems(collectionID, viewerID int, after *models.ItemCursor, limit int) ([]models.ItemRef, error) {
+	var afterPos, afterID interface{}
+	if after != nil {
+		afterPos, afterID = after.Position, after.PostID
+	}
+	query := `
+		SELECT sp.save_post_id, sp.save_position
+		FROM saved_posts sp
+		JOIN posts p ON p.post_id = sp.save_post_id
+		WHERE sp.save_collection_id = $2
+		  AND ` + visibility.PostPublished + `
+		  AND ` + visibility.PostVisible + `
+		  AND ($3::int IS NULL OR (sp.save_position, sp.save_post_id) > ($3::int, $4::int))
+		ORDER BY sp.save_position, sp.save_post_id
+		LIMIT $5;`
+
+	rows, err := r.db.Query(query, viewerID, collectionID, afterPos, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.ItemRef, 0, limit)
+	for rows.Next() {
+		var it models.ItemRef
+		if err := rows.Scan(&it.PostID, &it.Position); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// ย้ายโพสต์ที่บันทึกไว้เข้า collection (จาก collection อื่นหรือยังไม่จัด) แล้วเรียงลำดับใหม่ทั้ง collection
+// position nil หรือเกินจำนวน = ต่อท้าย
+func (r *collectionRepository) MoveItem(userID, collectionID, postID int, position *int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// ล็อก collection กันการจัดลำดับพร้อมกัน
+	if _, err := tx.Exec(`SELECT 1 FROM save_collections WHERE collection_id = $1 FOR UPDATE`, collectionID); err != nil {
+		return fmt.Errorf("lock collection: %w", err)
+	}
+
+	var saved bool
+	if err := tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM saved_posts WHERE save_user_id = $1 AND save_post_id = $2
+		)`, userID, postID).Scan(&saved); err != nil {
+		return err
+	}
+	if !saved {
+		return models.ErrNotSaved
+	}
+
+	rows, err := tx.Query(`
+		SELECT save_post_id FROM saved_posts
+		WHERE save_user_id = $1 AND save_collection_id = $2 AND save_post_id <> $3
+		ORDER BY save_position, save_post_id;`, userID, collectionID, postID)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	pos := len(ids)
+	if position != nil && *position >= 0 && *position < pos {
+		pos = *position
+	}
+	ordered := make([]int64, 0, len(ids)+1)
+	ordered = append(ordered, ids[:pos]...)
+	ordered = append(ordered, int64(postID))
+	ordered = append(ordered, ids[pos:]...)
+
+	if _, err := tx.Exec(`
+		UPDATE saved_posts sp
+		SET save_collection_id = $2, save_position = u.ord - 1
+		FROM unnest($3::int[]) WITH ORDINALITY AS u(post_id, ord)
+		WHERE sp.save_user_id = $1 AND sp.save_post_id = u.post_id;`,
+		userID, collectionID, pq.Array(ordered)); err != nil {
+		return fmt.Errorf("reorder collection: %w", err)
+	}
+	if _, err := tx.Exec(`
+		UPDATE save_collections SET collection_updated_at = now() WHERE collection_id = $1`, collectionID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// เอาออกจาก collection (ยังบันทึกอยู่)
+func (r *collectionRepository) RemoveItem(userID, collectionID, postID int) error {
+	res, err := r.db.Exec(`
+		UPDATE saved_posts SET save_collection_id = NULL, save_position = 0
+		WHERE save_user_id = $1 AND save_post_id = $2 AND save_collection_id = $3;`,
+		userID, postID, collectionID)
+	if err != nil {
+		return fmt.Errorf("remove collection item: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrItemNotFound
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"chaladshare_backend/internal/collections/models"
+	"chaladshare_backend/internal/collections/repository"
+	"chaladshare_backend/internal/pagination"
+	postservice "chaladshare_backend/internal/posts/service"
+)
+
+type CollectionService interface {
+	ListCollections(ownerID, viewerID int) ([]models.Collection, error)
+	GetCollection(viewerID, collectionID int) (*models.Collection, error)
+	CreateCollection(ownerID int, req models.CreateCollectionRequest) (*models.Collection, error)
+	UpdateCollection(ownerID, collectionID int, req models.UpdateCollectionRequest) (*models.Collection, error)
+	DeleteCollection(ownerID, collectionID int) error
+
+	ListPosts(viewerID, collectionID int, cursor string, limit int) (*models.CollectionPostPage, error)
+	MoveItem(ownerID, collectionID, postID int, position *int) error
+	RemoveItem(ownerID, collectionID, postID int) error
+}
+
+type collectionService struct {
+	repo        repository.CollectionRepository
+	postService postservice.PostService
+	cursors     *pagination.Codec
+}
+
+func NewCollectionService(repo repository.CollectionRepository, postService postservice.PostService, cursors *pagination.Codec) CollectionService {
+	return &collectionService{repo: repo, postService: postService, cursors: cursors}
+}
+
+func normalizeName(name string) (string, error) {
+	n := strings.TrimSpace(name)
+	if n == "" || utf8.RuneCountInString(n) > models.MaxNameLen {
+		return "", models.ErrInvalidName
+	}
+	return n, nil
+}
+
+// ค่าว่าง = private
+func normalizeVisibility(vis string) (string, error) {
+	switch v := strings.ToLower(strings.TrimSpace(vis)); v {
+	case "":
+		return models.VisibilityPrivate, nil
+	case models.VisibilityPrivate, models.VisibilityFriends, models.VisibilityPublic:
+		return v, nil
+	default:
+		return "", models.ErrInvalidVisibility
+	}
+}
+
+// collection ของคนอื่นตอบเหมือนไม่มีอยู่
+func (s *collectionService) getOwned(ownerID, collectionID int) (*models.Collection, error) {
+	c, err := s.repo.GetCollection(collectionID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if c.OwnerID != ownerID {
+		return nil, models.ErrCollectionNotFound
+	}
+	return c, nil
+}
+
+// ของตัวเองเห็นทั้งหมด ของคนอื่นเห็นเฉพาะที่แชร์ให้
+func (s *collectionService) ListCollections(ownerID, viewerID int) ([]models.Collection, error) {
+	return s.repo.ListCollections(ownerID, viewerID)
+}
+
+func (s *collectionService) GetCollection(viewerID, collectionID int) (*models.Collection, error) {
+	return s.repo.GetCollection(collectionID, viewerID)
+}
+
+func (s *collectionService) CreateCollection(ownerID int, req models.CreateCollectionRequest) (*models.Collection, error) {
+	name, err := normalizeName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	vis, err := normalizeVisibility(req.Visibility)
+	if err != nil {
+		return nil, err
+	}
+	n, err := s.repo.CountCollections(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if n >= models.MaxCollections {
+		return nil, models.ErrTooManyCollections
+	}
+	id, err := s.repo.CreateCollection(ownerID, name, vis)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetCollection(id, ownerID)
+}
+
+func (s *collectionService) UpdateCollection(ownerID, collectionID int, req models.UpdateCollectionRequest) (*models.Collection, error) {
+	c, err := s.getOwned(ownerID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	name, vis := c.Name, c.Visibility
+	if req.Name != nil {
+		if name, err = normalizeName(*req.Name); err != nil {
+			return nil, err
+		}
+	}
+	if req.Visibility != nil {
+		if vis, err = normalizeVisibility(*req.Visibility); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.UpdateCollection(collectionID, name, vis); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCollection(collectionID, ownerID)
+}
+
+func (s *collectionService) DeleteCollection(ownerID, collectionID int) error {
+	if _, err := s.getOwned(ownerID, collectionID); err != nil {
+		return err
+	}
+	return s.repo.DeleteCollection(collectionID)
+}
+
+// โพสต์ใน collection ตามลำดับที่เจ้าของจัด (กรองตาม visibility ของแต่ละโพสต์กับผู้ชม)
+func (s *collectionService) ListPosts(viewerID, collectionID int, cursor string, limit int) (*models.CollectionPostPage, error) {
+	var after models.ItemCursor
+	hasCursor, err := s.cursors.Decode(cursor, &after)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetCollection(collectionID, viewerID); err != nil {
+		return nil, err
+	}
+
+	limit = pagination.ClampLimit(limit)
+	var afterPtr *models.ItemCursor
+	if hasCursor {
+		afterPtr = &after
+	}
+	refs, err := s.repo.ListItems(collectionID, viewerID, afterPtr, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.CollectionPostPage{}
+	if len(refs) > limit {
+		refs = refs[:limit]
+		last := refs[limit-1]
+		if page.NextCursor, err = s.cursors.Encode(models.ItemCursor{Position: last.Position, PostID: last.PostID}); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int, len(refs))
+	for i, ref := range refs {
+		ids[i] = ref.PostID
+	}
+	if page.Items, err = s.postService.GetPostsByIDs(viewerID, ids); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (s *collectionService) MoveItem(ownerID, collectionID, postID int, position *int) error {
+	if _, err := s.getOwned(ownerID, collectionID); err != nil {
+		return err
+	}
+	return s.repo.MoveItem(ownerID, collectionID, postID, position)
+}
+
+func (s *collectionService) RemoveItem(ownerID, collectionID, postID int) error {
+	if _, err := s.getOwned(ownerID, collectionID); err != nil {
+		return err
+	}
+	return s.repo.RemoveItem(ownerID, collectionID, postID)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"chaladshare_backend/internal/comments/models"
+	"chaladshare_backend/internal/comments/service"
+	"chaladshare_backend/internal/pagination"
+)
+
+type CommentHandler struct {
+	commentService service.CommentService
+}
+
+func NewCommentHandler(commentService service.CommentService) *CommentHandler {
+	return &CommentHandler{commentService: commentService}
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrCommentNotFound), errors.Is(err, models.ErrPostNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrInvalidBody), errors.Is(err, models.ErrReplyDepth),
+		errors.Is(err, pagination.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func paramID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return id, true
+}
+
+func pageQuery(c *gin.Context) (string, int) {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+	return c.Query("cursor"), pagination.ClampLimit(limit)
+}
+
+// GET /api/v1/posts/:id/comments?cursor=&limit=
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	cursor, limit := pageQuery(c)
+	page, err := h.commentService.ListComments(uid, postID, cursor, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": page.Items, "next_cursor": page.NextCursor})
+}
+
+// POST /api/v1/posts/:id/comments
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req models.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	comment, err := h.commentService.CreateComment(uid, postID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": comment})
+}
+
+// GET /api/v1/comments/:id/replies?cursor=&limit=
+func (h *CommentHandler) ListReplies(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	commentID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	cursor, limit := pageQuery(c)
+	page, err := h.commentService.ListReplies(uid, commentID, cursor, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": page.Items, "next_cursor": page.NextCursor})
+}
+
+// PUT /api/v1/comments/:id (ผู้เขียนเท่านั้น)
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	commentID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req models.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	comment, err := h.commentService.UpdateComment(uid, commentID, req.Body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": comment})
+}
+
+// DELETE /api/v1/comments/:id (ผู้เขียน หรือเจ้าของโพสต์)
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	commentID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.commentService.DeleteComment(uid, commentID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrCommentNotFound = errors.New("comment not found")
+	ErrPostNotFound    = errors.New("post not found")
+	ErrForbidden       = errors.New("forbidden")
+	ErrInvalidBody     = errors.New("comment must be 1-2000 characters")
+	ErrReplyDepth      = errors.New("replies can only be one level deep")
+)
+
+// ความยาวสูงสุดของคอมเมนต์ (ตาม check ใน comments.comment_body)
+const MaxBodyLen = 2000
+
+type Comment struct {
+	CommentID  int       `json:"comment_id"`
+	PostID     int       `json:"post_id"`
+	ParentID   *int      `json:"parent_id"`
+	AuthorID   int       `json:"author_id"`
+	AuthorName string    `json:"author_name"`
+	AvatarURL  *string   `json:"avatar_url"`
+	Body       string    `json:"comment_body"`
+	Edited     bool      `json:"edited"`
+	ReplyCount int       `json:"reply_count"` // เฉพาะคอมเมนต์ระดับบน
+	CreatedAt  time.Time `json:"comment_created_at"`
+	UpdatedAt  time.Time `json:"comment_updated_at"`
+
+	// ถูกซ่อนจากการรายงาน (เห็นได้เฉพาะผู้เขียน)
+	HiddenAt *time.Time `json:"hidden_at,omitempty"`
+}
+
+// body: {"comment_body": "...", "parent_id": 12} (parent_id = ตอบคอมเมนต์)
+type CreateCommentRequest struct {
+	Body     string `json:"comment_body" binding:"required"`
+	ParentID *int   `json:"parent_id"`
+}
+
+type UpdateCommentRequest struct {
+	Body string `json:"comment_body" binding:"required"`
+}
+
+// หน้ารายการคอมเมนต์แบบ cursor (เก่า→ใหม่)
+type CommentPage struct {
+	Items      []Comment `json:"items"`
+	NextCursor string    `json:"next_cursor"`
+}
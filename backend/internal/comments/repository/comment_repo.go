@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"chaladshare_backend/internal/comments/models"
+	"chaladshare_backend/internal/pagination"
+)
+
+type CommentRepository interface {
+	CreateComment(postID, userID int, parentID *int, body string) (int, error)
+	GetComment(viewerID, commentID int) (*models.Comment, error)
+	FindComment(commentID int) (*models.Comment, error)
+	UpdateComment(commentID int, body string) error
+	DeleteComment(commentID int) error
+
+	ListComments(postID int, after *pagination.Keyset, limit int) ([]models.Comment, error)
+	ListReplies(parentID int, after *pagination.Keyset, limit int) ([]models.Comment, error)
+}
+
+type commentRepository struct {
+	db *sql.DB
+}
+
+func NewCommentRepository(db *sql.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+const qCommentSelect = `
+	SELECT c.comment_id, c.comment_post_id, c.comment_parent_id,
+		c.comment_user_id, u.username, up.avatar_url,
+		c.comment_body, c.comment_edited,
+		(SELECT COUNT(*) FROM comments r
+			WHERE r.comment_parent_id = c.comment_id AND r.comment_hidden_at IS NULL) AS reply_count,
+		c.comment_created_at, c.comment_updated_at, c.comment_hidden_at
+	FROM comments c
+	JOIN users u ON u.user_id = c.comment_user_id
+	LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
+`
+
+// keyset เก่า→ใหม่ บน (comment_created_at, comment_id)
+const qCommentAfter = `
+	AND ($2::timestamptz IS NULL OR (c.comment_created_at, c.comment_id) > ($2::timestamptz, $3::int))
+	ORDER BY c.comment_created_at, c.comment_id
+	LIMIT $4;`
+
+func scanComment(row interface{ Scan(...any) error }) (*models.Comment, error) {
+	var (
+		c        models.Comment
+		parentID sql.NullInt64
+		avatar   sql.NullString
+	)
+	if err := row.Scan(&c.CommentID, &c.PostID, &parentID,
+		&c.AuthorID, &c.AuthorName, &avatar,
+		&c.Body, &c.Edited, &c.ReplyCount,
+		&c.CreatedAt, &c.UpdatedAt, &c.HiddenAt); err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		v := int(parentID.Int64)
+		c.ParentID = &v
+	}
+	if avatar.Valid {
+		c.AvatarURL = &avatar.String
+	}
+	return &c, nil
+}
+
+// นับคอมเมนต์ที่มองเห็นได้ใหม่ทั้งโพสต์ลง post_stats (แบบเดียวกับ UpdateLikeCount)
+func refreshCommentCount(tx *sql.Tx, postID int) error {
+	_, err := tx.Exec(`
+		INSERT INTO post_stats (post_stats_post_id, post_comment_count, post_last_activity_at)
+		VALUES (
+			$1,
+			(SELECT COUNT(*) FROM comments WHERE comment_post_id = $1 AND comment_hidden_at IS NULL),
+			NOW()
+		)
+		ON CONFLICT (post_stats_post_id)
+		DO UPDATE SET
+			post_comment_count    = EXCLUDED.post_comment_count,
+			post_last_activity_at = EXCLUDED.post_last_activity_at;`, postID)
+	return err
+}
+
+func (r *commentRepository) CreateComment(postID, userID int, parentID *int, body string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var parentArg interface{}
+	if parentID != nil {
+		parentArg = *parentID
+	}
+
+	var id int
+	if err := tx.QueryRow(`
+		INSERT INTO comments (comment_post_id, comment_user_id, comment_parent_id, comment_body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING comment_id;`, postID, userID, parentArg, body).Scan(&id); err != nil {
+		return 0, fmt.Errorf("create comment: %w", err)
+	}
+	if err := refreshCommentCount(tx, postID); err != nil {
+		return 0, fmt.Errorf("update comment count: %w", err)
+	}
+	return id, tx.Commit()
+}
+
+// คอมเมนต์ที่ถูกซ่อนจากการรายงาน ผู้เขียนยังเห็น คนอื่นเห็นเหมือนไม่มี
+func (r *commentRepository) GetComment(viewerID, commentID int) (*models.Comment, error) {
+	c, err := scanComment(r.db.QueryRow(qCommentSelect+`
+		WHERE c.comment_id = $1 AND (c.comment_hidden_at IS NULL OR c.comment_user_id = $2);`, commentID, viewerID))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrCommentNotFound
+	}
+	return c, err
+}
+
+// รวมคอมเมนต์ที่ถูกซ่อน ใช้กับการลบโดยเจ้าของโพสต์ (moderation) เท่านั้น
+func (r *commentRepository) FindComment(commentID int) (*models.Comment, error) {
+	c, err := scanComment(r.db.QueryRow(qCommentSelect+` WHERE c.comment_id = $1;`, commentID))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrCommentNotFound
+	}
+	return c, err
+}
+
+func (r *commentRepository) UpdateComment(commentID int, body string) error {
+	res, err := r.db.Exec(`
+		UPDATE comments
+		SET comment_body = $2, comment_edited = true, comment_updated_at = now()
+		WHERE comment_id = $1;`, commentID, body)
+	if err != nil {
+		return fmt.Errorf("update comment: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrCommentNotFound
+	}
+	return nil
+}
+
+// ลบคอมเมนต์ (reply ถูกลบตามด้วย on delete cascade)
+func (r *commentRepository) DeleteComment(commentID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var postID int
+	err = tx.QueryRow(`DELETE FROM comments WHERE comment_id = $1 RETURNING comment_post_id;`, commentID).Scan(&postID)
+	if err == sql.ErrNoRows {
+		return models.ErrCommentNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("delete comment: %w", err)
+	}
+	if err := refreshCommentCount(tx, postID); err != nil {
+		return fmt.Errorf("update comment count: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *commentRepository) list(where string, id int, after *pagination.Keyset, limit int) ([]models.Comment, error) {
+	var afterTS, afterID interface{}
+	if after != nil {
+		afterTS, afterID = after.CreatedAt, after.ID
+	}
+	rows, err := r.db.Query(qCommentSelect+where+qCommentAfter, id, afterTS, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.Comment, 0, limit)
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *c)
+	}
+	return out, rows.Err()
+}
+
+func (r *commentRepository) ListComments(postID int, after *pagination.Keyset, limit int) ([]models.Comment, error) {
+	return r.list(` WHERE c.comment_post_id = $1 AND c.comment_parent_id IS NULL AND c.comment_hidden_at IS NULL`, postID, after, limit)
+}
+
+func (r *commentRepository) ListReplies(parentID int, after *pagination.Keyset, limit int) ([]models.Comment, error) {
+	return r.list(` WHERE c.comment_parent_id = $1 AND c.comment_hidden_at IS NULL`, parentID, after, limit)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"chaladshare_backend/internal/comments/models"
+	"chaladshare_backend/internal/comments/repository"
+	"chaladshare_backend/internal/pagination"
+	postservice "chaladshare_backend/internal/posts/service"
+)
+
+type CommentService interface {
+	CreateComment(viewerID, postID int, req models.CreateCommentRequest) (*models.Comment, error)
+	UpdateComment(viewerID, commentID int, body string) (*models.Comment, error)
+	DeleteComment(viewerID, commentID int) error
+
+	ListComments(viewerID, postID int, cursor string, limit int) (*models.CommentPage, error)
+	ListReplies(viewerID, commentID int, cursor string, limit int) (*models.CommentPage, error)
+}
+
+type commentService struct {
+	commentRepo repository.CommentRepository
+	postService postservice.PostService
+	cursors     *pagination.Codec
+}
+
+func NewCommentService(commentRepo repository.CommentRepository, postService postservice.PostService, cursors *pagination.Codec) CommentService {
+	return &commentService{
+		commentRepo: commentRepo,
+		postService: postService,
+		cursors:     cursors,
+	}
+}
+
+func normalizeBody(body string) (string, error) {
+	b := strings.TrimSpace(body)
+	if b == "" || utf8.RuneCountInString(b) > models.MaxBodyLen {
+		return "", models.ErrInvalidBody
+	}
+	return b, nil
+}
+
+// สิทธิ์ดู/คอมเมนต์ตามโพสต์แม่ (ใช้กฎเดียวกับหน้ารายละเอียดโพสต์)
+func (s *commentService) canView(viewerID, postID int) error {
+	ok, reason, err := s.postService.ViewPost(viewerID, postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrPostNotFound
+		}
+		return err
+	}
+	if !ok {
+		if reason == "not_found" {
+			return models.ErrPostNotFound
+		}
+		return models.ErrForbidden
+	}
+	return nil
+}
+
+func (s *commentService) CreateComment(viewerID, postID int, req models.CreateCommentRequest) (*models.Comment, error) {
+	body, err := normalizeBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canView(viewerID, postID); err != nil {
+		return nil, err
+	}
+
+	if req.ParentID != nil {
+		parent, err := s.commentRepo.GetComment(viewerID, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.PostID != postID {
+			return nil, models.ErrCommentNotFound
+		}
+		// reply ได้ชั้นเดียว: ตอบ reply ไม่ได้
+		if parent.ParentID != nil {
+			return nil, models.ErrReplyDepth
+		}
+	}
+
+	id, err := s.commentRepo.CreateComment(postID, viewerID, req.ParentID, body)
+	if err != nil {
+		return nil, err
+	}
+	return s.commentRepo.GetComment(viewerID, id)
+}
+
+// แก้ไขได้เฉพาะผู้เขียนคอมเมนต์
+func (s *commentService) UpdateComment(viewerID, commentID int, body string) (*models.Comment, error) {
+	b, err := normalizeBody(body)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.commentRepo.GetComment(viewerID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if c.AuthorID != viewerID {
+		return nil, models.ErrForbidden
+	}
+	if err := s.canView(viewerID, c.PostID); err != nil {
+		return nil, err
+	}
+	if err := s.commentRepo.UpdateComment(commentID, b); err != nil {
+		return nil, err
+	}
+	return s.commentRepo.GetComment(viewerID, commentID)
+}
+
+// ลบได้โดยผู้เขียนคอมเมนต์ หรือเจ้าของโพสต์ (moderation) รวมคอมเมนต์ที่ถูกซ่อนจากการรายงาน
+func (s *commentService) DeleteComment(viewerID, commentID int) error {
+	c, err := s.commentRepo.FindComment(commentID)
+	if err != nil {
+		return err
+	}
+	if c.AuthorID != viewerID {
+		isOwner, err := s.postService.IsOwner(c.PostID, viewerID)
+		if err != nil {
+			return err
+		}
+		if !isOwner {
+			// คนอื่นเห็นคอมเมนต์ที่ถูกซ่อนเหมือนไม่มี
+			if c.HiddenAt != nil {
+				return models.ErrCommentNotFound
+			}
+			return models.ErrForbidden
+		}
+	}
+	return s.commentRepo.DeleteComment(commentID)
+}
+
+func (s *commentService) decodeCursor(cursor string) (*pagination.Keyset, error) {
+	var k pagination.Keyset
+	ok, err := s.cursors.Decode(cursor, &k)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (s *commentService) toPage(items []models.Comment, limit int) (*models.CommentPage, error) {
+	page := &models.CommentPage{Items: items}
+	if len(items) <= limit {
+		return page, nil
+	}
+	page.Items = items[:limit]
+	last := page.Items[limit-1]
+	cursor, err := s.cursors.Encode(pagination.Keyset{CreatedAt: last.CreatedAt, ID: last.CommentID})
+	if err != nil {
+		return nil, err
+	}
+	page.NextCursor = cursor
+	return page, nil
+}
+
+func (s *commentService) ListComments(viewerID, postID int, cursor string, limit int) (*models.CommentPage, error) {
+	after, err := s.decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canView(viewerID, postID); err != nil {
+		return nil, err
+	}
+	limit = pagination.ClampLimit(limit)
+	items, err := s.commentRepo.ListComments(postID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	return s.toPage(items, limit)
+}
+
+func (s *commentService) ListReplies(viewerID, commentID int, cursor string, limit int) (*models.CommentPage, error) {
+	after, err := s.decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := s.commentRepo.GetComment(viewerID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canView(viewerID, parent.PostID); err != nil {
+		return nil, err
+	}
+	limit = pagination.ClampLimit(limit)
+	items, err := s.commentRepo.ListReplies(commentID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	return s.toPage(items, limit)
+}
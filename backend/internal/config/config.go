@@ -18,10 +18,27 @@ type Config struct {
 	DatabaseName     string
 	DatabaseSSLMode  string
 	JWTSecret        string
+	CursorSecret     string // ลายเซ็น cursor ของรายการโพสต์ (ว่าง = ใช้ JWTSecret)
 
 	TokenTTLMinutes int
 	CookieName      string
 	AllowOrigin     string
+
+	// recommend (น้ำหนัก content vs collaborative filtering)
+	RecommendContentWeight    float64
+	RecommendCFWeight         float64
+	RecommendCFRefreshMinutes int
+	RecommendCacheTTLMinutes  int
+
+	// k-means clustering ของ document_features
+	ClusterK              int
+	ClusterRefreshMinutes int
+
+	// รอบรีเฟรชคะแนน trending
+	TrendingRefreshMinutes int
+
+	// ซ่อนโพสต์/คอมเมนต์อัตโนมัติเมื่อผู้รายงานไม่ซ้ำครบจำนวนนี้ (0 = ปิด)
+	ReportAutoHideThreshold int
 }
 
 func LoadConfig() (Config, error) {
@@ -39,6 +56,7 @@ func LoadConfig() (Config, error) {
 	viper.SetDefault("POSTGRES.DBNAME", "chaladshare")
 	viper.SetDefault("POSTGRES.SSLMODE", "disable")
 	viper.SetDefault("JWT.SECRET", "changeme")
+	viper.SetDefault("CURSOR.SECRET", "")
 	viper.SetDefault("APP.PORT", "8080")
 
 	// ADD THIS PART
@@ -46,6 +64,18 @@ func LoadConfig() (Config, error) {
 	viper.SetDefault("COOKIE.NAME", "access_token")
 	viper.SetDefault("ALLOW.ORIGIN", "http://localhost:3000")
 
+	viper.SetDefault("RECOMMEND.CONTENT_WEIGHT", 0.7)
+	viper.SetDefault("RECOMMEND.CF_WEIGHT", 0.3)
+	viper.SetDefault("RECOMMEND.CF_REFRESH_MINUTES", 30)
+	viper.SetDefault("RECOMMEND.CACHE_TTL_MINUTES", 60)
+
+	viper.SetDefault("CLUSTER.K", 8)
+	viper.SetDefault("CLUSTER.REFRESH_MINUTES", 360)
+
+	viper.SetDefault("TRENDING.REFRESH_MINUTES", 15)
+
+	viper.SetDefault("REPORTS.AUTO_HIDE_THRESHOLD", 5)
+
 	// Set config values
 	config := Config{
 		AppPort:          viper.GetString("APP.PORT"),
@@ -56,11 +86,28 @@ func LoadConfig() (Config, error) {
 		DatabaseName:     viper.GetString("POSTGRES.DBNAME"),
 		DatabaseSSLMode:  viper.GetString("POSTGRES.SSLMODE"),
 		JWTSecret:        viper.GetString("JWT.SECRET"),
+		CursorSecret:     viper.GetString("CURSOR.SECRET"),
 
 		// ADD THIS PATH
 		TokenTTLMinutes: viper.GetInt("JWT.TTL_MINUTES"),
 		CookieName:      viper.GetString("COOKIE.NAME"),
 		AllowOrigin:     viper.GetString("ALLOW.ORIGIN"),
+
+		RecommendContentWeight:    viper.GetFloat64("RECOMMEND.CONTENT_WEIGHT"),
+		RecommendCFWeight:         viper.GetFloat64("RECOMMEND.CF_WEIGHT"),
+		RecommendCFRefreshMinutes: viper.GetInt("RECOMMEND.CF_REFRESH_MINUTES"),
+		RecommendCacheTTLMinutes:  viper.GetInt("RECOMMEND.CACHE_TTL_MINUTES"),
+
+		ClusterK:              viper.GetInt("CLUSTER.K"),
+		ClusterRefreshMinutes: viper.GetInt("CLUSTER.REFRESH_MINUTES"),
+
+		TrendingRefreshMinutes: viper.GetInt("TRENDING.REFRESH_MINUTES"),
+
+		ReportAutoHideThreshold: viper.GetInt("REPORTS.AUTO_HIDE_THRESHOLD"),
+	}
+
+	if config.CursorSecret == "" {
+		config.CursorSecret = config.JWTSecret
 	}
 
 	return config, nil
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"chaladshare_backend/internal/docfeatures/service"
+)
+
+type FeatureHandler struct {
+	featureService service.FeatureService
+}
+
+func NewFeatureHandler(featureService service.FeatureService) *FeatureHandler {
+	return &FeatureHandler{featureService: featureService}
+}
+
+// POST /api/v1/admin/clusters/run (admin) สั่งรัน k-means ทันที
+func (h *FeatureHandler) RunClustering(c *gin.Context) {
+	// ?wait=true รอผลจนเสร็จ ไม่งั้นรันเบื้องหลังแล้วตอบ 202
+	if c.Query("wait") == "true" {
+		runs, err := h.featureService.RunClustering()
+		if err != nil {
+			if errors.Is(err, service.ErrClusteringRunning) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": runs})
+		return
+	}
+
+	go func() {
+		if _, err := h.featureService.RunClustering(); err != nil {
+			log.Printf("clustering (on demand) failed: %v", err)
+		}
+	}()
+	c.JSON(http.StatusAccepted, gin.H{"message": "clustering started"})
+}
+
+// GET /api/v1/admin/clusters/runs?limit=20
+func (h *FeatureHandler) ListClusterRuns(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	runs, err := h.featureService.ListClusterRuns(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": runs})
+}
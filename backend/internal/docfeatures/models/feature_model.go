@@ -18,11 +18,33 @@ type DocumentFeature struct {
 	StyleLabel    *string         `json:"style_label,omitempty"`
 	StyleVector   json.RawMessage `json:"style_vector,omitempty"`
 	ClusterID     *int            `json:"cluster_id,omitempty"`
+	ClusterModel  *string         `json:"cluster_model_version,omitempty"`
 	ErrorMessage  *string         `json:"error_message,omitempty"`
 	CreatedAt     time.Time       `json:"created_at"`
 	UpdatedAt     time.Time       `json:"updated_at"`
 }
 
+// style_label ที่นำไป clustering (ที่เหลือได้ cluster_id = -1)
+var ClusterableLabels = []string{"typed", "handwritten"}
+
+// เอกสารที่มี content_embedding พร้อม clustering
+type EmbeddingRow struct {
+	DocumentID int
+	Embedding  []float64
+}
+
+// ผลการรัน k-means หนึ่งรอบต่อ style_label
+type ClusterRun struct {
+	RunID        int       `json:"run_id"`
+	ModelVersion string    `json:"model_version"`
+	StyleLabel   string    `json:"style_label"`
+	K            int       `json:"k"`
+	DocCount     int       `json:"doc_count"`
+	Inertia      float64   `json:"inertia"`
+	Iterations   int       `json:"iterations"`
+	CreatedAt    time.Time `json:"run_created_at"`
+}
+
 // ตอนสร้างแถวเริ่มต้น
 type CreateQueuedInput struct {
 	DocumentID int `json:"document_id"`
@@ -7,6 +7,7 @@ import (
 
 	"chaladshare_backend/internal/docfeatures/models"
 
+	"github.com/lib/pq"
 	"github.com/pgvector/pgvector-go"
 )
 
@@ -16,6 +17,12 @@ type DocFeaturesRepo interface {
 	SaveResult(input models.SaveResult) error
 	MarkFailed(documentID int, msg string) error
 	GetByDocumentID(documentID int) (*models.DocumentFeature, error)
+
+	// clustering
+	ListEmbeddings(styleLabel string) ([]models.EmbeddingRow, error)
+	SaveClusters(run models.ClusterRun, docIDs, clusterIDs []int) (*models.ClusterRun, error)
+	MarkUnclusterable(modelVersion string) (int, error)
+	ListClusterRuns(limit int) ([]models.ClusterRun, error)
 }
 
 type FeatureRepo struct {
@@ -109,7 +116,7 @@ func (r *FeatureRepo) MarkFailed(documentID int, msg string) error {
 func (r *FeatureRepo) GetByDocumentID(documentID int) (*models.DocumentFeature, error) {
 	q := `
 		SELECT document_id, feature_status, style_label, style_vector_raw, cluster_id,
-		       error_message, created_at, updated_at
+		       cluster_model_version, error_message, created_at, updated_at
 		FROM document_features
 		WHERE document_id = $1;
 	`
@@ -121,6 +128,7 @@ func (r *FeatureRepo) GetByDocumentID(documentID int) (*models.DocumentFeature,
 		&out.StyleLabel,
 		&out.StyleVector,
 		&out.ClusterID,
+		&out.ClusterModel,
 		&out.ErrorMessage,
 		&out.CreatedAt,
 		&out.UpdatedAt,
@@ -133,3 +141,121 @@ func (r *FeatureRepo) GetByDocumentID(documentID int) (*models.DocumentFeature,
 	}
 	return &out, nil
 }
+
+func (r *FeatureRepo) ListEmbeddings(styleLabel string) ([]models.EmbeddingRow, error) {
+	q := `
+		SELECT df.document_id, df.content_embedding
+		FROM document_features df
+		JOIN documents d ON d.document_id = df.document_id
+		WHERE df.feature_status = $1
+		  AND df.style_label = $2
+		  AND df.content_embedding IS NOT NULL
+		  AND d.document_deleted_at IS NULL
+		ORDER BY df.document_id;
+	`
+	rows, err := r.db.Query(q, models.FeatureDone, styleLabel)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.EmbeddingRow
+	for rows.Next() {
+		var (
+			id  int
+			vec pgvector.Vector
+		)
+		if err := rows.Scan(&id, &vec); err != nil {
+			return nil, err
+		}
+		f32 := vec.Slice()
+		emb := make([]float64, len(f32))
+		for i, v := range f32 {
+			emb[i] = float64(v)
+		}
+		out = append(out, models.EmbeddingRow{DocumentID: id, Embedding: emb})
+	}
+	return out, rows.Err()
+}
+
+// เขียน cluster_id ทั้งชุด + บันทึก run ใน transaction เดียว
+func (r *FeatureRepo) SaveClusters(run models.ClusterRun, docIDs, clusterIDs []int) (*models.ClusterRun, error) {
+	if len(docIDs) != len(clusterIDs) {
+		return nil, fmt.Errorf("docIDs/clusterIDs length mismatch")
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE document_features df
+		SET cluster_id            = v.cluster_id,
+		    cluster_model_version = $3,
+		    cluster_updated_at    = now()
+		FROM UNNEST($1::int[], $2::int[]) AS v(document_id, cluster_id)
+		WHERE df.document_id = v.document_id;
+	`, pq.Array(docIDs), pq.Array(clusterIDs), run.ModelVersion); err != nil {
+		return nil, fmt.Errorf("update cluster_id: %w", err)
+	}
+
+	out := run
+	if err := tx.QueryRow(`
+		INSERT INTO document_cluster_runs (model_version, style_label, k, doc_count, inertia, iterations)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING run_id, run_created_at;
+	`, run.ModelVersion, run.StyleLabel, run.K, run.DocCount, run.Inertia, run.Iterations,
+	).Scan(&out.RunID, &out.CreatedAt); err != nil {
+		return nil, fmt.Errorf("insert cluster run: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return &out, nil
+}
+
+// เอกสารที่ไม่เข้า clustering (empty/not_typed/unknown หรือไม่มี embedding) → cluster_id = -1
+func (r *FeatureRepo) MarkUnclusterable(modelVersion string) (int, error) {
+	res, err := r.db.Exec(`
+		UPDATE document_features
+		SET cluster_id            = -1,
+		    cluster_model_version = $2,
+		    cluster_updated_at    = now()
+		WHERE feature_status = $1
+		  AND (NOT (style_label = ANY($3)) OR style_label IS NULL OR content_embedding IS NULL)
+		  AND cluster_id IS DISTINCT FROM -1;
+	`, models.FeatureDone, modelVersion, pq.Array(models.ClusterableLabels))
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+func (r *FeatureRepo) ListClusterRuns(limit int) ([]models.ClusterRun, error) {
+	rows, err := r.db.Query(`
+		SELECT run_id, model_version, style_label, k, doc_count,
+		       COALESCE(inertia, 0), COALESCE(iterations, 0), run_created_at
+		FROM document_cluster_runs
+		ORDER BY run_created_at DESC, run_id DESC
+		LIMIT $1;
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.ClusterRun, 0, limit)
+	for rows.Next() {
+		var cr models.ClusterRun
+		if err := rows.Scan(&cr.RunID, &cr.ModelVersion, &cr.StyleLabel, &cr.K, &cr.DocCount,
+			&cr.Inertia, &cr.Iterations, &cr.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, cr)
+	}
+	return out, rows.Err()
+}
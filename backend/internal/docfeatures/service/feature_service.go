@@ -1,7 +1,12 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
 
 	"chaladshare_backend/internal/connect"
 	"chaladshare_backend/internal/docfeatures/models"
@@ -15,17 +20,36 @@ type FeatureService interface {
 	MarkFailed(documentID int, msg string) error
 	GetByDocumentID(documentID int) (*models.DocumentFeature, error)
 	ProcessDocument(documentID int, pdfPath string)
+
+	// clustering (k-means บน content_embedding)
+	RunClustering() ([]models.ClusterRun, error)
+	ListClusterRuns(limit int) ([]models.ClusterRun, error)
 }
 
+var ErrClusteringRunning = errors.New("clustering is already running")
+
+const (
+	clusterMaxIter = 50
+	clusterSeed    = 42
+	clusterAlgo    = "kmeans-v1"
+)
+
 type featureService struct {
 	featureRepo repository.DocFeaturesRepo
 	aiClient    *connect.Client
+
+	clusterK  int
+	clusterMu sync.Mutex
 }
 
-func NewFeatureService(featureRepo repository.DocFeaturesRepo, aiClient *connect.Client) FeatureService {
+func NewFeatureService(featureRepo repository.DocFeaturesRepo, aiClient *connect.Client, clusterK int) FeatureService {
+	if clusterK <= 0 {
+		clusterK = 8
+	}
 	return &featureService{
 		featureRepo: featureRepo,
 		aiClient:    aiClient,
+		clusterK:    clusterK,
 	}
 }
 
@@ -113,3 +137,75 @@ func (s *featureService) ProcessDocument(documentID int, pdfPath string) {
 		return
 	}
 }
+
+// จำนวน cluster ต่อ label: ไม่เกิน clusterK และไม่เกิน sqrt(n/2) ให้แต่ละกลุ่มมีสมาชิกพอ
+func chooseK(maxK, n int) int {
+	if n <= 1 {
+		return n
+	}
+	k := int(math.Sqrt(float64(n) / 2))
+	if k < 1 {
+		k = 1
+	}
+	if k > maxK {
+		k = maxK
+	}
+	return k
+}
+
+func (s *featureService) RunClustering() ([]models.ClusterRun, error) {
+	if !s.clusterMu.TryLock() {
+		return nil, ErrClusteringRunning
+	}
+	defer s.clusterMu.Unlock()
+
+	version := fmt.Sprintf("%s-%s", clusterAlgo, time.Now().UTC().Format("20060102T150405Z"))
+
+	runs := make([]models.ClusterRun, 0, len(models.ClusterableLabels))
+	for _, label := range models.ClusterableLabels {
+		rows, err := s.featureRepo.ListEmbeddings(label)
+		if err != nil {
+			return runs, fmt.Errorf("list embeddings (%s): %w", label, err)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		points := make([][]float64, len(rows))
+		docIDs := make([]int, len(rows))
+		for i, r := range rows {
+			points[i] = r.Embedding
+			docIDs[i] = r.DocumentID
+		}
+
+		k := chooseK(s.clusterK, len(points))
+		res := kmeans(points, k, clusterMaxIter, clusterSeed)
+
+		run, err := s.featureRepo.SaveClusters(models.ClusterRun{
+			ModelVersion: version,
+			StyleLabel:   label,
+			K:            k,
+			DocCount:     len(points),
+			Inertia:      res.Inertia,
+			Iterations:   res.Iterations,
+		}, docIDs, res.Assign)
+		if err != nil {
+			return runs, fmt.Errorf("save clusters (%s): %w", label, err)
+		}
+		runs = append(runs, *run)
+	}
+
+	if n, err := s.featureRepo.MarkUnclusterable(version); err != nil {
+		return runs, fmt.Errorf("mark unclusterable: %w", err)
+	} else if n > 0 {
+		log.Printf("clustering %s: %d documents marked as -1", version, n)
+	}
+	return runs, nil
+}
+
+func (s *featureService) ListClusterRuns(limit int) ([]models.ClusterRun, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.featureRepo.ListClusterRuns(limit)
+}
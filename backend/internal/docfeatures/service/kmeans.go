@@ -0,0 +1,162 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+)
+
+// ผลลัพธ์ k-means: assign[i] = cluster ของจุด i
+type kmeansResult struct {
+	Assign     []int
+	Inertia    float64
+	Iterations int
+}
+
+// spherical k-means: normalize ทุกจุดให้ยาว 1 ก่อน ระยะ euclidean จึงสอดคล้องกับ cosine
+// ใช้ k-means++ เลือกจุดเริ่มต้น และ seed คงที่เพื่อให้รันซ้ำได้ผลเดิม
+func kmeans(points [][]float64, k, maxIter int, seed int64) kmeansResult {
+	n := len(points)
+	if n == 0 || k <= 0 {
+		return kmeansResult{}
+	}
+	if k > n {
+		k = n
+	}
+
+	data := make([][]float64, n)
+	for i, p := range points {
+		data[i] = normalize(p)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	centroids := initPlusPlus(data, k, rng)
+
+	assign := make([]int, n)
+	for i := range assign {
+		assign[i] = -1
+	}
+
+	var (
+		inertia float64
+		iter    int
+	)
+	for iter = 1; iter <= maxIter; iter++ {
+		changed := 0
+		inertia = 0
+		for i, p := range data {
+			best, bestDist := 0, math.Inf(1)
+			for c, ctr := range centroids {
+				if d := sqDist(p, ctr); d < bestDist {
+					best, bestDist = c, d
+				}
+			}
+			if assign[i] != best {
+				assign[i] = best
+				changed++
+			}
+			inertia += bestDist
+		}
+
+		// คำนวณ centroid ใหม่
+		dim := len(data[0])
+		sums := make([][]float64, k)
+		counts := make([]int, k)
+		for c := range sums {
+			sums[c] = make([]float64, dim)
+		}
+		for i, p := range data {
+			c := assign[i]
+			counts[c]++
+			for d := 0; d < dim && d < len(p); d++ {
+				sums[c][d] += p[d]
+			}
+		}
+		for c := range centroids {
+			if counts[c] == 0 {
+				// cluster ว่าง: สุ่มจุดใหม่มาแทน
+				centroids[c] = data[rng.Intn(n)]
+				continue
+			}
+			for d := range sums[c] {
+				sums[c][d] /= float64(counts[c])
+			}
+			centroids[c] = normalize(sums[c])
+		}
+
+		if changed == 0 {
+			break
+		}
+	}
+	if iter > maxIter {
+		iter = maxIter
+	}
+
+	return kmeansResult{Assign: assign, Inertia: inertia, Iterations: iter}
+}
+
+func initPlusPlus(data [][]float64, k int, rng *rand.Rand) [][]float64 {
+	centroids := make([][]float64, 0, k)
+	centroids = append(centroids, data[rng.Intn(len(data))])
+
+	dist := make([]float64, len(data))
+	for len(centroids) < k {
+		var total float64
+		for i, p := range data {
+			best := math.Inf(1)
+			for _, ctr := range centroids {
+				if d := sqDist(p, ctr); d < best {
+					best = d
+				}
+			}
+			dist[i] = best
+			total += best
+		}
+
+		// ทุกจุดซ้ำกับ centroid แล้ว
+		if total == 0 {
+			centroids = append(centroids, data[rng.Intn(len(data))])
+			continue
+		}
+
+		target := rng.Float64() * total
+		pick := len(data) - 1
+		for i, d := range dist {
+			target -= d
+			if target <= 0 {
+				pick = i
+				break
+			}
+		}
+		centroids = append(centroids, data[pick])
+	}
+	return centroids
+}
+
+func normalize(v []float64) []float64 {
+	var norm float64
+	for _, x := range v {
+		norm += x * x
+	}
+	out := make([]float64, len(v))
+	if norm == 0 {
+		return out
+	}
+	norm = math.Sqrt(norm)
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+func sqDist(a, b []float64) float64 {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	var s float64
+	for i := 0; i < n; i++ {
+		d := a[i] - b[i]
+		s += d * d
+	}
+	return s
+}
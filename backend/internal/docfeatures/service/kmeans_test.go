@@ -0,0 +1,170 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// จุดรอบทิศทางหลัก 3 ทิศ (แยกกันชัดเจนเมื่อวัดแบบ cosine)
+func threeGroups() ([][]float64, []int) {
+	var (
+		points [][]float64
+		groups []int
+	)
+	dirs := [][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	for g, dir := range dirs {
+		for j := 0; j < 5; j++ {
+			p := make([]float64, len(dir))
+			for d := range dir {
+				p[d] = dir[d]*float64(j+1) + 0.01*float64(j)
+			}
+			points = append(points, p)
+			groups = append(groups, g)
+		}
+	}
+	return points, groups
+}
+
+func TestKmeansDegenerateInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		points [][]float64
+		k      int
+	}{
+		{"no points", nil, 3},
+		{"zero k", [][]float64{{1, 0}}, 0},
+		{"negative k", [][]float64{{1, 0}}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := kmeans(tt.points, tt.k, 10, 1)
+			if res.Assign != nil || res.Inertia != 0 || res.Iterations != 0 {
+				t.Fatalf("kmeans = %+v, want zero result", res)
+			}
+		})
+	}
+}
+
+func TestKmeansConverges(t *testing.T) {
+	points, groups := threeGroups()
+	res := kmeans(points, 3, 50, 7)
+
+	if len(res.Assign) != len(points) {
+		t.Fatalf("len(Assign) = %d, want %d", len(res.Assign), len(points))
+	}
+	if res.Iterations >= 50 {
+		t.Fatalf("Iterations = %d, want convergence before maxIter", res.Iterations)
+	}
+	if res.Inertia > 0.01 {
+		t.Fatalf("Inertia = %f, want near zero for separated groups", res.Inertia)
+	}
+
+	// กลุ่มเดียวกัน = cluster เดียวกัน, ต่างกลุ่ม = ต่าง cluster
+	clusterOf := map[int]int{}
+	seen := map[int]bool{}
+	for i, c := range res.Assign {
+		g := groups[i]
+		if want, ok := clusterOf[g]; ok {
+			if c != want {
+				t.Fatalf("point %d in group %d assigned %d, want %d", i, g, c, want)
+			}
+			continue
+		}
+		if seen[c] {
+			t.Fatalf("cluster %d shared by two groups", c)
+		}
+		clusterOf[g], seen[c] = c, true
+	}
+}
+
+func TestKmeansDeterministic(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	points := make([][]float64, 40)
+	for i := range points {
+		points[i] = []float64{rng.Float64(), rng.Float64(), rng.Float64(), rng.Float64()}
+	}
+
+	a := kmeans(points, 4, 20, 42)
+	b := kmeans(points, 4, 20, 42)
+	if a.Iterations != b.Iterations || a.Inertia != b.Inertia {
+		t.Fatalf("same seed gave %+v and %+v", a, b)
+	}
+	for i := range a.Assign {
+		if a.Assign[i] != b.Assign[i] {
+			t.Fatalf("Assign[%d] = %d and %d with same seed", i, a.Assign[i], b.Assign[i])
+		}
+	}
+	if a.Iterations > 20 {
+		t.Fatalf("Iterations = %d, want <= maxIter", a.Iterations)
+	}
+}
+
+// k มากกว่าจำนวนจุด หรือทุกจุดซ้ำกัน ทำให้มี cluster ว่าง ต้องไม่ panic และ assign อยู่ในช่วง
+func TestKmeansEmptyClusters(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  [][]float64
+		k       int
+		maxK    int
+		inertia float64
+	}{
+		{"k larger than n", [][]float64{{1, 0}, {0, 1}}, 5, 2, 0},
+		{"identical points", [][]float64{{2, 2}, {2, 2}, {2, 2}, {2, 2}}, 3, 3, 0},
+		{"zero vectors", [][]float64{{0, 0}, {0, 0}, {1, 0}}, 2, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := kmeans(tt.points, tt.k, 10, 1)
+			if len(res.Assign) != len(tt.points) {
+				t.Fatalf("len(Assign) = %d, want %d", len(res.Assign), len(tt.points))
+			}
+			for i, c := range res.Assign {
+				if c < 0 || c >= tt.maxK {
+					t.Fatalf("Assign[%d] = %d, want 0..%d", i, c, tt.maxK-1)
+				}
+			}
+			if math.Abs(res.Inertia-tt.inertia) > 1e-9 {
+				t.Fatalf("Inertia = %f, want %f", res.Inertia, tt.inertia)
+			}
+		})
+	}
+}
+
+func TestInitPlusPlus(t *testing.T) {
+	points, _ := threeGroups()
+	data := make([][]float64, len(points))
+	for i, p := range points {
+		data[i] = normalize(p)
+	}
+
+	// จุดแยกกันชัดเจน: k-means++ ควรได้ centroid คนละทิศ
+	centroids := initPlusPlus(data, 3, rand.New(rand.NewSource(1)))
+	if len(centroids) != 3 {
+		t.Fatalf("len(centroids) = %d, want 3", len(centroids))
+	}
+	for i := range centroids {
+		for j := i + 1; j < len(centroids); j++ {
+			if sqDist(centroids[i], centroids[j]) < 0.5 {
+				t.Fatalf("centroids %d and %d too close: %v %v", i, j, centroids[i], centroids[j])
+			}
+		}
+	}
+
+	// ทุกจุดซ้ำกัน (ระยะรวมเป็น 0) ยังต้องได้ครบ k
+	same := [][]float64{{1, 0}, {1, 0}}
+	if got := initPlusPlus(same, 2, rand.New(rand.NewSource(1))); len(got) != 2 {
+		t.Fatalf("len(centroids) = %d, want 2", len(got))
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got := normalize([]float64{3, 4})
+	if math.Abs(got[0]-0.6) > 1e-9 || math.Abs(got[1]-0.8) > 1e-9 {
+		t.Fatalf("normalize = %v, want [0.6 0.8]", got)
+	}
+	zero := normalize([]float64{0, 0})
+	if zero[0] != 0 || zero[1] != 0 {
+		t.Fatalf("normalize(zero) = %v, want zero vector", zero)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"chaladshare_backend/internal/experiments/models"
+	"chaladshare_backend/internal/experiments/service"
+)
+
+type ExperimentHandler struct {
+	experimentService service.ExperimentService
+}
+
+func NewExperimentHandler(experimentService service.ExperimentService) *ExperimentHandler {
+	return &ExperimentHandler{experimentService: experimentService}
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrExperimentNotFound), errors.Is(err, models.ErrNotInExperiment):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrExperimentKeyTaken), errors.Is(err, models.ErrSurfaceBusy),
+		errors.Is(err, models.ErrInvalidStatusChange):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrInvalidExperiment), errors.Is(err, models.ErrInvalidVariantConfig):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func parseID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid experiment id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// GET /api/v1/admin/experiments
+func (h *ExperimentHandler) ListExperiments(c *gin.Context) {
+	items, err := h.experimentService.ListExperiments()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// POST /api/v1/admin/experiments
+func (h *ExperimentHandler) CreateExperiment(c *gin.Context) {
+	var req models.CreateExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	exp, err := h.experimentService.CreateExperiment(req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": exp})
+}
+
+// POST /api/v1/admin/experiments/:id/start
+func (h *ExperimentHandler) StartExperiment(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	exp, err := h.experimentService.StartExperiment(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": exp})
+}
+
+// POST /api/v1/admin/experiments/:id/stop
+func (h *ExperimentHandler) StopExperiment(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	exp, err := h.experimentService.StopExperiment(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": exp})
+}
+
+// GET /api/v1/admin/experiments/:id/report
+func (h *ExperimentHandler) GetReport(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	report, err := h.experimentService.GetReport(id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// POST /api/v1/experiments/:key/click  body: {"post_id": 1}
+func (h *ExperimentHandler) RecordClick(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid <= 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.ClickRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.PostID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if err := h.experimentService.RecordClick(uid, c.Param("key"), req.PostID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// surface ที่ทำ experiment ได้
+const (
+	SurfaceRecommend = "recommend"
+	SurfacePopular   = "popular"
+	SurfaceSearch    = "search"
+)
+
+const (
+	StatusDraft   = "draft"
+	StatusRunning = "running"
+	StatusStopped = "stopped"
+)
+
+var (
+	ErrExperimentNotFound   = errors.New("experiment not found")
+	ErrExperimentKeyTaken   = errors.New("experiment key already exists")
+	ErrInvalidExperiment    = errors.New("invalid experiment")
+	ErrSurfaceBusy          = errors.New("another experiment is already running on this surface")
+	ErrInvalidStatusChange  = errors.New("invalid experiment status change")
+	ErrNotInExperiment      = errors.New("user is not in a running experiment")
+	ErrInvalidVariantConfig = errors.New("variant config must be a JSON object")
+)
+
+type Variant struct {
+	VariantID int             `json:"variant_id"`
+	Key       string          `json:"variant_key"`
+	Weight    int             `json:"variant_weight"`
+	Config    json.RawMessage `json:"variant_config"`
+}
+
+type Experiment struct {
+	ExperimentID int        `json:"experiment_id"`
+	Key          string     `json:"experiment_key"`
+	Surface      string     `json:"experiment_surface"`
+	Status       string     `json:"experiment_status"`
+	Variants     []Variant  `json:"variants"`
+	CreatedAt    time.Time  `json:"experiment_created_at"`
+	StartedAt    *time.Time `json:"experiment_started_at,omitempty"`
+	StoppedAt    *time.Time `json:"experiment_stopped_at,omitempty"`
+}
+
+// variant ที่ผู้ใช้ถูกสุ่มเข้า (deterministic ต่อ experiment key + user)
+type Assignment struct {
+	ExperimentID  int            `json:"-"`
+	ExperimentKey string         `json:"experiment_key"`
+	Variant       string         `json:"variant"`
+	Config        map[string]any `json:"-"`
+}
+
+// อ่านค่า string จาก config ของ variant
+func (a *Assignment) String(key string) string {
+	if a == nil {
+		return ""
+	}
+	s, _ := a.Config[key].(string)
+	return s
+}
+
+// อ่านค่าตัวเลขจาก config ของ variant
+func (a *Assignment) Float(key string) (float64, bool) {
+	if a == nil {
+		return 0, false
+	}
+	f, ok := a.Config[key].(float64)
+	return f, ok
+}
+
+type VariantRequest struct {
+	Key    string          `json:"variant_key" binding:"required"`
+	Weight int             `json:"variant_weight"`
+	Config json.RawMessage `json:"variant_config"`
+}
+
+// admin: สร้าง experiment (สถานะ draft)
+type CreateExperimentRequest struct {
+	Key      string           `json:"experiment_key" binding:"required"`
+	Surface  string           `json:"experiment_surface" binding:"required"`
+	Variants []VariantRequest `json:"variants" binding:"required"`
+}
+
+// client แจ้งว่าคลิกโพสต์จาก surface ที่อยู่ใน experiment
+type ClickRequest struct {
+	PostID int `json:"post_id" binding:"required"`
+}
+
+// ผลต่อ variant: นับเป็นคู่ (user, post) ที่เห็นไม่ซ้ำ
+// like/save นับเฉพาะที่เกิดหลังเห็นโพสต์ครั้งแรก
+type VariantReport struct {
+	Variant     string  `json:"variant"`
+	Users       int     `json:"users"`
+	Impressions int     `json:"impressions"`
+	Clicks      int     `json:"clicks"`
+	Likes       int     `json:"likes"`
+	Saves       int     `json:"saves"`
+	CTR         float64 `json:"ctr"`
+	LikeRate    float64 `json:"like_rate"`
+	SaveRate    float64 `json:"save_rate"`
+}
+
+type ExperimentReport struct {
+	Experiment Experiment      `json:"experiment"`
+	Variants   []VariantReport `json:"variants"`
+}
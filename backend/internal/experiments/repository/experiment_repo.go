@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"chaladshare_backend/internal/experiments/models"
+)
+
+type ExperimentRepository interface {
+	// admin
+	ListExperiments() ([]models.Experiment, error)
+	GetExperiment(experimentID int) (*models.Experiment, error)
+	CreateExperiment(key, surface string, variants []models.VariantRequest) (int, error)
+	SetStatus(experimentID int, from, to string) error
+	GetReport(experimentID int) ([]models.VariantReport, error)
+
+	// runtime
+	GetRunning(surface string) (*models.Experiment, error)
+	GetRunningByKey(key string) (*models.Experiment, error)
+	LogImpressions(experimentID int, variant string, userID int, postIDs, positions []int) error
+	LogClick(experimentID int, variant string, userID, postID int) error
+}
+
+type experimentRepository struct {
+	db *sql.DB
+}
+
+func NewExperimentRepository(db *sql.DB) ExperimentRepository {
+	return &experimentRepository{db: db}
+}
+
+const qExperimentSelect = `
+	SELECT e.experiment_id, e.experiment_key, e.experiment_surface, e.experiment_status,
+		e.experiment_created_at, e.experiment_started_at, e.experiment_stopped_at
+	FROM experiments e
+`
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func scanExperiment(row interface{ Scan(...any) error }) (*models.Experiment, error) {
+	var (
+		e                models.Experiment
+		started, stopped sql.NullTime
+	)
+	if err := row.Scan(&e.ExperimentID, &e.Key, &e.Surface, &e.Status,
+		&e.CreatedAt, &started, &stopped); err != nil {
+		return nil, err
+	}
+	if started.Valid {
+		t := started.Time
+		e.StartedAt = &t
+	}
+	if stopped.Valid {
+		t := stopped.Time
+		e.StoppedAt = &t
+	}
+	return &e, nil
+}
+
+func (r *experimentRepository) loadVariants(e *models.Experiment) error {
+	rows, err := r.db.Query(`
+		SELECT variant_id, variant_key, variant_weight, variant_config
+		FROM experiment_variants
+		WHERE variant_experiment_id = $1
+		ORDER BY variant_id;`, e.ExperimentID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	e.Variants = make([]models.Variant, 0, 4)
+	for rows.Next() {
+		var (
+			v   models.Variant
+			cfg []byte
+		)
+		if err := rows.Scan(&v.VariantID, &v.Key, &v.Weight, &cfg); err != nil {
+			return err
+		}
+		v.Config = json.RawMessage(cfg)
+		e.Variants = append(e.Variants, v)
+	}
+	return rows.Err()
+}
+
+func (r *experimentRepository) ListExperiments() ([]models.Experiment, error) {
+	rows, err := r.db.Query(qExperimentSelect + ` ORDER BY e.experiment_created_at DESC;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.Experiment, 0, 8)
+	for rows.Next() {
+		e, err := scanExperiment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range out {
+		if err := r.loadVariants(&out[i]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (r *experimentRepository) getOne(where string, arg any) (*models.Experiment, error) {
+	e, err := scanExperiment(r.db.QueryRow(qExperimentSelect+where, arg))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrExperimentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadVariants(e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *experimentRepository) GetExperiment(experimentID int) (*models.Experiment, error) {
+	return r.getOne(` WHERE e.experiment_id = $1;`, experimentID)
+}
+
+func (r *experimentRepository) GetRunning(surface string) (*models.Experiment, error) {
+	return r.getOne(` WHERE e.experiment_surface = $1 AND e.experiment_status = 'running';`, surface)
+}
+
+func (r *experimentRepository) GetRunningByKey(key string) (*models.Experiment, error) {
+	return r.getOne(` WHERE e.experiment_key = $1 AND e.experiment_status = 'running';`, key)
+}
+
+func (r *experimentRepository) CreateExperiment(key, surface string, variants []models.VariantRequest) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow(`
+		INSERT INTO experiments (experiment_key, experiment_surface)
+		VALUES ($1, $2)
+		RETURNING experiment_id;`, key, surface).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, models.ErrExperimentKeyTaken
+		}
+		return 0, fmt.Errorf("create experiment: %w", err)
+	}
+
+	for _, v := range variants {
+		if _, err := tx.Exec(`
+			INSERT INTO experiment_variants (variant_experiment_id, variant_key, variant_weight, variant_config)
+			VALUES ($1, $2, $3, $4::jsonb);`, id, v.Key, v.Weight, string(v.Config)); err != nil {
+			return 0, fmt.Errorf("create variant %q: %w", v.Key, err)
+		}
+	}
+	return id, tx.Commit()
+}
+
+// เปลี่ยนสถานะแบบมีเงื่อนไข (from → to) กันการเปลี่ยนซ้อน
+func (r *experimentRepository) SetStatus(experimentID int, from, to string) error {
+	query := `
+		UPDATE experiments
+		SET experiment_status = $3,
+			experiment_started_at = CASE WHEN $3 = 'running' THEN now() ELSE experiment_started_at END,
+			experiment_stopped_at = CASE WHEN $3 = 'stopped' THEN now() ELSE experiment_stopped_at END
+		WHERE experiment_id = $1 AND experiment_status = $2;`
+	res, err := r.db.Exec(query, experimentID, from, to)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrSurfaceBusy
+		}
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrInvalidStatusChange
+	}
+	return nil
+}
+
+// positions[i] = ตำแหน่งจริงของ postIDs[i] ในรายการ (เริ่มที่ 1)
+func (r *experimentRepository) LogImpressions(experimentID int, variant string, userID int, postIDs, positions []int) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(postIDs))
+	for i, id := range postIDs {
+		ids[i] = int64(id)
+	}
+	pos := make([]int64, len(positions))
+	for i, p := range positions {
+		pos[i] = int64(p)
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO experiment_impressions (impression_experiment_id, impression_variant_key,
+			impression_user_id, impression_post_id, impression_position)
+		SELECT $1, $2, $3, x.post_id, x.position
+		FROM UNNEST($4::int[], $5::int[]) AS x(post_id, position);`,
+		experimentID, variant, userID, pq.Array(ids), pq.Array(pos))
+	return err
+}
+
+func (r *experimentRepository) LogClick(experimentID int, variant string, userID, postID int) error {
+	_, err := r.db.Exec(`
+		INSERT INTO experiment_clicks (click_experiment_id, click_variant_key, click_user_id, click_post_id)
+		SELECT $1, $2, $3, p.post_id FROM posts p WHERE p.post_id = $4;`,
+		experimentID, variant, userID, postID)
+	return err
+}
+
+// impressions = คู่ (user, post) ที่เห็นไม่ซ้ำ, like/save นับเฉพาะที่เกิดหลังเห็นครั้งแรก
+func (r *experimentRepository) GetReport(experimentID int) ([]models.VariantReport, error) {
+	query := `
+		WITH imp AS (
+			SELECT impression_variant_key AS variant_key,
+				impression_user_id AS user_id,
+				impression_post_id AS post_id,
+				MIN(impression_created_at) AS first_seen
+			FROM experiment_impressions
+			WHERE impression_experiment_id = $1
+			GROUP BY 1, 2, 3
+		),
+		clk AS (
+			SELECT DISTINCT click_variant_key AS variant_key,
+				click_user_id AS user_id,
+				click_post_id AS post_id
+			FROM experiment_clicks
+			WHERE click_experiment_id = $1
+		)
+		SELECT v.variant_key,
+			COUNT(DISTINCT i.user_id) AS users,
+			COUNT(i.post_id) AS impressions,
+			COUNT(c.post_id) AS clicks,
+			COUNT(l.like_post_id) AS likes,
+			COUNT(sp.save_post_id) AS saves
+		FROM experiment_variants v
+		LEFT JOIN imp i ON i.variant_key = v.variant_key
+		LEFT JOIN clk c ON c.variant_key = i.variant_key
+			AND c.user_id = i.user_id AND c.post_id = i.post_id
+		LEFT JOIN likes l ON l.like_user_id = i.user_id
+			AND l.like_post_id = i.post_id AND l.like_created_at >= i.first_seen
+		LEFT JOIN saved_posts sp ON sp.save_user_id = i.user_id
+			AND sp.save_post_id = i.post_id AND sp.save_created_at >= i.first_seen
+		WHERE v.variant_experiment_id = $1
+		GROUP BY v.variant_id, v.variant_key
+		ORDER BY v.variant_id;`
+
+	rows, err := r.db.Query(query, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.VariantReport, 0, 4)
+	for rows.Next() {
+		var v models.VariantReport
+		if err := rows.Scan(&v.Variant, &v.Users, &v.Impressions, &v.Clicks, &v.Likes, &v.Saves); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
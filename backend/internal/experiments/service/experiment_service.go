@@ -0,0 +1,311 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"chaladshare_backend/internal/experiments/models"
+	"chaladshare_backend/internal/experiments/repository"
+)
+
+type ExperimentService interface {
+	// admin
+	ListExperiments() ([]models.Experiment, error)
+	CreateExperiment(req models.CreateExperimentRequest) (*models.Experiment, error)
+	StartExperiment(experimentID int) (*models.Experiment, error)
+	StopExperiment(experimentID int) (*models.Experiment, error)
+	GetReport(experimentID int) (*models.ExperimentReport, error)
+
+	// runtime (เรียกจาก handler ของ surface ต่าง ๆ)
+	Assign(userID int, surface string) *models.Assignment
+	TrackImpressions(a *models.Assignment, userID int, postIDs []int, offset int)
+	TrackRankedImpressions(a *models.Assignment, userID int, postIDs, positions []int)
+	RecordClick(userID int, experimentKey string, postID int) error
+
+	// เรียก fn(surface) หลังเริ่ม/หยุด experiment (เช่น ล้าง cache ที่จัดอันดับด้วย config เดิม)
+	OnStatusChange(fn func(surface string))
+}
+
+const (
+	maxExperimentKeyLen = 50 // ตาม experiments.experiment_key varchar(50)
+	maxVariantKeyLen    = 30 // ตาม experiment_variants.variant_key varchar(30)
+	maxVariants         = 10
+
+	// เก็บ experiment ที่กำลังรันต่อ surface ไว้ในหน่วยความจำ ลด query ทุก request
+	runningCacheTTL = 30 * time.Second
+)
+
+type runningEntry struct {
+	exp      *models.Experiment
+	configs  []map[string]any
+	loadedAt time.Time
+}
+
+type experimentService struct {
+	repo repository.ExperimentRepository
+
+	mu       sync.Mutex
+	running  map[string]runningEntry
+	onChange []func(surface string)
+}
+
+func NewExperimentService(repo repository.ExperimentRepository) ExperimentService {
+	return &experimentService{
+		repo:    repo,
+		running: make(map[string]runningEntry),
+	}
+}
+
+func (s *experimentService) OnStatusChange(fn func(surface string)) {
+	s.mu.Lock()
+	s.onChange = append(s.onChange, fn)
+	s.mu.Unlock()
+}
+
+func validSurface(s string) bool {
+	switch s {
+	case models.SurfaceRecommend, models.SurfacePopular, models.SurfaceSearch:
+		return true
+	}
+	return false
+}
+
+// a-z, 0-9, _ และ - เท่านั้น
+func normalizeKey(key string, maxLen int) (string, bool) {
+	k := strings.ToLower(strings.TrimSpace(key))
+	if k == "" || len(k) > maxLen {
+		return "", false
+	}
+	for _, r := range k {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			continue
+		}
+		return "", false
+	}
+	return k, true
+}
+
+func (s *experimentService) ListExperiments() ([]models.Experiment, error) {
+	return s.repo.ListExperiments()
+}
+
+func (s *experimentService) CreateExperiment(req models.CreateExperimentRequest) (*models.Experiment, error) {
+	key, ok := normalizeKey(req.Key, maxExperimentKeyLen)
+	if !ok {
+		return nil, fmt.Errorf("%w: experiment_key", models.ErrInvalidExperiment)
+	}
+	surface := strings.ToLower(strings.TrimSpace(req.Surface))
+	if !validSurface(surface) {
+		return nil, fmt.Errorf("%w: experiment_surface", models.ErrInvalidExperiment)
+	}
+	if len(req.Variants) < 2 || len(req.Variants) > maxVariants {
+		return nil, fmt.Errorf("%w: need 2-%d variants", models.ErrInvalidExperiment, maxVariants)
+	}
+
+	seen := make(map[string]bool, len(req.Variants))
+	variants := make([]models.VariantRequest, 0, len(req.Variants))
+	for _, v := range req.Variants {
+		vk, ok := normalizeKey(v.Key, maxVariantKeyLen)
+		if !ok || seen[vk] {
+			return nil, fmt.Errorf("%w: variant_key", models.ErrInvalidExperiment)
+		}
+		seen[vk] = true
+
+		if v.Weight < 0 {
+			return nil, fmt.Errorf("%w: variant_weight", models.ErrInvalidExperiment)
+		}
+		if v.Weight == 0 {
+			v.Weight = 1
+		}
+
+		if len(v.Config) == 0 {
+			v.Config = json.RawMessage(`{}`)
+		}
+		var obj map[string]any
+		if err := json.Unmarshal(v.Config, &obj); err != nil || obj == nil {
+			return nil, models.ErrInvalidVariantConfig
+		}
+
+		v.Key = vk
+		variants = append(variants, v)
+	}
+
+	id, err := s.repo.CreateExperiment(key, surface, variants)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetExperiment(id)
+}
+
+func (s *experimentService) StartExperiment(experimentID int) (*models.Experiment, error) {
+	return s.changeStatus(experimentID, models.StatusDraft, models.StatusRunning)
+}
+
+func (s *experimentService) StopExperiment(experimentID int) (*models.Experiment, error) {
+	return s.changeStatus(experimentID, models.StatusRunning, models.StatusStopped)
+}
+
+func (s *experimentService) changeStatus(experimentID int, from, to string) (*models.Experiment, error) {
+	exp, err := s.repo.GetExperiment(experimentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetStatus(experimentID, from, to); err != nil {
+		return nil, err
+	}
+
+	// ล้าง cache ของ surface ให้ผลมีทันที
+	s.mu.Lock()
+	delete(s.running, exp.Surface)
+	listeners := s.onChange
+	s.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(exp.Surface)
+	}
+
+	return s.repo.GetExperiment(experimentID)
+}
+
+func (s *experimentService) GetReport(experimentID int) (*models.ExperimentReport, error) {
+	exp, err := s.repo.GetExperiment(experimentID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.GetReport(experimentID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		if n := float64(rows[i].Impressions); n > 0 {
+			rows[i].CTR = float64(rows[i].Clicks) / n
+			rows[i].LikeRate = float64(rows[i].Likes) / n
+			rows[i].SaveRate = float64(rows[i].Saves) / n
+		}
+	}
+	return &models.ExperimentReport{Experiment: *exp, Variants: rows}, nil
+}
+
+func (s *experimentService) runningFor(surface string) (runningEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.running[surface]; ok && time.Since(e.loadedAt) < runningCacheTTL {
+		return e, e.exp != nil
+	}
+
+	entry := runningEntry{loadedAt: time.Now()}
+	exp, err := s.repo.GetRunning(surface)
+	switch {
+	case err == nil:
+		entry.exp = exp
+		entry.configs = make([]map[string]any, len(exp.Variants))
+		for i, v := range exp.Variants {
+			if err := json.Unmarshal(v.Config, &entry.configs[i]); err != nil {
+				log.Printf("experiment %s variant %s: bad config: %v", exp.Key, v.Key, err)
+			}
+		}
+	case errors.Is(err, models.ErrExperimentNotFound):
+	default:
+		// DB มีปัญหา: ไม่เข้าร่วม experiment และไม่ cache ผลนี้
+		log.Printf("load running experiment (%s): %v", surface, err)
+		return entry, false
+	}
+	s.running[surface] = entry
+	return entry, entry.exp != nil
+}
+
+// เลือก variant แบบ deterministic: hash(experiment key, user) แล้วแบ่งตาม weight
+// ผู้ใช้คนเดิมจะได้ variant เดิมตลอดอายุ experiment
+func bucket(exp *models.Experiment, userID int) int {
+	total := 0
+	for _, v := range exp.Variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return -1
+	}
+
+	// ใช้บิตสูงของ hash (multiply-shift) แทน mod: บิตต่ำของ FNV-1a ขึ้นกับ parity ของ input
+	// mod ด้วยจำนวนคู่จะทำให้ผู้ใช้ถูกแบ่งกลุ่มสัมพันธ์กันข้าม experiment
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s:%d", exp.Key, userID)
+	point := int(uint64(h.Sum32()) * uint64(total) >> 32)
+	for i, v := range exp.Variants {
+		if point < v.Weight {
+			return i
+		}
+		point -= v.Weight
+	}
+	return len(exp.Variants) - 1
+}
+
+// คืน nil ถ้า surface นี้ไม่มี experiment ที่กำลังรัน
+func (s *experimentService) Assign(userID int, surface string) *models.Assignment {
+	if userID <= 0 {
+		return nil
+	}
+	entry, ok := s.runningFor(surface)
+	if !ok {
+		return nil
+	}
+	i := bucket(entry.exp, userID)
+	if i < 0 {
+		return nil
+	}
+	return &models.Assignment{
+		ExperimentID:  entry.exp.ExperimentID,
+		ExperimentKey: entry.exp.Key,
+		Variant:       entry.exp.Variants[i].Key,
+		Config:        entry.configs[i],
+	}
+}
+
+// บันทึก impression แบบ async ไม่ให้ response ช้า (ผิดพลาดแค่ log)
+// รายการต่อเนื่องกัน: ตำแหน่ง = offset + ลำดับในหน้า
+func (s *experimentService) TrackImpressions(a *models.Assignment, userID int, postIDs []int, offset int) {
+	positions := make([]int, len(postIDs))
+	for i := range postIDs {
+		positions[i] = offset + i + 1
+	}
+	s.TrackRankedImpressions(a, userID, postIDs, positions)
+}
+
+// ตำแหน่งของแต่ละรายการระบุเอง (เช่น rank ใน cache ที่มีบางรายการถูกกรองออก)
+func (s *experimentService) TrackRankedImpressions(a *models.Assignment, userID int, postIDs, positions []int) {
+	if a == nil || userID <= 0 || len(postIDs) == 0 || len(postIDs) != len(positions) {
+		return
+	}
+	ids := append([]int(nil), postIDs...)
+	pos := append([]int(nil), positions...)
+	go func() {
+		if err := s.repo.LogImpressions(a.ExperimentID, a.Variant, userID, ids, pos); err != nil {
+			log.Printf("log impressions (experiment %s): %v", a.ExperimentKey, err)
+		}
+	}()
+}
+
+// variant คำนวณใหม่ฝั่ง server ไม่เชื่อค่าจาก client
+func (s *experimentService) RecordClick(userID int, experimentKey string, postID int) error {
+	if userID <= 0 || postID <= 0 {
+		return models.ErrInvalidExperiment
+	}
+	exp, err := s.repo.GetRunningByKey(strings.ToLower(strings.TrimSpace(experimentKey)))
+	if err != nil {
+		if errors.Is(err, models.ErrExperimentNotFound) {
+			return models.ErrNotInExperiment
+		}
+		return err
+	}
+	i := bucket(exp, userID)
+	if i < 0 {
+		return models.ErrNotInExperiment
+	}
+	return s.repo.LogClick(exp.ExperimentID, exp.Variants[i].Key, userID, postID)
+}
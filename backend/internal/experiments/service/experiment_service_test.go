@@ -0,0 +1,93 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"chaladshare_backend/internal/experiments/models"
+)
+
+func newExp(key string, weights ...int) *models.Experiment {
+	exp := &models.Experiment{Key: key}
+	for i, w := range weights {
+		exp.Variants = append(exp.Variants, models.Variant{Key: string(rune('a' + i)), Weight: w})
+	}
+	return exp
+}
+
+func TestBucketDeterministic(t *testing.T) {
+	exp := newExp("feed-sort", 1, 1, 1)
+	for uid := 1; uid <= 500; uid++ {
+		first := bucket(exp, uid)
+		if first < 0 || first >= len(exp.Variants) {
+			t.Fatalf("bucket(%d) = %d, want 0..%d", uid, first, len(exp.Variants)-1)
+		}
+		for i := 0; i < 3; i++ {
+			if got := bucket(exp, uid); got != first {
+				t.Fatalf("bucket(%d) = %d then %d", uid, first, got)
+			}
+		}
+		// ขึ้นกับ key + user เท่านั้น ไม่ขึ้นกับ object
+		if got := bucket(newExp("feed-sort", 1, 1, 1), uid); got != first {
+			t.Fatalf("bucket(%d) on copy = %d, want %d", uid, got, first)
+		}
+	}
+}
+
+// key ต่างกันต้องแบ่งผู้ใช้ต่างกัน (ไม่ใช่ทุก experiment ได้กลุ่มเดียวกันหมด)
+func TestBucketDependsOnKey(t *testing.T) {
+	a, b := newExp("exp-a", 1, 1), newExp("exp-b", 1, 1)
+	differ := 0
+	for uid := 1; uid <= 1000; uid++ {
+		if bucket(a, uid) != bucket(b, uid) {
+			differ++
+		}
+	}
+	if differ < 300 || differ > 700 {
+		t.Fatalf("%d/1000 users changed variant between keys, want about half", differ)
+	}
+}
+
+func TestBucketWeightSplit(t *testing.T) {
+	const users = 20000
+	tests := []struct {
+		name    string
+		weights []int
+	}{
+		{"even", []int{50, 50}},
+		{"skewed", []int{10, 90}},
+		{"three way", []int{1, 2, 1}},
+		{"zero weight arm", []int{0, 3, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := newExp("split-"+tt.name, tt.weights...)
+			total := 0
+			for _, w := range tt.weights {
+				total += w
+			}
+			counts := make([]int, len(tt.weights))
+			for uid := 1; uid <= users; uid++ {
+				counts[bucket(exp, uid)]++
+			}
+			for i, w := range tt.weights {
+				want := float64(w) / float64(total)
+				got := float64(counts[i]) / users
+				if w == 0 && counts[i] != 0 {
+					t.Fatalf("variant %d has weight 0 but got %d users", i, counts[i])
+				}
+				if math.Abs(got-want) > 0.02 {
+					t.Errorf("variant %d share = %.3f, want %.3f±0.02", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBucketNoWeight(t *testing.T) {
+	for _, exp := range []*models.Experiment{newExp("none"), newExp("zeros", 0, 0)} {
+		if got := bucket(exp, 1); got != -1 {
+			t.Fatalf("bucket(%q) = %d, want -1", exp.Key, got)
+		}
+	}
+}
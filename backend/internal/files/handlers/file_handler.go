@@ -183,13 +183,20 @@ func (h *FileHandler) UploadFile(c *gin.Context) {
 		return
 	}
 
+	// PDF หรือรูปภาพ (.jpg .jpeg .png) สำหรับแนบกับโพสต์
+	kind, ext := models.KindPDF, ".pdf"
+	switch e := strings.ToLower(filepath.Ext(fh.Filename)); e {
+	case ".jpg", ".jpeg", ".png":
+		kind, ext = models.KindImage, e
+	}
+
 	if err := os.MkdirAll("./uploads", 0755); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้างโฟลเดอร์ uploads ได้"})
 		return
 	}
 
 	id := uuid.New().String()
-	filename := id + ".pdf"
+	filename := id + ext
 	abs := filepath.Join("./uploads", filename)
 	publicURL := "/uploads/" + filename
 
@@ -203,6 +210,7 @@ func (h *FileHandler) UploadFile(c *gin.Context) {
 		DocumentName:    fh.Filename,
 		DocumentURL:     publicURL,
 		StorageProvider: "local",
+		Kind:            kind,
 		LocalPath:       abs,
 	}
 	resp, err := h.fileservice.UploadFile(req)
@@ -212,8 +220,9 @@ func (h *FileHandler) UploadFile(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"document_id": resp.DocumentID,
-		"pdf_url":     publicURL,
+		"document_id":   resp.DocumentID,
+		"document_kind": kind,
+		"pdf_url":       publicURL,
 	})
 }
 
@@ -392,3 +401,54 @@ func (h *FileHandler) DeleteFile(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "ลบไฟล์สำเร็จ"})
 }
+
+// GET /api/v1/files/trash (ไฟล์ในถังขยะของตัวเอง)
+func (h *FileHandler) GetTrash(c *gin.Context) {
+	authUID := c.GetInt(middleware.CtxUserID)
+	if authUID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	files, err := h.fileservice.GetTrashByUserID(authUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, files)
+}
+
+// POST /api/v1/files/:document_id/restore
+func (h *FileHandler) RestoreFile(c *gin.Context) {
+	authUID := c.GetInt(middleware.CtxUserID)
+	if authUID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	docID, err := strconv.Atoi(c.Param("document_id"))
+	if err != nil || docID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid document_id"})
+		return
+	}
+
+	ok, err := h.fileservice.IsOwner(docID, authUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	if err := h.fileservice.RestoreFile(docID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบไฟล์นี้ในถังขยะ"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "กู้คืนไฟล์สำเร็จ"})
+}
@@ -2,14 +2,26 @@ package models
 
 import "time"
 
+// ชนิดไฟล์ที่แนบกับโพสต์ได้ (สกัด feature / สรุปเฉพาะ pdf)
+const (
+	KindPDF   = "pdf"
+	KindImage = "image"
+)
+
+// ไฟล์ที่ลบจะอยู่ในถังขยะกี่วันก่อนถูกลบจริง (รวมไฟล์ใน storage)
+const TrashRetentionDays = 30
+
 // ข้อมูลไฟล์ที่อัปโหลด
 type Document struct {
-	DocumentID      int       `json:"document_id"`
-	DocumentUserID  int       `json:"document_user_id"`
-	DocumentName    string    `json:"document_name"`
-	DocumentURL     string    `json:"document_url"`
-	StorageProvider string    `json:"storage_provider"`
-	UploadedAt      time.Time `json:"uploaded_at"`
+	DocumentID      int        `json:"document_id"`
+	DocumentUserID  int        `json:"document_user_id"`
+	DocumentName    string     `json:"document_name"`
+	DocumentURL     string     `json:"document_url"`
+	StorageProvider string     `json:"storage_provider"`
+	Kind            string     `json:"document_kind"`
+	UploadedAt      time.Time  `json:"uploaded_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	PurgeAt         *time.Time `json:"purge_at,omitempty"`
 }
 
 // เก็บข้อมูลจากไฟล์ที่สรุปเนื้อหาด้วย AI
@@ -27,6 +39,7 @@ type UploadRequest struct {
 	DocumentName    string `json:"document_name"`
 	DocumentURL     string `json:"document_url"`
 	StorageProvider string `json:"storage_provider"`
+	Kind            string `json:"document_kind"`
 	LocalPath       string `json:"-"`
 }
 
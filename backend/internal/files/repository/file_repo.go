@@ -13,6 +13,10 @@ type FileRepository interface {
 	CreateDocument(doc *models.Document) (*models.Document, error)
 	GetListDocByUserID(userID int) ([]models.Document, error)
 	DeleteDocument(id int) error
+	RestoreDocument(id int) error
+	GetTrashedDocByUserID(userID int) ([]models.Document, error)
+	ListPurgeableDocuments() ([]models.Document, error)
+	PurgeDocument(id int) error
 
 	GetDocumentOwnerID(documentID int) (int, error)
 	GetDocumentByID(documentID int) (*models.Document, error)
@@ -34,11 +38,11 @@ func NewFileRepository(db *sql.DB) FileRepository {
 // CreateDocument
 func (r *fileRepository) CreateDocument(req *models.Document) (*models.Document, error) {
 	err := r.db.QueryRow(`
-		INSERT INTO documents (document_user_id, document_name, document_url, storage_provider, uploaded_at)
-		VALUES ($1,$2,$3,$4,$5)
+		INSERT INTO documents (document_user_id, document_name, document_url, storage_provider, document_kind, uploaded_at)
+		VALUES ($1,$2,$3,$4,$5,$6)
 		RETURNING document_id, uploaded_at
 	`,
-		req.DocumentUserID, req.DocumentName, req.DocumentURL, req.StorageProvider, time.Now(),
+		req.DocumentUserID, req.DocumentName, req.DocumentURL, req.StorageProvider, req.Kind, time.Now(),
 	).Scan(&req.DocumentID, &req.UploadedAt)
 
 	if err != nil {
@@ -50,9 +54,9 @@ func (r *fileRepository) CreateDocument(req *models.Document) (*models.Document,
 // etListDocByUserID latest
 func (r *fileRepository) GetListDocByUserID(userID int) ([]models.Document, error) {
 	rows, err := r.db.Query(`
-		SELECT document_id, document_user_id, document_name, document_url, storage_provider, uploaded_at
+		SELECT document_id, document_user_id, document_name, document_url, storage_provider, document_kind, uploaded_at
 		FROM documents
-		WHERE document_user_id = $1
+		WHERE document_user_id = $1 AND document_deleted_at IS NULL
 		ORDER BY uploaded_at DESC
 	`, userID)
 	if err != nil {
@@ -63,7 +67,7 @@ func (r *fileRepository) GetListDocByUserID(userID int) ([]models.Document, erro
 	var docs []models.Document
 	for rows.Next() {
 		var d models.Document
-		if err := rows.Scan(&d.DocumentID, &d.DocumentUserID, &d.DocumentName, &d.DocumentURL, &d.StorageProvider, &d.UploadedAt); err != nil {
+		if err := rows.Scan(&d.DocumentID, &d.DocumentUserID, &d.DocumentName, &d.DocumentURL, &d.StorageProvider, &d.Kind, &d.UploadedAt); err != nil {
 			return nil, err
 		}
 		docs = append(docs, d)
@@ -99,9 +103,32 @@ func (r *fileRepository) GetSummaryByDocID(docID int) (*models.Summary, error) {
 	return &s, err
 }
 
-// DeleteDocument
+// เลือกเอกสารหลักของโพสต์ที่แนบเอกสาร $1 ใหม่จากไฟล์แนบที่ยังไม่อยู่ในถังขยะ
+// (PDF แรกตามลำดับ ถ้าไม่มี PDF ใช้ไฟล์แรก, ไม่เหลือเลย = NULL)
+const qRefreshPrimaryDocument = `
+	UPDATE posts p SET post_document_id = (
+		SELECT pd.attachment_document_id
+		FROM post_documents pd
+		JOIN documents d ON d.document_id = pd.attachment_document_id
+		WHERE pd.attachment_post_id = p.post_id AND d.document_deleted_at IS NULL
+		ORDER BY (d.document_kind = 'pdf') DESC, pd.attachment_position
+		LIMIT 1
+	)
+	WHERE p.post_id IN (
+		SELECT attachment_post_id FROM post_documents WHERE attachment_document_id = $1
+	);`
+
+// DeleteDocument ย้ายเอกสารลงถังขยะ โพสต์ที่แนบไว้เปลี่ยนไปใช้ไฟล์แนบอื่นเป็นเอกสารหลัก
 func (r *fileRepository) DeleteDocument(id int) error {
-	res, err := r.db.Exec("DELETE FROM documents WHERE document_id = $1", id)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE documents SET document_deleted_at = now()
+		WHERE document_id = $1 AND document_deleted_at IS NULL`, id)
 	if err != nil {
 		return err
 	}
@@ -109,7 +136,91 @@ func (r *fileRepository) DeleteDocument(id int) error {
 	if rowsAffected == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	if _, err := tx.Exec(qRefreshPrimaryDocument, id); err != nil {
+		return fmt.Errorf("refresh primary document: %w", err)
+	}
+	return tx.Commit()
+}
+
+// RestoreDocument กู้คืนจากถังขยะ (ก่อนครบกำหนด purge)
+func (r *fileRepository) RestoreDocument(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE documents SET document_deleted_at = NULL
+		WHERE document_id = $1 AND document_deleted_at > now() - make_interval(days => $2)`,
+		id, models.TrashRetentionDays)
+	if err != nil {
+		return err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.Exec(qRefreshPrimaryDocument, id); err != nil {
+		return fmt.Errorf("refresh primary document: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetTrashedDocByUserID ลบล่าสุดก่อน
+func (r *fileRepository) GetTrashedDocByUserID(userID int) ([]models.Document, error) {
+	rows, err := r.db.Query(`
+		SELECT document_id, document_user_id, document_name, document_url, storage_provider, document_kind, uploaded_at,
+			document_deleted_at, document_deleted_at + make_interval(days => $2)
+		FROM documents
+		WHERE document_user_id = $1 AND document_deleted_at > now() - make_interval(days => $2)
+		ORDER BY document_deleted_at DESC
+	`, userID, models.TrashRetentionDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := make([]models.Document, 0)
+	for rows.Next() {
+		var d models.Document
+		if err := rows.Scan(&d.DocumentID, &d.DocumentUserID, &d.DocumentName, &d.DocumentURL, &d.StorageProvider, &d.Kind, &d.UploadedAt,
+			&d.DeletedAt, &d.PurgeAt); err != nil {
+			return nil, err
+		}
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
+
+// ListPurgeableDocuments เอกสารที่อยู่ในถังขยะครบกำหนดแล้ว
+func (r *fileRepository) ListPurgeableDocuments() ([]models.Document, error) {
+	rows, err := r.db.Query(`
+		SELECT document_id, document_user_id, document_name, document_url, storage_provider, document_kind, uploaded_at, document_deleted_at
+		FROM documents
+		WHERE document_deleted_at <= now() - make_interval(days => $1)
+		ORDER BY document_deleted_at
+	`, models.TrashRetentionDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []models.Document
+	for rows.Next() {
+		var d models.Document
+		if err := rows.Scan(&d.DocumentID, &d.DocumentUserID, &d.DocumentName, &d.DocumentURL, &d.StorageProvider, &d.Kind, &d.UploadedAt, &d.DeletedAt); err != nil {
+			return nil, err
+		}
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
+
+// PurgeDocument ลบแถวจริง (เฉพาะเอกสารที่อยู่ในถังขยะ) ตารางลูก cascade ตาม FK
+func (r *fileRepository) PurgeDocument(id int) error {
+	_, err := r.db.Exec(`DELETE FROM documents WHERE document_id = $1 AND document_deleted_at IS NOT NULL`, id)
+	return err
 }
 
 func (r *fileRepository) GetDocumentOwnerID(documentID int) (int, error) {
@@ -128,9 +239,9 @@ func (r *fileRepository) GetDocumentOwnerID(documentID int) (int, error) {
 func (r *fileRepository) GetDocumentByID(id int) (*models.Document, error) {
 	var d models.Document
 	err := r.db.QueryRow(
-		`SELECT document_id, document_user_id, document_name, document_url, storage_provider, uploaded_at
+		`SELECT document_id, document_user_id, document_name, document_url, storage_provider, document_kind, uploaded_at
 		FROM documents
-		WHERE document_id = $1`, id).Scan(&d.DocumentID, &d.DocumentUserID, &d.DocumentName, &d.DocumentURL, &d.StorageProvider, &d.UploadedAt)
+		WHERE document_id = $1`, id).Scan(&d.DocumentID, &d.DocumentUserID, &d.DocumentName, &d.DocumentURL, &d.StorageProvider, &d.Kind, &d.UploadedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -20,6 +20,9 @@ type FileService interface {
 	UploadFile(req *models.UploadRequest) (*models.UploadResponse, error)
 	GetFilesByUserID(userID int) ([]models.Document, error)
 	DeleteFile(documentID int) error
+	RestoreFile(documentID int) error
+	GetTrashByUserID(userID int) ([]models.Document, error)
+	PurgeTrash() (int, error)
 
 	GetDocumentOwnerID(documentID int) (int, error)
 
@@ -77,11 +80,17 @@ func (s *fileService) UploadFile(req *models.UploadRequest) (*models.UploadRespo
 		req.DocumentURL = publicURL
 	}
 
+	kind := req.Kind
+	if kind != models.KindImage {
+		kind = models.KindPDF
+	}
+
 	doc := &models.Document{
 		DocumentUserID:  req.UserID,
 		DocumentName:    req.DocumentName,
 		DocumentURL:     req.DocumentURL,
 		StorageProvider: provider,
+		Kind:            kind,
 	}
 
 	savedDoc, err := s.filerepo.CreateDocument(doc)
@@ -89,6 +98,19 @@ func (s *fileService) UploadFile(req *models.UploadRequest) (*models.UploadRespo
 		return nil, fmt.Errorf("บันทึกไฟล์ไม่สำเร็จ: %v", err)
 	}
 
+	// รูปภาพไม่ต้องสกัด feature
+	if kind == models.KindImage {
+		if provider == "supabase" {
+			_ = os.Remove(req.LocalPath)
+		}
+		return &models.UploadResponse{
+			Message:    "อัปโหลดไฟล์สำเร็จ",
+			File:       *savedDoc,
+			FileURL:    savedDoc.DocumentURL,
+			DocumentID: savedDoc.DocumentID,
+		}, nil
+	}
+
 	if err := s.featureSvc.CreateQueued(savedDoc.DocumentID); err != nil {
 		return nil, fmt.Errorf("สร้าง document_features ไม่สำเร็จ: %v", err)
 	}
@@ -123,16 +145,62 @@ func (s *fileService) GetFilesByUserID(userID int) ([]models.Document, error) {
 	return files, nil
 }
 
+// DeleteFile ย้ายลงถังขยะ ไฟล์ใน storage ถูกลบตอน PurgeTrash
 func (s *fileService) DeleteFile(documentID int) error {
 	if documentID <= 0 {
 		return errors.New("document_id ไม่ถูกต้อง")
 	}
+	if err := s.filerepo.DeleteDocument(documentID); err != nil {
+		return fmt.Errorf("ไม่สามารถลบไฟล์ได้: %w", err)
+	}
+	return nil
+}
+
+func (s *fileService) RestoreFile(documentID int) error {
+	if documentID <= 0 {
+		return errors.New("document_id ไม่ถูกต้อง")
+	}
+	if err := s.filerepo.RestoreDocument(documentID); err != nil {
+		return fmt.Errorf("ไม่สามารถกู้คืนไฟล์ได้: %w", err)
+	}
+	return nil
+}
 
-	doc, err := s.filerepo.GetDocumentByID(documentID)
+func (s *fileService) GetTrashByUserID(userID int) ([]models.Document, error) {
+	files, err := s.filerepo.GetTrashedDocByUserID(userID)
 	if err != nil {
-		return fmt.Errorf("ไม่พบเอกสาร: %v", err)
+		return nil, fmt.Errorf("ไม่สามารถดึงข้อมูลไฟล์ได้: %v", err)
+	}
+	return files, nil
+}
+
+// PurgeTrash ลบไฟล์ที่อยู่ในถังขยะครบกำหนดทั้งใน storage และ DB
+// ลบใน storage ไม่สำเร็จ = เก็บแถวไว้ ลองใหม่รอบถัดไป
+func (s *fileService) PurgeTrash() (int, error) {
+	docs, err := s.filerepo.ListPurgeableDocuments()
+	if err != nil {
+		return 0, err
 	}
 
+	purged := 0
+	var errs []error
+	for i := range docs {
+		doc := &docs[i]
+		if err := removeStoredObject(doc); err != nil {
+			errs = append(errs, fmt.Errorf("document %d: %w", doc.DocumentID, err))
+			continue
+		}
+		_ = s.filerepo.DeleteSummariesByDocID(doc.DocumentID)
+		if err := s.filerepo.PurgeDocument(doc.DocumentID); err != nil {
+			errs = append(errs, fmt.Errorf("document %d: %w", doc.DocumentID, err))
+			continue
+		}
+		purged++
+	}
+	return purged, errors.Join(errs...)
+}
+
+func removeStoredObject(doc *models.Document) error {
 	// local delete
 	if strings.EqualFold(doc.StorageProvider, "local") && strings.TrimSpace(doc.DocumentURL) != "" {
 		p := filepath.Clean("." + doc.DocumentURL)
@@ -155,12 +223,6 @@ func (s *fileService) DeleteFile(documentID int) error {
 			return fmt.Errorf("ลบไฟล์ใน Supabase ไม่สำเร็จ: %v", err)
 		}
 	}
-
-	_ = s.filerepo.DeleteSummariesByDocID(documentID)
-
-	if err := s.filerepo.DeleteDocument(documentID); err != nil {
-		return fmt.Errorf("ไม่สามารถลบไฟล์ได้: %v", err)
-	}
 	return nil
 }
 
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RoleAdmin = "admin"
+
+// ต้องใช้หลัง JWT() เพราะอ่าน user_id จาก context
+func RequireAdmin(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uid := c.GetInt(CtxUserID)
+		if uid <= 0 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		var role string
+		err := db.QueryRowContext(c.Request.Context(),
+			`SELECT user_role FROM users WHERE user_id = $1`, uid).Scan(&role)
+		if err != nil || role != RoleAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+		c.Next()
+	}
+}
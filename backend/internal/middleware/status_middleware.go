@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const StatusSuspended = "suspended"
+
+// บัญชีที่ถูกระงับใช้ token เดิมต่อไม่ได้ (JWT ไม่มี state จึงต้องเช็คจาก users)
+// ต้องใช้หลัง JWT() เพราะอ่าน user_id จาก context
+func RequireActive(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uid := c.GetInt(CtxUserID)
+		if uid <= 0 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		var status string
+		err := db.QueryRowContext(c.Request.Context(),
+			`SELECT user_status FROM users WHERE user_id = $1`, uid).Scan(&status)
+		if err == sql.ErrNoRows {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if status == StatusSuspended {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "account suspended"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"chaladshare_backend/internal/middleware"
+	"chaladshare_backend/internal/notifications/models"
+	"chaladshare_backend/internal/notifications/service"
+	"chaladshare_backend/internal/pagination"
+)
+
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrNotificationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, pagination.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func pageQuery(c *gin.Context) (string, int) {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+	return c.Query("cursor"), pagination.ClampLimit(limit)
+}
+
+// GET /api/v1/notifications?unread=true&cursor=&limit=
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	unreadOnly := c.Query("unread") == "true"
+	cursor, limit := pageQuery(c)
+	page, err := h.notificationService.ListNotifications(uid, unreadOnly, cursor, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": page.Items, "unread_count": page.UnreadCount, "next_cursor": page.NextCursor})
+}
+
+// POST /api/v1/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := h.notificationService.MarkRead(uid, id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "marked as read"})
+}
+
+// POST /api/v1/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	n, err := h.notificationService.MarkAllRead(uid)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"updated": n})
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// ประเภทการแจ้งเตือน
+const (
+	TypeReportResolved = "report_resolved" // ผลการพิจารณารายงานถึงผู้รายงาน
+)
+
+type Notification struct {
+	NotificationID int64           `json:"notification_id"`
+	Type           string          `json:"type"`
+	Message        string          `json:"message"`
+	Data           json.RawMessage `json:"data"`
+	ReadAt         *time.Time      `json:"read_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type NotificationPage struct {
+	Items       []Notification `json:"items"`
+	UnreadCount int            `json:"unread_count"`
+	NextCursor  string         `json:"next_cursor"`
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"chaladshare_backend/internal/notifications/models"
+	"chaladshare_backend/internal/pagination"
+)
+
+type NotificationRepository interface {
+	ListNotifications(userID int, after *pagination.Keyset, unreadOnly bool, limit int) ([]models.Notification, error)
+	CountUnread(userID int) (int, error)
+	MarkRead(userID int, notificationID int64) error
+	MarkAllRead(userID int) (int, error)
+}
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// keyset ใหม่→เก่า บน (notification_created_at, notification_id)
+func (r *notificationRepository) ListNotifications(userID int, after *pagination.Keyset, unreadOnly bool, limit int) ([]models.Notification, error) {
+	var (
+		afterAt any
+		afterID any
+	)
+	if after != nil {
+		afterAt, afterID = after.CreatedAt, after.ID
+	}
+	rows, err := r.db.Query(`
+		SELECT notification_id, notification_type, notification_message, notification_data,
+			notification_read_at, notification_created_at
+		FROM notifications
+		WHERE notification_user_id = $1
+		  AND (NOT $2 OR notification_read_at IS NULL)
+		  AND ($3::timestamptz IS NULL OR (notification_created_at, notification_id) < ($3::timestamptz, $4::bigint))
+		ORDER BY notification_created_at DESC, notification_id DESC
+		LIMIT $5;`, userID, unreadOnly, afterAt, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.Notification{}
+	for rows.Next() {
+		var (
+			n    models.Notification
+			data []byte
+		)
+		if err := rows.Scan(&n.NotificationID, &n.Type, &n.Message, &data, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		n.Data = json.RawMessage(data)
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+func (r *notificationRepository) CountUnread(userID int) (int, error) {
+	var n int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM notifications
+		WHERE notification_user_id = $1 AND notification_read_at IS NULL;`, userID).Scan(&n)
+	return n, err
+}
+
+// อ่านซ้ำได้ (ไม่เปลี่ยนเวลาที่อ่านครั้งแรก)
+func (r *notificationRepository) MarkRead(userID int, notificationID int64) error {
+	res, err := r.db.Exec(`
+		UPDATE notifications
+		SET notification_read_at = COALESCE(notification_read_at, now())
+		WHERE notification_id = $1 AND notification_user_id = $2;`, notificationID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepository) MarkAllRead(userID int) (int, error) {
+	res, err := r.db.Exec(`
+		UPDATE notifications SET notification_read_at = now()
+		WHERE notification_user_id = $1 AND notification_read_at IS NULL;`, userID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
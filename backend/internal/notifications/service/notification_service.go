@@ -0,0 +1,64 @@
+package service
+
+import (
+	"chaladshare_backend/internal/notifications/models"
+	"chaladshare_backend/internal/notifications/repository"
+	"chaladshare_backend/internal/pagination"
+)
+
+type NotificationService interface {
+	ListNotifications(userID int, unreadOnly bool, cursor string, limit int) (*models.NotificationPage, error)
+	MarkRead(userID int, notificationID int64) error
+	MarkAllRead(userID int) (int, error)
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	cursors          *pagination.Codec
+}
+
+func NewNotificationService(notificationRepo repository.NotificationRepository, cursors *pagination.Codec) NotificationService {
+	return &notificationService{notificationRepo: notificationRepo, cursors: cursors}
+}
+
+func (s *notificationService) ListNotifications(userID int, unreadOnly bool, cursor string, limit int) (*models.NotificationPage, error) {
+	var after *pagination.Keyset
+	var k pagination.Keyset
+	ok, err := s.cursors.Decode(cursor, &k)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		after = &k
+	}
+
+	limit = pagination.ClampLimit(limit)
+	items, err := s.notificationRepo.ListNotifications(userID, after, unreadOnly, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.NotificationPage{Items: items, UnreadCount: unread}
+	if len(items) <= limit {
+		return page, nil
+	}
+	page.Items = items[:limit]
+	last := page.Items[limit-1]
+	page.NextCursor, err = s.cursors.Encode(pagination.Keyset{CreatedAt: last.CreatedAt, ID: int(last.NotificationID)})
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (s *notificationService) MarkRead(userID int, notificationID int64) error {
+	return s.notificationRepo.MarkRead(userID, notificationID)
+}
+
+func (s *notificationService) MarkAllRead(userID int) (int, error) {
+	return s.notificationRepo.MarkAllRead(userID)
+}
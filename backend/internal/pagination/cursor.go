@@ -0,0 +1,82 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ค่าเริ่มต้น/สูงสุดของจำนวนรายการต่อหน้า
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ตำแหน่งหลังรายการสุดท้ายของหน้าก่อน (keyset บน created_at, id)
+// Offset = จำนวนรายการที่ส่งไปแล้ว ใช้แค่บันทึกตำแหน่ง ไม่ได้ใช้ query
+type Keyset struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int       `json:"id"`
+	Offset    int       `json:"o,omitempty"`
+}
+
+// เข้ารหัส/ถอด cursor แบบ opaque: base64(JSON) + "." + base64(HMAC-SHA256)
+// client แก้ค่าใน cursor ไม่ได้ (ลายเซ็นจะไม่ตรง)
+type Codec struct {
+	secret []byte
+}
+
+func NewCodec(secret string) *Codec {
+	return &Codec{secret: []byte(secret)}
+}
+
+func (c *Codec) sign(payload string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func (c *Codec) Encode(v any) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	return payload + "." + c.sign(payload), nil
+}
+
+// cursor ว่าง = หน้าแรก (คืน false)
+func (c *Codec) Decode(cursor string, v any) (bool, error) {
+	if cursor == "" {
+		return false, nil
+	}
+	payload, sig, ok := strings.Cut(cursor, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(c.sign(payload))) {
+		return false, ErrInvalidCursor
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return false, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, ErrInvalidCursor
+	}
+	return true, nil
+}
+
+// ปรับ limit ให้อยู่ในช่วง 1..MaxLimit
+func ClampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+	return limit
+}
@@ -0,0 +1,116 @@
+package pagination
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	c := NewCodec("secret")
+	in := Keyset{CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC), ID: 42, Offset: 20}
+
+	cursor, err := c.Encode(in)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	var out Keyset
+	ok, err := c.Decode(cursor, &out)
+	if err != nil || !ok {
+		t.Fatalf("decode = %v, %v", ok, err)
+	}
+	if !out.CreatedAt.Equal(in.CreatedAt) || out.ID != in.ID || out.Offset != in.Offset {
+		t.Fatalf("decode = %+v, want %+v", out, in)
+	}
+}
+
+func TestCodecEmptyCursor(t *testing.T) {
+	var out Keyset
+	ok, err := NewCodec("secret").Decode("", &out)
+	if ok || err != nil {
+		t.Fatalf("decode empty = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func TestCodecRejectsTampering(t *testing.T) {
+	c := NewCodec("secret")
+	cursor, err := c.Encode(Keyset{ID: 42})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	payload, sig, _ := strings.Cut(cursor, ".")
+
+	// payload อื่นที่ยังเป็น JSON ถูกต้อง แต่ใช้ลายเซ็นเดิม
+	forged, err := c.Encode(Keyset{ID: 1})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	other, err := NewCodec("other").Encode(Keyset{ID: 42})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"swapped payload", forgedPayload + "." + sig},
+		{"flipped signature", payload + "." + flip(sig)},
+		{"missing signature", payload},
+		{"empty signature", payload + "."},
+		{"other secret", other},
+		{"garbage", "not-a-cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out Keyset
+			ok, err := c.Decode(tt.cursor, &out)
+			if ok || !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("decode = %v, %v; want ErrInvalidCursor", ok, err)
+			}
+		})
+	}
+}
+
+// ลายเซ็นถูกต้องแต่ payload ไม่ใช่ base64/JSON ก็ต้องถูกปฏิเสธ
+func TestCodecRejectsBadPayload(t *testing.T) {
+	c := NewCodec("secret")
+	for _, payload := range []string{"!!!", "bm90LWpzb24"} {
+		var out Keyset
+		ok, err := c.Decode(payload+"."+c.sign(payload), &out)
+		if ok || !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("decode %q = %v, %v; want ErrInvalidCursor", payload, ok, err)
+		}
+	}
+}
+
+func flip(s string) string {
+	b := []byte(s)
+	if b[0] == 'A' {
+		b[0] = 'B'
+	} else {
+		b[0] = 'A'
+	}
+	return string(b)
+}
+
+func TestClampLimit(t *testing.T) {
+	tests := []struct {
+		in, want int
+	}{
+		{-5, DefaultLimit},
+		{0, DefaultLimit},
+		{1, 1},
+		{50, 50},
+		{MaxLimit, MaxLimit},
+		{MaxLimit + 1, MaxLimit},
+	}
+	for _, tt := range tests {
+		if got := ClampLimit(tt.in); got != tt.want {
+			t.Errorf("ClampLimit(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
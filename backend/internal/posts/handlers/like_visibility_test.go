@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+
+	"chaladshare_backend/internal/posts/repository"
+	"chaladshare_backend/internal/posts/service"
+)
+
+// ต้องมีฐานข้อมูลที่สร้างจาก database/docker/init.sql (TEST_DATABASE_URL) ไม่งั้นข้าม
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatalf("ping db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func createUser(t *testing.T, db *sql.DB, name string) int {
+	t.Helper()
+	name = fmt.Sprintf("%s_%d", name, time.Now().UnixNano())
+	var id int
+	if err := db.QueryRow(`
+		INSERT INTO users (email, username, password_hash)
+		VALUES ($1, $2, 'x') RETURNING user_id;`, name+"@test.local", name).Scan(&id); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE user_id = $1`, id) })
+	return id
+}
+
+func createPost(t *testing.T, db *sql.DB, authorID int, visibility, status string) int {
+	t.Helper()
+	var id int
+	if err := db.QueryRow(`
+		INSERT INTO posts (post_author_user_id, post_title, post_visibility, post_status)
+		VALUES ($1, 'test', $2, $3) RETURNING post_id;`, authorID, visibility, status).Scan(&id); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	return id
+}
+
+func likeRouter(db *sql.DB, viewerID int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewPostHandler(nil, service.NewLikeService(repository.NewLikeRepository(db)), nil, nil, nil, nil, nil)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", viewerID) })
+	r.POST("/posts/:id/like", h.ToggleLike)
+	r.PUT("/posts/:id/like", h.LikePost)
+	return r
+}
+
+func assertLikeNotFound(t *testing.T, db *sql.DB, viewerID, postID int) {
+	t.Helper()
+	r := likeRouter(db, viewerID)
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, fmt.Sprintf("/posts/%d/like", postID), nil))
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("%s like = %d %s, want 404", method, w.Code, w.Body.String())
+		}
+	}
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM likes WHERE like_post_id = $1`, postID).Scan(&n); err != nil {
+		t.Fatalf("count likes: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("likes on post %d = %d, want 0", postID, n)
+	}
+}
+
+func TestLikeDraftPostNotFound(t *testing.T) {
+	db := testDB(t)
+	author := createUser(t, db, "author")
+	viewer := createUser(t, db, "viewer")
+	assertLikeNotFound(t, db, viewer, createPost(t, db, author, "public", "draft"))
+}
+
+func TestLikeFriendsOnlyPostNotFound(t *testing.T) {
+	db := testDB(t)
+	author := createUser(t, db, "author")
+	viewer := createUser(t, db, "viewer")
+	assertLikeNotFound(t, db, viewer, createPost(t, db, author, "friends", "published"))
+}
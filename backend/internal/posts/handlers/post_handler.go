@@ -1,10 +1,16 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	expmodels "chaladshare_backend/internal/experiments/models"
+	expservice "chaladshare_backend/internal/experiments/service"
+	"chaladshare_backend/internal/pagination"
 	"chaladshare_backend/internal/posts/models"
 	"chaladshare_backend/internal/posts/service"
 
@@ -12,19 +18,79 @@ import (
 )
 
 type PostHandler struct {
-	postService service.PostService
-	likeService service.LikeService
-	saveService service.SaveService
+	postService       service.PostService
+	likeService       service.LikeService
+	saveService       service.SaveService
+	repostService     service.RepostService
+	reactionService   service.ReactionService
+	feedService       service.FeedService
+	experimentService expservice.ExperimentService
 }
 
-func NewPostHandler(postService service.PostService, likeService service.LikeService, saveService service.SaveService) *PostHandler {
+func NewPostHandler(postService service.PostService, likeService service.LikeService, saveService service.SaveService, repostService service.RepostService, reactionService service.ReactionService, feedService service.FeedService, experimentService expservice.ExperimentService) *PostHandler {
 	return &PostHandler{
-		postService: postService,
-		likeService: likeService,
-		saveService: saveService,
+		postService:       postService,
+		likeService:       likeService,
+		saveService:       saveService,
+		repostService:     repostService,
+		reactionService:   reactionService,
+		feedService:       feedService,
+		experimentService: experimentService,
 	}
 }
 
+// ?limit=&cursor= ของรายการแบบ cursor (limit ไม่ถูกต้อง = ค่า default)
+func pageQuery(c *gin.Context) (string, int) {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+	return c.Query("cursor"), pagination.ClampLimit(limit)
+}
+
+func respondPageError(c *gin.Context, err error) {
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, models.ErrFeedExpired) {
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// สถานะ / เวลาเผยแพร่ / visibility / ไฟล์แนบ ไม่ถูกต้อง = 400
+func respondPostError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidStatus), errors.Is(err, models.ErrInvalidPublishAt),
+		errors.Is(err, models.ErrAlreadyPublished), errors.Is(err, models.ErrInvalidVisibility),
+		errors.Is(err, models.ErrInvalidAudienceList), errors.Is(err, models.ErrInvalidAttachments),
+		errors.Is(err, models.ErrInvalidQuote), errors.Is(err, models.ErrRepostOwnPost),
+		errors.Is(err, models.ErrInvalidReaction),
+		errors.Is(err, pagination.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrRevisionNotFound), errors.Is(err, models.ErrNotInTrash),
+		errors.Is(err, models.ErrPostNotFound), errors.Is(err, models.ErrRepostNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	// โพสต์ไม่มีอยู่ หรืออยู่ในถังขยะ
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+	case errors.Is(err, models.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func postIDs(posts []models.PostResponse) []int {
+	ids := make([]int, 0, len(posts))
+	for _, p := range posts {
+		ids = append(ids, p.PostID)
+	}
+	return ids
+}
+
 // สร้างโพสต์ใหม่ (ต้องล็อกอิน)
 func (h *PostHandler) CreatePost(c *gin.Context) {
 	uid := c.GetInt("user_id")
@@ -36,32 +102,36 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 	var req struct {
 		Title       string   `json:"post_title" binding:"required"`
 		Description string   `json:"post_description"`
-		Visibility  string   `json:"post_visibility" binding:"required"` // ตอนนี้รองรับ "public" เท่านั้น
-		DocumentID  *int     `json:"document_id"`
+		Visibility  string   `json:"post_visibility" binding:"required"` // public | friends | private | list
+		AudienceID  *int     `json:"audience_list_id"`                   // ใช้กับ list
+		DocumentID  *int     `json:"document_id"`                        // client เก่า: ไฟล์เดียว
+		Attachments []int    `json:"attachments"`                        // document_id ตามลำดับที่แสดง
 		CoverURL    *string  `json:"cover_url"`
 		Tags        []string `json:"tags"`
+		// "draft" | "scheduled" | "published" (ว่าง = published หรือ scheduled ถ้า publish_at อยู่ในอนาคต)
+		Status    string     `json:"post_status"`
+		PublishAt *time.Time `json:"publish_at"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
 		return
 	}
-	if req.Visibility != models.VisibilityPublic && req.Visibility != models.VisibilityFriends {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported visibility"})
-		return
-	}
-
 	post := &models.Post{
 		AuthorUserID: uid,
 		Title:        req.Title,
 		Description:  req.Description,
 		Visibility:   req.Visibility,
 		DocumentID:   req.DocumentID,
+		Attachments:  req.Attachments,
 		CoverURL:     req.CoverURL,
+		AudienceID:   req.AudienceID,
+		Status:       req.Status,
+		PublishAt:    req.PublishAt,
 	}
 
 	postID, err := h.postService.CreatePost(post, req.Tags)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPostError(c, err)
 		return
 	}
 	c.Header("Location", "/api/v1/posts/"+strconv.Itoa(postID))
@@ -69,6 +139,7 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 }
 
 // ดึงโพสต์ทั้งหมด (ต้องล็อกอิน)
+// ?mode=latest (ใหม่→เก่า, default) | ranked (เพื่อน/คนที่ติดตาม/แท็กที่ติดตาม/trending)
 func (h *PostHandler) GetAllPosts(c *gin.Context) {
 	uid := c.GetInt("user_id")
 	if uid == 0 {
@@ -76,12 +147,26 @@ func (h *PostHandler) GetAllPosts(c *gin.Context) {
 		return
 	}
 
-	posts, err := h.postService.GetFeedPosts(uid)
+	cursor, limit := pageQuery(c)
+
+	var (
+		page *models.PostPage
+		err  error
+	)
+	switch c.DefaultQuery("mode", models.FeedModeLatest) {
+	case models.FeedModeLatest:
+		page, err = h.postService.GetFeedPosts(uid, cursor, limit)
+	case models.FeedModeRanked:
+		page, err = h.feedService.GetRankedFeed(uid, cursor, limit)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mode"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPageError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": posts})
+	c.JSON(http.StatusOK, gin.H{"data": page.Items, "next_cursor": page.NextCursor})
 }
 
 // รายละเอียดโพสต์ (ต้องล็อกอิน)
@@ -100,7 +185,7 @@ func (h *PostHandler) GetPostByID(c *gin.Context) {
 
 	ok, reason, err := h.postService.ViewPost(uid, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPostError(c, err)
 		return
 	}
 	if !ok {
@@ -117,7 +202,7 @@ func (h *PostHandler) GetPostByID(c *gin.Context) {
 
 	post, err := h.postService.GetPostByIDForViewer(uid, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPostError(c, err)
 		return
 	}
 	if post == nil {
@@ -142,7 +227,7 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 
 	isOwner, err := h.postService.IsOwner(postID, uid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPostError(c, err)
 		return
 	}
 	if !isOwner {
@@ -154,7 +239,12 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 		Title       string   `json:"post_title"`
 		Description string   `json:"post_description"`
 		Visibility  *string  `json:"post_visibility"`
+		AudienceID  *int     `json:"audience_list_id"`
+		Attachments []int    `json:"attachments"`
 		Tags        []string `json:"tags"`
+		// ไม่ส่งทั้งคู่ = คงสถานะเดิม
+		Status    string     `json:"post_status"`
+		PublishAt *time.Time `json:"publish_at"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
@@ -162,12 +252,7 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 	}
 	vis := ""
 	if req.Visibility != nil {
-		v := strings.ToLower(strings.TrimSpace(*req.Visibility))
-		if v != models.VisibilityPublic && v != models.VisibilityFriends && v != "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported visibility"})
-			return
-		}
-		vis = v
+		vis = strings.ToLower(strings.TrimSpace(*req.Visibility))
 	}
 
 	post := &models.Post{
@@ -175,14 +260,84 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 		Title:       req.Title,
 		Description: req.Description,
 		Visibility:  vis,
+		AudienceID:  req.AudienceID,
+		Attachments: req.Attachments,
+		Status:      req.Status,
+		PublishAt:   req.PublishAt,
 	}
 	if err := h.postService.UpdatePost(post, req.Tags); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPostError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "post updated successfully"})
 }
 
+// ประวัติการแก้ไขโพสต์ (เฉพาะเจ้าของ)
+func (h *PostHandler) ListRevisions(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	isOwner, err := h.postService.IsOwner(postID, uid)
+	if err != nil {
+		respondPostError(c, err)
+		return
+	}
+	if !isOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	revisions, err := h.postService.ListRevisions(postID)
+	if err != nil {
+		respondPostError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": revisions})
+}
+
+// กู้คืนโพสต์จาก revision (เฉพาะเจ้าของ)
+func (h *PostHandler) RestoreRevision(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	revisionID, err := strconv.Atoi(c.Param("revision_id"))
+	if err != nil || revisionID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision_id"})
+		return
+	}
+
+	isOwner, err := h.postService.IsOwner(postID, uid)
+	if err != nil {
+		respondPostError(c, err)
+		return
+	}
+	if !isOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	if err := h.postService.RestoreRevision(postID, revisionID); err != nil {
+		respondPostError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "post restored successfully"})
+}
+
 // ลบโพสต์ (เฉพาะเจ้าของ)
 func (h *PostHandler) DeletePost(c *gin.Context) {
 	uid := c.GetInt("user_id")
@@ -198,7 +353,7 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 	// เช็คสิทธิ์เจ้าของก่อน
 	isOwner, err := h.postService.IsOwner(postID, uid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPostError(c, err)
 		return
 	}
 	if !isOwner {
@@ -207,12 +362,57 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 	}
 
 	if err := h.postService.DeletePost(postID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPostError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
 
+// โพสต์ในถังขยะของตัวเอง
+func (h *PostHandler) GetTrashedPosts(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	posts, err := h.postService.GetTrashedPosts(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": posts})
+}
+
+// กู้คืนโพสต์จากถังขยะ (เฉพาะเจ้าของ)
+func (h *PostHandler) RestorePost(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	isOwner, err := h.postService.IsOwner(postID, uid)
+	if err != nil {
+		respondPostError(c, err)
+		return
+	}
+	if !isOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	if err := h.postService.RestorePost(postID); err != nil {
+		respondPostError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "post restored successfully"})
+}
+
 // toggle like
 func (h *PostHandler) ToggleLike(c *gin.Context) {
 	uid := c.GetInt("user_id")
@@ -230,7 +430,7 @@ func (h *PostHandler) ToggleLike(c *gin.Context) {
 	// เรียก service ให้จัดการ toggle ให้
 	isLiked, likeCount, err := h.likeService.ToggleLike(uid, postID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPostError(c, err)
 		return
 	}
 
@@ -243,6 +443,75 @@ func (h *PostHandler) ToggleLike(c *gin.Context) {
 	})
 }
 
+// PUT /api/v1/posts/:id/like (ไลก์ซ้ำได้ ผลเหมือนเดิม)
+func (h *PostHandler) LikePost(c *gin.Context) {
+	h.setLike(c, true)
+}
+
+// DELETE /api/v1/posts/:id/like (ยกเลิกซ้ำได้ ผลเหมือนเดิม)
+func (h *PostHandler) UnlikePost(c *gin.Context) {
+	h.setLike(c, false)
+}
+
+func (h *PostHandler) setLike(c *gin.Context, liked bool) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	likeCount, err := h.likeService.SetLike(uid, postID, liked)
+	if err != nil {
+		respondPostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"post_id":    postID,
+			"is_liked":   liked,
+			"like_count": likeCount,
+		},
+	})
+}
+
+// PUT /api/v1/posts/:id/reactions/:type (กดซ้ำได้ ผลเหมือนเดิม, type = like | helpful | clear | thanks)
+func (h *PostHandler) AddReaction(c *gin.Context) {
+	h.setReaction(c, true)
+}
+
+// DELETE /api/v1/posts/:id/reactions/:type
+func (h *PostHandler) RemoveReaction(c *gin.Context) {
+	h.setReaction(c, false)
+}
+
+func (h *PostHandler) setReaction(c *gin.Context, on bool) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	summary, err := h.reactionService.SetReaction(uid, postID, c.Param("type"), on)
+	if err != nil {
+		respondPostError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
+
 // ดึงรายการโพสต์ที่ user คนนี้บันทึกไว้
 func (h *PostHandler) GetSavedPosts(c *gin.Context) {
 	uid := c.GetInt("user_id")
@@ -251,13 +520,32 @@ func (h *PostHandler) GetSavedPosts(c *gin.Context) {
 		return
 	}
 
-	posts, err := h.postService.GetSavedPosts(uid)
+	cursor, limit := pageQuery(c)
+	page, err := h.postService.GetSavedPosts(uid, cursor, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPageError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": posts})
+	c.JSON(http.StatusOK, gin.H{"data": page.Items, "next_cursor": page.NextCursor})
+}
+
+// draft + scheduled ของตัวเอง
+func (h *PostHandler) GetDraftPosts(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	cursor, limit := pageQuery(c)
+	page, err := h.postService.GetDraftPosts(uid, cursor, limit)
+	if err != nil {
+		respondPageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": page.Items, "next_cursor": page.NextCursor})
 }
 
 // toggle save
@@ -276,7 +564,7 @@ func (h *PostHandler) ToggleSave(c *gin.Context) {
 
 	isSaved, saveCount, err := h.saveService.ToggleSave(uid, postID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPostError(c, err)
 		return
 	}
 
@@ -289,6 +577,134 @@ func (h *PostHandler) ToggleSave(c *gin.Context) {
 	})
 }
 
+// PUT /api/v1/posts/:id/save (บันทึกซ้ำได้ ผลเหมือนเดิม)
+func (h *PostHandler) SavePost(c *gin.Context) {
+	h.setSave(c, true)
+}
+
+// DELETE /api/v1/posts/:id/save (ยกเลิกซ้ำได้ ผลเหมือนเดิม)
+func (h *PostHandler) UnsavePost(c *gin.Context) {
+	h.setSave(c, false)
+}
+
+func (h *PostHandler) setSave(c *gin.Context, saved bool) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	saveCount, err := h.saveService.SetSave(uid, postID, saved)
+	if err != nil {
+		respondPostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"post_id":    postID,
+			"is_saved":   saved,
+			"save_count": saveCount,
+		},
+	})
+}
+
+// รีโพสต์ให้ผู้ติดตาม (ส่งซ้ำ = แก้ quote)
+func (h *PostHandler) Repost(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req models.RepostRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+	}
+
+	repostCount, err := h.repostService.Repost(uid, postID, req.Quote)
+	if err != nil {
+		respondPostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"post_id":      postID,
+			"is_reposted":  true,
+			"repost_count": repostCount,
+		},
+	})
+}
+
+// ยกเลิกรีโพสต์
+func (h *PostHandler) Unrepost(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	repostCount, err := h.repostService.Unrepost(uid, postID)
+	if err != nil {
+		respondPostError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"post_id":      postID,
+			"is_reposted":  false,
+			"repost_count": repostCount,
+		},
+	})
+}
+
+// รายชื่อคนที่รีโพสต์ (ต้องเห็นโพสต์ต้นทางได้)
+func (h *PostHandler) ListReposts(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || postID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	cursor, limit := pageQuery(c)
+	page, err := h.repostService.ListReposts(uid, postID, cursor, limit)
+	if err != nil {
+		respondPostError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": page.Items, "next_cursor": page.NextCursor})
+}
+
+// GET /api/v1/posts/popular?limit=&cursor=
 func (h *PostHandler) GetPopularPosts(c *gin.Context) {
 	uid := c.GetInt("user_id")
 	if uid == 0 {
@@ -303,13 +719,20 @@ func (h *PostHandler) GetPopularPosts(c *gin.Context) {
 		return
 	}
 
-	posts, err := h.postService.GetPopularPosts(uid, limit)
+	// variant ของ experiment กำหนดลำดับได้ผ่าน config {"sort": "likes" | "saves" | "recent"}
+	exp := h.experimentService.Assign(uid, expmodels.SurfacePopular)
+	page, err := h.postService.GetPopularPosts(uid, exp.String("sort"), c.Query("cursor"), limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPageError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": posts})
+	resp := gin.H{"data": page.Items, "next_cursor": page.NextCursor}
+	if exp != nil {
+		h.experimentService.TrackImpressions(exp, uid, postIDs(page.Items), page.Offset)
+		resp["experiment"] = exp
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 func (h *PostHandler) SearchPosts(c *gin.Context) {
@@ -320,45 +743,88 @@ func (h *PostHandler) SearchPosts(c *gin.Context) {
 	}
 
 	search := strings.TrimSpace(c.Query("search"))
+	cursor, limit := pageQuery(c)
 
 	if search == "" {
 		c.JSON(http.StatusOK, gin.H{
 			"data": gin.H{
-				"items": []models.PostResponse{},
-				"total": 0,
-				"page":  1,
-				"size":  20,
+				"items":       []models.PostResponse{},
+				"total":       0,
+				"size":        limit,
+				"next_cursor": "",
 			},
 		})
 		return
 	}
 
-	pageStr := c.DefaultQuery("page", "1")
-	sizeStr := c.DefaultQuery("size", "20")
+	// variant ของ experiment กำหนดลำดับผลค้นหาได้ผ่าน config {"sort": "recent" | "relevance"}
+	exp := h.experimentService.Assign(uid, expmodels.SurfaceSearch)
+	page, err := h.postService.SearchPosts(uid, search, exp.String("sort"), cursor, limit)
+	if err != nil {
+		respondPageError(c, err)
+		return
+	}
+
+	data := gin.H{
+		"items":       page.Items,
+		"size":        limit,
+		"search":      search,
+		"next_cursor": page.NextCursor,
+	}
+	if page.Total != nil {
+		data["total"] = *page.Total
+	}
+	resp := gin.H{"data": data}
+
+	if exp != nil {
+		h.experimentService.TrackImpressions(exp, uid, postIDs(page.Items), page.Offset)
+		resp["experiment"] = exp
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// โพสต์ที่คล้ายกัน ("more like this") ใช้สิทธิ์การมองเห็นเดียวกับหน้ารายละเอียดโพสต์
+func (h *PostHandler) GetSimilarPosts(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
 
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
 	}
 
-	size, err := strconv.Atoi(sizeStr)
-	if err != nil || size <= 0 || size > 100 {
-		size = 20
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "6"))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return
 	}
 
-	items, total, err := h.postService.SearchPosts(uid, search, page, size)
+	ok, reason, err := h.postService.ViewPost(uid, id)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if !ok {
+		if reason == "not_found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+			return
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data": gin.H{
-			"items":  items,
-			"total":  total,
-			"page":   page,
-			"size":   size,
-			"search": search,
-		},
-	})
+	posts, err := h.postService.GetSimilarPosts(uid, id, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": posts})
 }
@@ -1,25 +1,77 @@
 package models
 
 import (
+	"errors"
 	"time"
+
+	"chaladshare_backend/internal/pagination"
 )
 
 const (
 	VisibilityPublic  = "public"
 	VisibilityFriends = "friends"
+	VisibilityPrivate = "private" // ผู้เขียนเท่านั้น
+	VisibilityList    = "list"    // สมาชิกของ audience list ที่เลือก
+)
+
+var (
+	ErrInvalidVisibility   = errors.New("post_visibility must be public, friends, private or list")
+	ErrInvalidAudienceList = errors.New("audience_list_id must be one of your audience lists")
+)
+
+// สถานะการเผยแพร่ (draft / scheduled เห็นได้เฉพาะผู้เขียน)
+const (
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+)
+
+var (
+	ErrInvalidStatus    = errors.New("post_status must be draft, scheduled or published")
+	ErrInvalidPublishAt = errors.New("publish_at must be in the future")
+	ErrAlreadyPublished = errors.New("published posts cannot go back to draft or scheduled")
+)
+
+// ไฟล์แนบต่อโพสต์ (PDF / รูปภาพ ของผู้เขียนเอง)
+const MaxAttachments = 10
+
+var ErrInvalidAttachments = errors.New("attachments must be 1-10 of your own documents")
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// โพสต์ที่ลบจะอยู่ในถังขยะกี่วันก่อนถูกลบจริง
+const TrashRetentionDays = 30
+
+var ErrNotInTrash = errors.New("post is not in trash or has already been purged")
+
+// ลำดับของโพสต์ยอดนิยม (variant ของ A/B experiment เลือกได้)
+const (
+	PopularByLikes  = "likes"
+	PopularBySaves  = "saves"
+	PopularByRecent = "recent"
+)
+
+// ลำดับผลค้นหา (variant ของ A/B experiment เลือกได้)
+const (
+	SearchByRecent    = "recent"    // ใหม่→เก่า (keyset)
+	SearchByRelevance = "relevance" // ตรงชื่อ/แท็กมากกว่าก่อน แล้วตามยอด like/save (keyset บนคะแนน)
 )
 
 // post
 type Post struct {
-	PostID       int       `json:"post_id"`
-	AuthorUserID int       `json:"-"`
-	Title        string    `json:"post_title"`
-	Description  string    `json:"post_description"`
-	Visibility   string    `json:"post_visibility"`
-	DocumentID   *int      `json:"post_document_id"`
-	CoverURL     *string   `json:"post_cover_url"`
-	CreatedAt    time.Time `json:"post_created_at"`
-	UpdatedAt    time.Time `json:"post_updated_at"`
+	PostID       int        `json:"post_id"`
+	AuthorUserID int        `json:"-"`
+	Title        string     `json:"post_title"`
+	Description  string     `json:"post_description"`
+	Visibility   string     `json:"post_visibility"`
+	DocumentID   *int       `json:"post_document_id"` // เอกสารหลัก (PDF แรกในไฟล์แนบ)
+	Attachments  []int      `json:"attachment_ids"`   // document_id เรียงตามลำดับที่แสดง
+	CoverURL     *string    `json:"post_cover_url"`
+	AudienceID   *int       `json:"post_audience_list_id"`
+	Status       string     `json:"post_status"`
+	PublishAt    *time.Time `json:"post_publish_at"`
+	CreatedAt    time.Time  `json:"post_created_at"`
+	UpdatedAt    time.Time  `json:"post_updated_at"`
 }
 
 // each tag
@@ -68,6 +120,18 @@ type PostResponse struct {
 	CreatedAt    time.Time `json:"post_created_at"`
 	UpdatedAt    time.Time `json:"post_updated_at"`
 
+	Status     string     `json:"post_status"`
+	PublishAt  *time.Time `json:"post_publish_at"`
+	AudienceID *int       `json:"post_audience_list_id"`
+
+	Attachments []Attachment `json:"attachments"`
+
+	Edited   bool       `json:"edited"`    // แก้ไขหลังเผยแพร่
+	EditedAt *time.Time `json:"edited_at"` // เวลาแก้ไขล่าสุด
+
+	// ถูกซ่อนจากการรายงาน (ผู้เขียนยังเห็น คนอื่นเห็นเหมือนไม่มีโพสต์)
+	HiddenAt *time.Time `json:"hidden_at,omitempty"`
+
 	FileURL   *string  `json:"file_url"`
 	CoverURL  *string  `json:"cover_url"`
 	AvatarURL *string  `json:"avatar_url"`
@@ -75,8 +139,145 @@ type PostResponse struct {
 	LikeCount int      `json:"like_count"`
 	SaveCount int      `json:"save_count"`
 
+	CommentCount int `json:"comment_count"`
+	RepostCount  int `json:"repost_count"`
+
 	IsLiked bool `json:"is_liked"`
 	IsSaved bool `json:"is_saved"`
+
+	// จำนวนต่อแบบ (รวม like) และแบบที่ผู้ชมกดไว้
+	Reactions   map[string]int `json:"reactions"`
+	MyReactions []string       `json:"my_reactions"`
+
+	// มีค่าเมื่อรายการนี้ใน feed มาจากการรีโพสต์ของคนที่ผู้ชมติดตาม
+	Repost *Repost `json:"repost,omitempty"`
+}
+
+// ไฟล์แนบหนึ่งไฟล์ของโพสต์
+type Attachment struct {
+	DocumentID int    `json:"document_id"`
+	Name       string `json:"document_name"`
+	FileURL    string `json:"file_url"`
+	Kind       string `json:"document_kind"` // pdf | image
+	Position   int    `json:"position"`
+}
+
+// สถานะของโพสต์ก่อนการแก้ไขแต่ละครั้ง
+type PostRevision struct {
+	RevisionID    int       `json:"revision_id"`
+	PostID        int       `json:"post_id"`
+	Number        int       `json:"revision_number"`
+	Title         string    `json:"post_title"`
+	Description   string    `json:"post_description"`
+	Visibility    string    `json:"post_visibility"`
+	AudienceID    *int      `json:"post_audience_list_id"`
+	Tags          []string  `json:"tags"`
+	AttachmentIDs []int     `json:"attachment_ids"`
+	CreatedAt     time.Time `json:"revision_created_at"`
+}
+
+// รีโพสต์: แชร์โพสต์ที่ตัวเองเห็นได้ให้ผู้ติดตาม (ผู้ติดตามต้องเห็นโพสต์ต้นทางได้ด้วย)
+const MaxQuoteLen = 500
+
+var (
+	ErrInvalidQuote   = errors.New("quote must be at most 500 characters")
+	ErrRepostOwnPost  = errors.New("cannot repost your own post")
+	ErrRepostNotFound = errors.New("repost not found")
+	ErrPostNotFound   = errors.New("post not found")
+	ErrForbidden      = errors.New("forbidden")
+)
+
+type Repost struct {
+	RepostID  int       `json:"repost_id"`
+	PostID    int       `json:"post_id"`
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username"`
+	AvatarURL *string   `json:"avatar_url"`
+	Quote     *string   `json:"quote"`
+	CreatedAt time.Time `json:"repost_created_at"`
+}
+
+// body: {"quote": "..."} (ไม่ส่ง = รีโพสต์เฉย ๆ)
+type RepostRequest struct {
+	Quote *string `json:"quote"`
+}
+
+type RepostPage struct {
+	Items      []Repost `json:"items"`
+	NextCursor string   `json:"next_cursor"`
+}
+
+// หนึ่งรายการของ latest feed: โพสต์ตรง ๆ หรือรีโพสต์ (SortAt = เวลารีโพสต์)
+type FeedEntry struct {
+	PostID   int
+	RepostID *int
+	SortAt   time.Time
+}
+
+// cursor ของ latest feed: keyset บน (เวลาในฟีด, post_id, repost_id) (โพสต์ตรง ๆ = repost_id 0)
+type FeedEntryCursor struct {
+	SortAt   time.Time `json:"t"`
+	PostID   int       `json:"id"`
+	RepostID int       `json:"r,omitempty"`
+	Offset   int       `json:"o,omitempty"`
+}
+
+// โพสต์ในถังขยะ (กู้คืนได้จนถึง purge_at)
+type TrashedPost struct {
+	PostID    int       `json:"post_id"`
+	Title     string    `json:"post_title"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// โหมดของ home feed
+const (
+	FeedModeLatest = "latest"
+	FeedModeRanked = "ranked"
+)
+
+var ErrFeedExpired = errors.New("feed snapshot expired, reload the feed")
+
+// cursor ของ ranked feed: อ่านต่อจาก snapshot (Rank) แล้วต่อด้วยโพสต์ใหม่→เก่าที่ไม่อยู่ใน snapshot (Tail)
+type FeedCursor struct {
+	Snapshot int64              `json:"s"`
+	Rank     int                `json:"r"`
+	Tail     *pagination.Keyset `json:"k,omitempty"`
+}
+
+// ลำดับของโพสต์ใน snapshot ของ ranked feed
+type FeedRankItem struct {
+	Rank   int
+	PostID int
+}
+
+// cursor ของโพสต์ยอดนิยม: keyset บน (คะแนน, post_id)
+// Count = ยอด like/save, At = เวลากิจกรรมล่าสุด (sort=recent)
+// Sort ต้องตรงกับลำดับของหน้าปัจจุบัน ไม่งั้น keyset ไม่มีความหมาย
+type PopularCursor struct {
+	Sort   string    `json:"s"`
+	Count  int       `json:"n,omitempty"`
+	At     time.Time `json:"t,omitempty"`
+	ID     int       `json:"id"`
+	Offset int       `json:"o,omitempty"`
+}
+
+// cursor ของผลค้นหา: recent = keyset บน (CreatedAt, ID)
+// relevance = keyset บน (Score, CreatedAt, ID) โดย Score คือคะแนนความเกี่ยวข้องของรายการสุดท้าย
+type SearchCursor struct {
+	Sort      string    `json:"s"`
+	Score     float64   `json:"n,omitempty"`
+	CreatedAt time.Time `json:"t"`
+	ID        int       `json:"id"`
+	Offset    int       `json:"o,omitempty"`
+}
+
+// หน้ารายการโพสต์แบบ cursor (next_cursor ว่าง = หมดแล้ว)
+type PostPage struct {
+	Items      []PostResponse `json:"items"`
+	NextCursor string         `json:"next_cursor"`
+	Total      *int           `json:"total,omitempty"` // search: นับเฉพาะหน้าแรก
+	Offset     int            `json:"-"`               // ตำแหน่งของรายการแรกในหน้านี้
 }
 
 type UpdatePostRequest struct {
@@ -92,3 +293,17 @@ type PostQueryParam struct {
 	Sort   string   `form:"sort"`
 	Limit  int      `form:"limit"`
 }
+
+// reaction ของโพสต์: "like" เป็นค่าเริ่มต้น (เก็บในตาราง likes, endpoint like เดิมคือ reaction นี้)
+const DefaultReaction = "like"
+
+// เรียงตามลำดับที่แสดง
+var ReactionTypes = []string{DefaultReaction, "helpful", "clear", "thanks"}
+
+var ErrInvalidReaction = errors.New("reaction must be one of like, helpful, clear, thanks")
+
+type ReactionSummary struct {
+	PostID      int            `json:"post_id"`
+	Reactions   map[string]int `json:"reactions"`
+	MyReactions []string       `json:"my_reactions"`
+}
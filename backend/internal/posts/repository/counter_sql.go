@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"chaladshare_backend/internal/posts/models"
+	"chaladshare_backend/internal/visibility"
+)
+
+// ตาราง (ผู้ใช้, โพสต์) ที่มีตัวนับใน post_stats เช่น likes → post_like_count
+// เปลี่ยนแถวและบวก/ลบตัวนับใน statement เดียว (ไม่ COUNT(*) ใหม่ทั้งโพสต์)
+type counterTable struct {
+	table    string
+	userCol  string
+	postCol  string
+	countCol string
+}
+
+var (
+	likeCounter = counterTable{table: "likes", userCol: "like_user_id", postCol: "like_post_id", countCol: "post_like_count"}
+	saveCounter = counterTable{table: "saved_posts", userCol: "save_user_id", postCol: "save_post_id", countCol: "post_save_count"}
+)
+
+// $1 = user_id, $2 = post_id
+// โพสต์ที่ผู้ใช้มองไม่เห็น (ไม่มี, ถังขยะ, draft, ถูกซ่อน, private/friends/list ที่ไม่มีสิทธิ์)
+// = ไม่เปลี่ยนอะไรและไม่คืนแถว จึงไม่เผยจำนวนหรือดันกิจกรรมล่าสุดของโพสต์ที่ไม่ควรเห็น
+const qCounterTarget = `target AS (
+		SELECT p.post_id FROM posts p
+		WHERE p.post_id = $2 AND ` + visibility.PostPublished + ` AND ` + visibility.PostVisible + `
+	)`
+
+func (t counterTable) del() string {
+	return `del AS (
+		DELETE FROM ` + t.table + `
+		WHERE ` + t.userCol + ` = $1 AND ` + t.postCol + ` = $2 AND EXISTS (SELECT 1 FROM target)
+		RETURNING 1
+	)`
+}
+
+func (t counterTable) ins(cond string) string {
+	return `ins AS (
+		INSERT INTO ` + t.table + ` (` + t.userCol + `, ` + t.postCol + `)
+		SELECT $1, post_id FROM target ` + cond + `
+		ON CONFLICT DO NOTHING
+		RETURNING 1
+	)`
+}
+
+// บวก delta.n เข้าตัวนับ (กิจกรรมล่าสุดเปลี่ยนเมื่อมีการเปลี่ยนจริง)
+func (t counterTable) apply() string {
+	return `
+	INSERT INTO post_stats (post_stats_post_id, ` + t.countCol + `, post_last_activity_at)
+	SELECT target.post_id, GREATEST(delta.n, 0), now() FROM target, delta
+	ON CONFLICT (post_stats_post_id) DO UPDATE SET
+		` + t.countCol + ` = GREATEST(COALESCE(post_stats.` + t.countCol + `, 0) + (SELECT n FROM delta), 0),
+		post_last_activity_at = CASE WHEN (SELECT n FROM delta) <> 0
+			THEN now() ELSE post_stats.post_last_activity_at END
+	RETURNING post_stats.` + t.countCol
+}
+
+// กดซ้ำ = ยกเลิก คืน (สถานะหลังกด, จำนวนล่าสุด)
+// ล็อก advisory ต่อ (ผู้ใช้, โพสต์) ก่อนอ่านแถวเดิม: ถ้าไม่ล็อก การกดพร้อมกันสองครั้งบนโพสต์ที่ยังไม่กด
+// จะเห็น "ไม่มีแถว" ทั้งคู่ภายใต้ READ COMMITTED แล้วได้ผลเป็นกดทั้งคู่
+// ครั้งที่สองรอจน commit แล้วเริ่ม statement ใหม่ จึงเห็นผลของครั้งแรก = เหมือนกดทีละครั้ง
+func (t counterTable) toggle(db *sql.DB, userID, postID int) (bool, int, error) {
+	query := `WITH ` + qCounterTarget + `, ` + t.del() + `, ` + t.ins(`WHERE NOT EXISTS (SELECT 1 FROM del)`) + `,
+	delta AS (SELECT (SELECT COUNT(*) FROM ins) - (SELECT COUNT(*) FROM del) AS n)` +
+		t.apply() + `, NOT EXISTS (SELECT 1 FROM del);`
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1, $2)`, userID, postID); err != nil {
+		return false, 0, fmt.Errorf("lock %s: %w", t.table, err)
+	}
+
+	var (
+		count int
+		on    bool
+	)
+	err = tx.QueryRow(query, userID, postID).Scan(&count, &on)
+	if err == sql.ErrNoRows {
+		return false, 0, models.ErrPostNotFound
+	}
+	if err != nil {
+		return false, 0, fmt.Errorf("toggle %s: %w", t.table, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, 0, err
+	}
+	return on, count, nil
+}
+
+// ตั้งสถานะตรง ๆ (PUT / DELETE) เรียกซ้ำได้ผลเหมือนเดิม คืนจำนวนล่าสุด
+func (t counterTable) set(db *sql.DB, userID, postID int, on bool) (int, error) {
+	var query string
+	if on {
+		query = `WITH ` + qCounterTarget + `, ` + t.ins(``) + `,
+	delta AS (SELECT (SELECT COUNT(*) FROM ins) AS n)` + t.apply() + `;`
+	} else {
+		query = `WITH ` + qCounterTarget + `, ` + t.del() + `,
+	delta AS (SELECT -(SELECT COUNT(*) FROM del) AS n)` + t.apply() + `;`
+	}
+
+	var count int
+	err := db.QueryRow(query, userID, postID).Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, models.ErrPostNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("set %s: %w", t.table, err)
+	}
+	return count, nil
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"chaladshare_backend/internal/pagination"
+	"chaladshare_backend/internal/posts/models"
+	"chaladshare_backend/internal/visibility"
+)
+
+type FeedRepository interface {
+	BuildSnapshot(viewerID, maxItems int, keep time.Duration) (int64, time.Time, error)
+	GetSnapshot(snapshotID int64) (int, time.Time, error)
+	ListSnapshotItems(snapshotID int64, afterRank, limit int) ([]models.FeedRankItem, error)
+	ListTail(viewerID int, snapshotID int64, after pagination.Keyset, limit int) ([]pagination.Keyset, error)
+}
+
+type feedRepository struct {
+	db *sql.DB
+}
+
+func NewFeedRepository(db *sql.DB) FeedRepository {
+	return &feedRepository{db: db}
+}
+
+// น้ำหนักคะแนนของ ranked feed (รวมกันได้สูงสุด ~1)
+const (
+	feedWindowDays       = 30   // พิจารณาเฉพาะโพสต์ในช่วงนี้
+	feedRecencyWeight    = 0.45 // ความใหม่ (exp decay)
+	feedRecencyHours     = 48.0 // ค่าคงที่ของ decay (ชั่วโมง)
+	feedEngagementWeight = 0.25 // like + 2*save จาก post_stats (log scale)
+	feedAffinityWeight   = 0.30 // ความสัมพันธ์กับผู้เขียน + แท็กที่ติดตาม
+	feedTrendingLimit    = 100  // จำนวนโพสต์ trending ที่ใช้เป็นแหล่ง
+)
+
+// สร้าง snapshot ลำดับฟีดของผู้ชม
+// แหล่งโพสต์: เพื่อน, คนที่ติดตาม (รวมโพสต์ที่คนที่ติดตามรีโพสต์), แท็กที่ติดตาม, โพสต์ trending (7 วัน)
+// และล้าง snapshot เก่าของผู้ชมที่เกินอายุ keep
+func (r *feedRepository) BuildSnapshot(viewerID, maxItems int, keep time.Duration) (int64, time.Time, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		DELETE FROM home_feed_snapshots
+		WHERE snapshot_user_id = $1 AND snapshot_created_at < now() - make_interval(secs => $2);`,
+		viewerID, keep.Seconds()); err != nil {
+		return 0, time.Time{}, fmt.Errorf("cleanup feed snapshots: %w", err)
+	}
+
+	var (
+		snapshotID int64
+		createdAt  time.Time
+	)
+	if err := tx.QueryRow(`
+		INSERT INTO home_feed_snapshots (snapshot_user_id) VALUES ($1)
+		RETURNING snapshot_id, snapshot_created_at;`, viewerID).Scan(&snapshotID, &createdAt); err != nil {
+		return 0, time.Time{}, fmt.Errorf("create feed snapshot: %w", err)
+	}
+
+	query := `
+		WITH viewer_friends AS (
+			SELECT CASE WHEN f.user_id = $1 THEN f.friend_id ELSE f.user_id END AS uid
+			FROM friendships f
+			WHERE f.user_id = $1 OR f.friend_id = $1
+		),
+		viewer_follows AS (
+			SELECT followed_user_id AS uid FROM follows WHERE follower_user_id = $1
+		),
+		viewer_tags AS (
+			SELECT tag_follow_tag_id AS tag_id FROM tag_follows WHERE tag_follow_user_id = $1
+		),
+		trending AS (
+			SELECT ps.post_stats_post_id AS post_id
+			FROM post_stats ps
+			WHERE ps.post_last_activity_at >= now() - interval '7 days'
+			ORDER BY (COALESCE(ps.post_like_count, 0) + 2 * COALESCE(ps.post_save_count, 0)) DESC
+			LIMIT $9
+		),
+		-- จำนวนครั้งที่ผู้ชม like/save โพสต์ของผู้เขียนแต่ละคนใน 90 วัน
+		interactions AS (
+			SELECT p.post_author_user_id AS author_id, COUNT(*) AS n
+			FROM (
+				SELECT like_post_id AS post_id FROM likes
+				WHERE like_user_id = $1 AND like_created_at >= now() - interval '90 days'
+				UNION ALL
+				SELECT save_post_id FROM saved_posts
+				WHERE save_user_id = $1 AND save_created_at >= now() - interval '90 days'
+			) x
+			JOIN posts p ON p.post_id = x.post_id
+			GROUP BY p.post_author_user_id
+		),
+		candidates AS (
+			SELECT p.post_id, p.post_author_user_id, p.post_created_at,
+				p.post_author_user_id IN (SELECT uid FROM viewer_friends) AS from_friend,
+				p.post_author_user_id IN (SELECT uid FROM viewer_follows) AS from_follow,
+				EXISTS (
+					SELECT 1 FROM reposts rp
+					JOIN viewer_follows vf ON vf.uid = rp.repost_user_id
+					WHERE rp.repost_post_id = p.post_id
+				) AS from_repost,
+				EXISTS (
+					SELECT 1 FROM post_tags pt
+					JOIN viewer_tags vt ON vt.tag_id = pt.post_tag_tag_id
+					WHERE pt.post_tag_post_id = p.post_id
+				) AS from_tag,
+				p.post_id IN (SELECT post_id FROM trending) AS is_trending
+			FROM posts p
+			WHERE p.post_author_user_id <> $1
+			  AND p.post_created_at >= now() - make_interval(days => $3)
+			  AND ` + visibility.PostPublished + `
+			  AND ` + visibility.PostVisible + `
+		),
+		scored AS (
+			SELECT c.post_id, c.post_created_at,
+				$5 * EXP(-EXTRACT(EPOCH FROM now() - c.post_created_at) / 3600.0 / $6)
+				+ $7 * LEAST(LN(1 + COALESCE(ps.post_like_count, 0) + 2 * COALESCE(ps.post_save_count, 0)) / LN(51), 1)
+				+ $8 * LEAST(
+					CASE WHEN c.from_friend THEN 0.4 ELSE 0 END
+					+ CASE WHEN c.from_follow THEN 0.3 ELSE 0 END
+					+ CASE WHEN c.from_repost THEN 0.3 ELSE 0 END
+					+ CASE WHEN c.from_tag THEN 0.2 ELSE 0 END
+					+ 0.3 * LN(1 + COALESCE(i.n, 0)) / LN(11),
+				1) AS score
+			FROM candidates c
+			LEFT JOIN post_stats ps ON ps.post_stats_post_id = c.post_id
+			LEFT JOIN interactions i ON i.author_id = c.post_author_user_id
+			WHERE c.from_friend OR c.from_follow OR c.from_repost OR c.from_tag OR c.is_trending
+		)
+		INSERT INTO home_feed_items (item_snapshot_id, item_rank, item_post_id)
+		SELECT $2, ROW_NUMBER() OVER (ORDER BY score DESC, post_created_at DESC, post_id DESC), post_id
+		FROM (
+			SELECT post_id, post_created_at, score
+			FROM scored
+			ORDER BY score DESC, post_created_at DESC, post_id DESC
+			LIMIT $4
+		) top;`
+
+	if _, err := tx.Exec(query, viewerID, snapshotID, feedWindowDays, maxItems,
+		feedRecencyWeight, feedRecencyHours, feedEngagementWeight, feedAffinityWeight,
+		feedTrendingLimit); err != nil {
+		return 0, time.Time{}, fmt.Errorf("rank feed: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, time.Time{}, err
+	}
+	return snapshotID, createdAt, nil
+}
+
+func (r *feedRepository) GetSnapshot(snapshotID int64) (int, time.Time, error) {
+	var (
+		userID    int
+		createdAt time.Time
+	)
+	err := r.db.QueryRow(`
+		SELECT snapshot_user_id, snapshot_created_at
+		FROM home_feed_snapshots WHERE snapshot_id = $1;`, snapshotID).Scan(&userID, &createdAt)
+	return userID, createdAt, err
+}
+
+func (r *feedRepository) ListSnapshotItems(snapshotID int64, afterRank, limit int) ([]models.FeedRankItem, error) {
+	rows, err := r.db.Query(`
+		SELECT item_rank, item_post_id
+		FROM home_feed_items
+		WHERE item_snapshot_id = $1 AND item_rank > $2
+		ORDER BY item_rank
+		LIMIT $3;`, snapshotID, afterRank, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.FeedRankItem, 0, limit)
+	for rows.Next() {
+		var it models.FeedRankItem
+		if err := rows.Scan(&it.Rank, &it.PostID); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// หลังหมด snapshot: โพสต์ใหม่→เก่าที่ผู้ชมเห็นได้ และไม่อยู่ใน snapshot (กันซ้ำกับหน้าก่อน ๆ)
+func (r *feedRepository) ListTail(viewerID int, snapshotID int64, after pagination.Keyset, limit int) ([]pagination.Keyset, error) {
+	query := `
+		SELECT p.post_id, p.post_created_at
+		FROM posts p
+		WHERE ` + visibility.PostVisible + `
+		AND ` + visibility.PostPublished + `
+		AND ` + keysetAfter(3, 4) + `
+		AND NOT EXISTS (
+			SELECT 1 FROM home_feed_items hi
+			WHERE hi.item_snapshot_id = $2 AND hi.item_post_id = p.post_id
+		)
+		ORDER BY p.post_created_at DESC, p.post_id DESC
+		LIMIT $5;`
+
+	rows, err := r.db.Query(query, viewerID, snapshotID, after.CreatedAt, after.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]pagination.Keyset, 0, limit)
+	for rows.Next() {
+		var k pagination.Keyset
+		if err := rows.Scan(&k.ID, &k.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
@@ -7,24 +7,41 @@ import (
 
 	"github.com/lib/pq"
 
+	"chaladshare_backend/internal/pagination"
 	"chaladshare_backend/internal/posts/models"
+	"chaladshare_backend/internal/tagname"
+	"chaladshare_backend/internal/visibility"
 )
 
 type PostRepository interface {
 	CreatePost(post *models.Post, tags []string) (int, error)
 	UpdatePost(post *models.Post, tags []string) error
 	DeletePost(postID int) error
+	RestorePost(postID int) error
+	GetTrashedPosts(userID int) ([]models.TrashedPost, error)
+	PurgeTrash() (int, error)
+	ReconcileStats() (int, error)
 
-	GetAllPosts() ([]models.PostResponse, error)
+	GetAllPosts(after *pagination.Keyset, limit int) ([]models.PostResponse, error)
 	GetPostByID(postID int) (*models.PostResponse, error)
 	GetPostByIDForViewer(viewerID, postID int) (*models.PostResponse, error)
-	GetFeedPosts(viewerID int) ([]models.PostResponse, error)
+	GetFeedEntries(viewerID int, after *models.FeedEntryCursor, limit int) ([]models.FeedEntry, error)
+	GetRepostsByIDs(repostIDs []int) (map[int]models.Repost, error)
 	GetPostOwnerID(postID int) (int, error)
+	ListRevisions(postID int) ([]models.PostRevision, error)
+	GetRevision(postID, revisionID int) (*models.PostRevision, error)
+	GetAudienceListOwner(listID int) (int, error)
+	IsAudienceMember(listID, userID int) (bool, error)
 	CountByUserID(userID int) (int, error)
 
-	GetSavedPosts(userID int) ([]models.PostResponse, error)
-	GetPopularPosts(viewerID, limit int) ([]models.PostResponse, error)
-	SearchPosts(viewerID int, search string, page, size int) ([]models.PostResponse, int, error)
+	GetSavedPosts(userID int, after *pagination.Keyset, limit int) ([]models.PostResponse, error)
+	GetDraftPosts(userID int, after *pagination.Keyset, limit int) ([]models.PostResponse, error)
+	PublishDue() (int, error)
+	GetPopularPosts(viewerID int, orderBy string, after *models.PopularCursor, limit int) ([]models.PostResponse, []models.PopularCursor, error)
+	SearchPosts(viewerID int, search, orderBy string, after *models.SearchCursor, limit int) ([]models.PostResponse, []float64, error)
+	CountSearchPosts(viewerID int, search string) (int, error)
+	GetSimilarPosts(viewerID, postID, limit int) ([]models.PostResponse, error)
+	GetPostsByIDs(viewerID int, postIDs []int) ([]models.PostResponse, error)
 }
 
 type postRepository struct {
@@ -42,10 +59,11 @@ func (r *postRepository) CreatePost(post *models.Post, tags []string) (int, erro
 	}
 	defer tx.Rollback()
 
-	if post.DocumentID == nil {
-		return 0, fmt.Errorf("document_id is required")
+	primaryID, err := primaryDocument(tx, post.AuthorUserID, post.Attachments)
+	if err != nil {
+		return 0, err
 	}
-	var docArg interface{} = *post.DocumentID
+	post.DocumentID = &primaryID
 
 	var coverArg interface{} = nil
 	if post.CoverURL != nil {
@@ -53,40 +71,27 @@ func (r *postRepository) CreatePost(post *models.Post, tags []string) (int, erro
 	}
 
 	query := `INSERT INTO posts (post_author_user_id, post_title, post_description,
-			  post_visibility, post_document_id, post_cover_url) 
-			  SELECT $1, $2, $3, $4, $5, $6
-			  FROM documents d
-			  WHERE d.document_id = $5 AND d.document_user_id = $1
+			  post_visibility, post_document_id, post_cover_url, post_status, post_publish_at,
+			  post_audience_list_id) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 			  RETURNING post_id;`
 
 	var postID int
 	if err := tx.QueryRow(
 		query,
 		post.AuthorUserID, post.Title, post.Description,
-		post.Visibility, docArg, coverArg,
+		post.Visibility, primaryID, coverArg, post.Status, post.PublishAt,
+		post.AudienceID,
 	).Scan(&postID); err != nil {
-		if err == sql.ErrNoRows {
-			return 0, fmt.Errorf("invalid document_id or not owned by user")
-		}
 		return 0, fmt.Errorf("create post: %w", err)
 	}
 
-	if len(tags) > 0 {
-		upsertTag := `INSERT INTO tags (tag_name) VALUES ($1) ON CONFLICT (tag_name) DO UPDATE
-					  SET tag_name = EXCLUDED.tag_name RETURNING tag_id;`
-
-		link := `INSERT INTO post_tags (post_tag_post_id, post_tag_tag_id)
-				 VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+	if err := replaceAttachments(tx, postID, post.Attachments); err != nil {
+		return 0, err
+	}
 
-		for _, t := range tags {
-			var tagID int
-			if err := tx.QueryRow(upsertTag, t).Scan(&tagID); err != nil {
-				return 0, fmt.Errorf("upsert tag %q: %w", t, err)
-			}
-			if _, err := tx.Exec(link, postID, tagID); err != nil {
-				return 0, fmt.Errorf("link tag %q: %w", t, err)
-			}
-		}
+	if err := linkTags(tx, postID, tags); err != nil {
+		return 0, err
 	}
 
 	initStats := `INSERT INTO post_stats (post_stats_post_id, post_like_count, post_save_count)
@@ -108,10 +113,43 @@ func (r *postRepository) UpdatePost(post *models.Post, tags []string) error {
 	}
 	defer tx.Rollback()
 
+	// เก็บสถานะก่อนแก้เป็น revision (ล็อกแถวกันเลข revision ชนกัน)
+	if _, err := tx.Exec(`SELECT 1 FROM posts WHERE post_id = $1 FOR UPDATE`, post.PostID); err != nil {
+		return fmt.Errorf("lock post: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO post_revisions (revision_post_id, revision_number, revision_title, revision_description,
+			revision_visibility, revision_audience_list_id, revision_tags, revision_attachment_ids)
+		SELECT p.post_id,
+			COALESCE((SELECT MAX(revision_number) FROM post_revisions WHERE revision_post_id = p.post_id), 0) + 1,
+			p.post_title, p.post_description, p.post_visibility, p.post_audience_list_id,
+			ARRAY(
+				SELECT t.tag_name FROM post_tags pt
+				JOIN tags t ON t.tag_id = pt.post_tag_tag_id
+				WHERE pt.post_tag_post_id = p.post_id
+				ORDER BY t.tag_name
+			),
+			ARRAY(
+				SELECT pd.attachment_document_id FROM post_documents pd
+				WHERE pd.attachment_post_id = p.post_id
+				ORDER BY pd.attachment_position
+			)
+		FROM posts p
+		WHERE p.post_id = $1;`, post.PostID); err != nil {
+		return fmt.Errorf("save revision: %w", err)
+	}
+
+	// เผยแพร่จาก draft / scheduled: ตั้ง post_created_at เป็นเวลาเผยแพร่ ให้ขึ้นบนสุดของ feed
+	// แก้โพสต์ที่เผยแพร่แล้ว = "edited"
 	res, err := tx.Exec(`UPDATE posts SET post_title = $1,
-        				 post_description = $2, post_visibility = $3, post_updated_at = now()
+        				 post_description = $2, post_visibility = $3, post_updated_at = now(),
+        				 post_edited_at = CASE WHEN post_status = 'published' THEN now() ELSE post_edited_at END,
+        				 post_created_at = CASE WHEN post_status <> 'published' AND $5::text = 'published'
+        				                        THEN now() ELSE post_created_at END,
+        				 post_status = $5, post_publish_at = $6, post_audience_list_id = $7
     					 WHERE post_id = $4;`,
-		post.Title, post.Description, post.Visibility, post.PostID)
+		post.Title, post.Description, post.Visibility, post.PostID, post.Status, post.PublishAt,
+		post.AudienceID)
 	if err != nil {
 		return fmt.Errorf("update post: %w", err)
 	}
@@ -119,29 +157,27 @@ func (r *postRepository) UpdatePost(post *models.Post, tags []string) error {
 		return sql.ErrNoRows
 	}
 
+	// ส่งไฟล์แนบมา = แทนที่ทั้งชุด (รวมลำดับ) และเปลี่ยนเอกสารหลักตาม
+	if post.Attachments != nil {
+		primaryID, err := primaryDocument(tx, post.AuthorUserID, post.Attachments)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE posts SET post_document_id = $2 WHERE post_id = $1`, post.PostID, primaryID); err != nil {
+			return fmt.Errorf("update primary document: %w", err)
+		}
+		if err := replaceAttachments(tx, post.PostID, post.Attachments); err != nil {
+			return err
+		}
+	}
+
 	if tags != nil {
 		if _, err := tx.Exec(`DELETE FROM post_tags WHERE post_tag_post_id = $1`, post.PostID); err != nil {
 			return fmt.Errorf("clear old tags: %w", err)
 		}
 
-		if len(tags) > 0 {
-			upsertTag := `INSERT INTO tags (tag_name)
-						  VALUES ($1) ON CONFLICT (tag_name) DO UPDATE
-						  SET tag_name = EXCLUDED.tag_name
-						  RETURNING tag_id;`
-
-			link := `INSERT INTO post_tags (post_tag_post_id, post_tag_tag_id)
-					 VALUES ($1, $2) ON CONFLICT DO NOTHING;`
-
-			for _, t := range tags {
-				var tagID int
-				if err := tx.QueryRow(upsertTag, t).Scan(&tagID); err != nil {
-					return fmt.Errorf("upsert tag %q: %w", t, err)
-				}
-				if _, err := tx.Exec(link, post.PostID, tagID); err != nil {
-					return fmt.Errorf("link tag %q: %w", t, err)
-				}
-			}
+		if err := linkTags(tx, post.PostID, tags); err != nil {
+			return err
 		}
 	}
 
@@ -151,8 +187,148 @@ func (r *postRepository) UpdatePost(post *models.Post, tags []string) error {
 	return nil
 }
 
+// เอกสารทุกไฟล์ต้องเป็นของผู้เขียน (เหมือนเช็ค document_id เดิม)
+// คืนเอกสารหลัก = PDF แรกตามลำดับ ถ้าไม่มี PDF ใช้ไฟล์แรก
+func primaryDocument(tx *sql.Tx, authorID int, documentIDs []int) (int, error) {
+	if len(documentIDs) == 0 {
+		return 0, models.ErrInvalidAttachments
+	}
+	ids := make([]int64, len(documentIDs))
+	for i, id := range documentIDs {
+		ids[i] = int64(id)
+	}
+
+	rows, err := tx.Query(`
+		SELECT document_id, document_kind FROM documents
+		WHERE document_id = ANY($1::int[]) AND document_user_id = $2 AND document_deleted_at IS NULL;`, pq.Array(ids), authorID)
+	if err != nil {
+		return 0, fmt.Errorf("check attachments: %w", err)
+	}
+	defer rows.Close()
+
+	kinds := make(map[int]string, len(documentIDs))
+	for rows.Next() {
+		var (
+			id   int
+			kind string
+		)
+		if err := rows.Scan(&id, &kind); err != nil {
+			return 0, err
+		}
+		kinds[id] = kind
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(kinds) != len(documentIDs) {
+		return 0, models.ErrInvalidAttachments
+	}
+
+	for _, id := range documentIDs {
+		if kinds[id] == "pdf" {
+			return id, nil
+		}
+	}
+	return documentIDs[0], nil
+}
+
+// ผูกแท็กกับโพสต์: ชื่อที่เป็น alias ใช้แท็ก canonical, ชื่อที่ถูกแบนข้ามไป
+func linkTags(tx *sql.Tx, postID int, tags []string) error {
+	upsertTag := `INSERT INTO tags (tag_name) VALUES ($1) ON CONFLICT (tag_name) DO UPDATE
+				  SET tag_name = EXCLUDED.tag_name RETURNING tag_id;`
+
+	link := `INSERT INTO post_tags (post_tag_post_id, post_tag_tag_id)
+			 VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+
+	for _, t := range tags {
+		var name string
+		err := tx.QueryRow(tagname.ResolveSQL, t).Scan(&name)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("resolve tag %q: %w", t, err)
+		}
+
+		var tagID int
+		if err := tx.QueryRow(upsertTag, name).Scan(&tagID); err != nil {
+			return fmt.Errorf("upsert tag %q: %w", name, err)
+		}
+		if _, err := tx.Exec(link, postID, tagID); err != nil {
+			return fmt.Errorf("link tag %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func replaceAttachments(tx *sql.Tx, postID int, documentIDs []int) error {
+	ids := make([]int64, len(documentIDs))
+	for i, id := range documentIDs {
+		ids[i] = int64(id)
+	}
+	if _, err := tx.Exec(`DELETE FROM post_documents WHERE attachment_post_id = $1`, postID); err != nil {
+		return fmt.Errorf("clear attachments: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO post_documents (attachment_post_id, attachment_document_id, attachment_position)
+		SELECT $1, x.document_id, x.ord - 1
+		FROM UNNEST($2::int[]) WITH ORDINALITY AS x(document_id, ord);`, postID, pq.Array(ids)); err != nil {
+		return fmt.Errorf("link attachments: %w", err)
+	}
+	return nil
+}
+
+// เติม attachments ให้ทุกโพสต์ในหน้าด้วย query เดียว
+func (r *postRepository) loadAttachments(posts []models.PostResponse) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(posts))
+	index := make(map[int]int, len(posts))
+	for i := range posts {
+		ids[i] = int64(posts[i].PostID)
+		index[posts[i].PostID] = i
+		posts[i].Attachments = []models.Attachment{}
+	}
+
+	rows, err := r.db.Query(`
+		SELECT pd.attachment_post_id, d.document_id, COALESCE(d.document_name, ''),
+			d.document_url, d.document_kind, pd.attachment_position
+		FROM post_documents pd
+		JOIN documents d ON d.document_id = pd.attachment_document_id
+		WHERE pd.attachment_post_id = ANY($1::int[]) AND d.document_deleted_at IS NULL
+		ORDER BY pd.attachment_post_id, pd.attachment_position;`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("load attachments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			postID int
+			a      models.Attachment
+		)
+		if err := rows.Scan(&postID, &a.DocumentID, &a.Name, &a.FileURL, &a.Kind, &a.Position); err != nil {
+			return err
+		}
+		if i, ok := index[postID]; ok {
+			posts[i].Attachments = append(posts[i].Attachments, a)
+		}
+	}
+	return rows.Err()
+}
+
+// เติม attachments + reactions ของผู้ชม (viewerID = 0 → ไม่มี my_reactions)
+func (r *postRepository) loadDetails(viewerID int, posts []models.PostResponse) error {
+	if err := r.loadAttachments(posts); err != nil {
+		return err
+	}
+	return loadReactions(r.db, viewerID, posts)
+}
+
+// ย้ายโพสต์ลงถังขยะ (ลบจริงโดย PurgeTrash หลังครบ TrashRetentionDays)
 func (r *postRepository) DeletePost(postID int) error {
-	query := `DELETE FROM posts WHERE post_id = $1`
+	query := `UPDATE posts SET post_deleted_at = now() WHERE post_id = $1 AND post_deleted_at IS NULL`
 	res, err := r.db.Exec(query, postID)
 	if err != nil {
 		return fmt.Errorf("delete post: %w", err)
@@ -163,12 +339,153 @@ func (r *postRepository) DeletePost(postID int) error {
 	return nil
 }
 
-func (r *postRepository) GetAllPosts() ([]models.PostResponse, error) {
+func (r *postRepository) RestorePost(postID int) error {
+	res, err := r.db.Exec(`
+		UPDATE posts SET post_deleted_at = NULL
+		WHERE post_id = $1 AND post_deleted_at > now() - make_interval(days => $2);`,
+		postID, models.TrashRetentionDays)
+	if err != nil {
+		return fmt.Errorf("restore post: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrNotInTrash
+	}
+	return nil
+}
+
+// โพสต์ในถังขยะของผู้ใช้ ลบล่าสุดก่อน
+func (r *postRepository) GetTrashedPosts(userID int) ([]models.TrashedPost, error) {
+	rows, err := r.db.Query(`
+		SELECT post_id, post_title, post_deleted_at,
+			post_deleted_at + make_interval(days => $2) AS purge_at
+		FROM posts
+		WHERE post_author_user_id = $1
+		  AND post_deleted_at > now() - make_interval(days => $2)
+		ORDER BY post_deleted_at DESC, post_id DESC;`, userID, models.TrashRetentionDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.TrashedPost, 0)
+	for rows.Next() {
+		var t models.TrashedPost
+		if err := rows.Scan(&t.PostID, &t.Title, &t.DeletedAt, &t.PurgeAt); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// ลบโพสต์ที่อยู่ในถังขยะครบกำหนดแล้วจริง (ตารางลูก cascade ตาม FK)
+func (r *postRepository) PurgeTrash() (int, error) {
+	res, err := r.db.Exec(`
+		DELETE FROM posts
+		WHERE post_deleted_at <= now() - make_interval(days => $1);`, models.TrashRetentionDays)
+	if err != nil {
+		return 0, fmt.Errorf("purge trashed posts: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// ค่าจริงของตัวนับใน post_stats นับจากตารางต้นทาง (ps = แถวของ post_stats)
+const qActualStats = `
+	(SELECT COUNT(*) FROM likes WHERE like_post_id = ps.post_stats_post_id),
+	(SELECT COUNT(*) FROM saved_posts WHERE save_post_id = ps.post_stats_post_id),
+	(SELECT COUNT(*) FROM comments WHERE comment_post_id = ps.post_stats_post_id AND comment_hidden_at IS NULL),
+	(SELECT COUNT(*) FROM reposts WHERE repost_post_id = ps.post_stats_post_id),
+	(SELECT COALESCE(jsonb_object_agg(reaction_type, n), '{}'::jsonb) FROM (
+		SELECT reaction_type, COUNT(*) AS n FROM reactions
+		WHERE reaction_post_id = ps.post_stats_post_id GROUP BY reaction_type) rc)`
+
+// ซ่อมตัวนับใน post_stats (รวม post_reaction_counts) ที่ไม่ตรงกับตารางต้นทาง คืนจำนวนโพสต์ที่ถูกแก้
+// ล็อกแถวที่เพี้ยนก่อนแล้วนับใหม่ใน statement ถัดไป: การกดที่ commit แล้วจะถูกนับ
+// ส่วนการกดที่ค้างอยู่จะรอแถวนี้แล้วบวกตัวนับหลังเรา จึงไม่ทับกัน
+func (r *postRepository) ReconcileStats() (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO post_stats (post_stats_post_id)
+		SELECT p.post_id FROM posts p
+		WHERE NOT EXISTS (SELECT 1 FROM post_stats ps WHERE ps.post_stats_post_id = p.post_id)
+		ON CONFLICT DO NOTHING;`); err != nil {
+		return 0, fmt.Errorf("insert missing post_stats: %w", err)
+	}
+
+	rows, err := tx.Query(`
+		SELECT ps.post_stats_post_id
+		FROM post_stats ps
+		WHERE (COALESCE(ps.post_like_count, 0), COALESCE(ps.post_save_count, 0),
+			   COALESCE(ps.post_comment_count, 0), COALESCE(ps.post_repost_count, 0),
+			   -- ตัดแบบที่เป็น 0 ออกก่อนเทียบ (ยกเลิกจนเหลือ 0 ไม่ถือว่าเพี้ยน)
+			   (SELECT COALESCE(jsonb_object_agg(key, value), '{}'::jsonb)
+			    FROM jsonb_each(ps.post_reaction_counts) WHERE value <> '0'::jsonb))
+			IS DISTINCT FROM (` + qActualStats + `)
+		ORDER BY ps.post_stats_post_id
+		FOR UPDATE;`)
+	if err != nil {
+		return 0, fmt.Errorf("find drifted post_stats: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE post_stats ps
+		SET (post_like_count, post_save_count, post_comment_count, post_repost_count, post_reaction_counts) = (`+qActualStats+`)
+		WHERE ps.post_stats_post_id = ANY($1::int[]);`, pq.Array(ids)); err != nil {
+		return 0, fmt.Errorf("repair post_stats: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return len(ids), nil
+}
+
+// เงื่อนไข keyset: ดึงเฉพาะโพสต์ที่เก่ากว่า cursor (เรียง post_created_at, post_id จากใหม่ไปเก่า)
+// ใช้ร่วมกับ keysetArgs ส่ง cursor เป็น parameter คู่ (timestamptz, int) ที่อาจเป็น NULL (หน้าแรก)
+func keysetAfter(tsParam, idParam int) string {
+	return fmt.Sprintf(
+		`($%d::timestamptz IS NULL OR (p.post_created_at, p.post_id) < ($%d::timestamptz, $%d::int))`,
+		tsParam, tsParam, idParam)
+}
+
+func keysetArgs(after *pagination.Keyset) (interface{}, interface{}) {
+	if after == nil {
+		return nil, nil
+	}
+	return after.CreatedAt, after.ID
+}
+
+// ไม่มีผู้ชม จึงคืนเฉพาะโพสต์ public
+func (r *postRepository) GetAllPosts(after *pagination.Keyset, limit int) ([]models.PostResponse, error) {
 	query := `SELECT p.post_id, p.post_author_user_id, u.username AS author_name,
 		p.post_title, p.post_description, p.post_visibility,
-		p.post_document_id, p.post_created_at, p.post_updated_at,
+		p.post_document_id, p.post_created_at, p.post_updated_at, p.post_status, p.post_publish_at, p.post_audience_list_id, p.post_edited_at,
 		COALESCE(ps.post_like_count, 0) AS post_like_count,
 		COALESCE(ps.post_save_count, 0) AS post_save_count,
+		COALESCE(ps.post_comment_count, 0) AS post_comment_count,
+		COALESCE(ps.post_repost_count, 0) AS post_repost_count,
 		d.document_url AS document_file_url,
 		d.document_name AS document_name,
 		p.post_cover_url, up.avatar_url,
@@ -180,10 +497,13 @@ func (r *postRepository) GetAllPosts() ([]models.PostResponse, error) {
 	LEFT JOIN tags t ON t.tag_id = pt.post_tag_tag_id
 	LEFT JOIN documents d ON d.document_id = p.post_document_id
 	LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
-	GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count, d.document_url, d.document_name, p.post_cover_url, up.avatar_url
-	ORDER BY p.post_created_at DESC;`
+	WHERE p.post_visibility = 'public' AND ` + visibility.PostPublished + ` AND ` + keysetAfter(1, 2) + `
+	GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count, ps.post_comment_count, ps.post_repost_count, d.document_url, d.document_name, p.post_cover_url, up.avatar_url
+	ORDER BY p.post_created_at DESC, p.post_id DESC
+	LIMIT $3;`
 
-	rows, err := r.db.Query(query)
+	afterTS, afterID := keysetArgs(after)
+	rows, err := r.db.Query(query, afterTS, afterID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -204,8 +524,8 @@ func (r *postRepository) GetAllPosts() ([]models.PostResponse, error) {
 		if err := rows.Scan(
 			&p.PostID, &p.AuthorID, &p.AuthorName,
 			&p.Title, &p.Description, &p.Visibility,
-			&docID, &p.CreatedAt, &p.UpdatedAt,
-			&p.LikeCount, &p.SaveCount,
+			&docID, &p.CreatedAt, &p.UpdatedAt, &p.Status, &p.PublishAt, &p.AudienceID, &p.EditedAt,
+			&p.LikeCount, &p.SaveCount, &p.CommentCount, &p.RepostCount,
 			&fileURL, &docName, &coverURL, &avatarURL, &tags,
 		); err != nil {
 			return nil, err
@@ -229,126 +549,129 @@ func (r *postRepository) GetAllPosts() ([]models.PostResponse, error) {
 		}
 
 		p.Tags = []string(tags)
+		p.Edited = p.EditedAt != nil
 		posts = append(posts, p)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	if err := r.loadDetails(0, posts); err != nil {
+		return nil, err
+	}
 	return posts, nil
 }
 
-func (r *postRepository) GetFeedPosts(viewerID int) ([]models.PostResponse, error) {
+// รายการของ latest feed ใหม่→เก่า: โพสต์ที่ผู้ชมเห็นได้ + รีโพสต์จากคนที่ผู้ชมติดตาม
+// รีโพสต์ใช้เงื่อนไขการมองเห็นของโพสต์ต้นทางกับผู้ชม (รีโพสต์โพสต์ friends ไม่หลุดไปถึงคนที่ไม่ใช่เพื่อนผู้เขียน)
+// โพสต์หนึ่งขึ้นครั้งเดียวทั้งฟีด ที่เวลาล่าสุดของมัน: ถ้าคนที่ติดตามรีโพสต์ = รีโพสต์ล่าสุดแทนโพสต์ตรง ๆ
+// keyset บน (เวลาในฟีด, post_id, repost_id) (โพสต์ตรง ๆ ใช้ repost_id 0)
+func (r *postRepository) GetFeedEntries(viewerID int, after *models.FeedEntryCursor, limit int) ([]models.FeedEntry, error) {
 	query := `
-		SELECT p.post_id, p.post_author_user_id, u.username AS author_name,
-			p.post_title, p.post_description, p.post_visibility,
-			p.post_document_id, p.post_created_at, p.post_updated_at,
-			COALESCE(ps.post_like_count, 0) AS post_like_count,
-			COALESCE(ps.post_save_count, 0) AS post_save_count,
-			d.document_url AS document_file_url,
-			d.document_name AS document_name,
-			p.post_cover_url, up.avatar_url,
-			ARRAY_REMOVE(ARRAY_AGG(DISTINCT t.tag_name), NULL) AS tags,
-
-			EXISTS (
-				SELECT 1 FROM likes l
-				WHERE l.like_user_id = $1 AND l.like_post_id = p.post_id
-			) AS is_liked,
-			EXISTS (
-				SELECT 1 FROM saved_posts sp
-				WHERE sp.save_user_id = $1 AND sp.save_post_id = p.post_id
-			) AS is_saved
-
-		FROM posts p
-		JOIN users u ON u.user_id = p.post_author_user_id
-		LEFT JOIN post_stats ps ON ps.post_stats_post_id = p.post_id
-		LEFT JOIN post_tags pt ON pt.post_tag_post_id = p.post_id
-		LEFT JOIN tags t ON t.tag_id = pt.post_tag_tag_id
-		LEFT JOIN documents d ON d.document_id = p.post_document_id
-		LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
-		WHERE
-			p.post_author_user_id = $1
-			OR p.post_visibility = 'public'
-			OR ( p.post_visibility = 'friends'
-				AND EXISTS (
-					SELECT 1
-					FROM friendships f
-					WHERE
-						f.user_id  = LEAST(p.post_author_user_id, $1)
-						AND f.friend_id = GREATEST(p.post_author_user_id, $1)
+		WITH entries AS (
+			(
+				SELECT p.post_id, NULL::int AS repost_id, p.post_created_at AS sort_at
+				FROM posts p
+				WHERE ` + visibility.PostVisible + `
+				AND ` + visibility.PostPublished + `
+				AND NOT EXISTS (
+					SELECT 1 FROM reposts rp2
+					JOIN follows f2 ON f2.followed_user_id = rp2.repost_user_id AND f2.follower_user_id = $1
+					WHERE rp2.repost_post_id = p.post_id
 				)
+				AND ($2::timestamptz IS NULL OR (p.post_created_at, p.post_id, 0) < ($2::timestamptz, $3::int, $4::int))
+				ORDER BY p.post_created_at DESC, p.post_id DESC
+				LIMIT $5
 			)
-		GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count,
-				 d.document_url, d.document_name, p.post_cover_url, up.avatar_url
-		ORDER BY p.post_created_at DESC;
+			UNION ALL
+			(
+				SELECT p.post_id, rp.repost_id, rp.repost_created_at AS sort_at
+				FROM reposts rp
+				JOIN follows f ON f.followed_user_id = rp.repost_user_id AND f.follower_user_id = $1
+				JOIN posts p ON p.post_id = rp.repost_post_id
+				WHERE ` + visibility.PostVisible + `
+				AND ` + visibility.PostPublished + `
+				AND NOT EXISTS (
+					SELECT 1 FROM reposts rp2
+					JOIN follows f2 ON f2.followed_user_id = rp2.repost_user_id AND f2.follower_user_id = $1
+					WHERE rp2.repost_post_id = rp.repost_post_id
+					  AND (rp2.repost_created_at, rp2.repost_id) > (rp.repost_created_at, rp.repost_id)
+				)
+				AND ($2::timestamptz IS NULL
+					OR (rp.repost_created_at, p.post_id, rp.repost_id) < ($2::timestamptz, $3::int, $4::int))
+				ORDER BY rp.repost_created_at DESC, p.post_id DESC, rp.repost_id DESC
+				LIMIT $5
+			)
+		)
+		SELECT post_id, repost_id, sort_at
+		FROM entries
+		ORDER BY sort_at DESC, post_id DESC, COALESCE(repost_id, 0) DESC
+		LIMIT $5;
 	`
 
-	rows, err := r.db.Query(query, viewerID)
+	var afterTS, afterID, afterRepost interface{}
+	if after != nil {
+		afterTS, afterID, afterRepost = after.SortAt, after.PostID, after.RepostID
+	}
+	rows, err := r.db.Query(query, viewerID, afterTS, afterID, afterRepost, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var posts []models.PostResponse
+	out := make([]models.FeedEntry, 0, limit)
 	for rows.Next() {
 		var (
-			p         models.PostResponse
-			tags      pq.StringArray
-			fileURL   sql.NullString
-			docName   sql.NullString
-			coverURL  sql.NullString
-			avatarURL sql.NullString
-			docID     sql.NullInt64
-			isLiked   bool
-			isSaved   bool
+			e        models.FeedEntry
+			repostID sql.NullInt64
 		)
-		if err := rows.Scan(
-			&p.PostID, &p.AuthorID, &p.AuthorName,
-			&p.Title, &p.Description, &p.Visibility,
-			&docID, &p.CreatedAt, &p.UpdatedAt,
-			&p.LikeCount, &p.SaveCount,
-			&fileURL, &docName, &coverURL, &avatarURL, &tags,
-			&isLiked, &isSaved,
-		); err != nil {
+		if err := rows.Scan(&e.PostID, &repostID, &e.SortAt); err != nil {
 			return nil, err
 		}
-
-		if docID.Valid {
-			v := int(docID.Int64)
-			p.DocumentID = &v
-		}
-		if fileURL.Valid {
-			p.FileURL = &fileURL.String
-		}
-		if docName.Valid {
-			p.DocumentName = &docName.String
-		}
-		if coverURL.Valid {
-			p.CoverURL = &coverURL.String
-		}
-		if avatarURL.Valid {
-			p.AvatarURL = &avatarURL.String
+		if repostID.Valid {
+			v := int(repostID.Int64)
+			e.RepostID = &v
 		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
 
-		p.IsLiked = isLiked
-		p.IsSaved = isSaved
+// รายละเอียดรีโพสต์ (ผู้รีโพสต์ + quote) ตาม repost_id
+func (r *postRepository) GetRepostsByIDs(repostIDs []int) (map[int]models.Repost, error) {
+	out := make(map[int]models.Repost, len(repostIDs))
+	if len(repostIDs) == 0 {
+		return out, nil
+	}
+	ids := make([]int64, len(repostIDs))
+	for i, id := range repostIDs {
+		ids[i] = int64(id)
+	}
 
-		p.Tags = []string(tags)
-		posts = append(posts, p)
+	rows, err := r.db.Query(qRepostSelect+` WHERE rp.repost_id = ANY($1::int[]);`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("reposts by ids: %w", err)
 	}
+	defer rows.Close()
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	for rows.Next() {
+		rp, err := scanRepost(rows)
+		if err != nil {
+			return nil, err
+		}
+		out[rp.RepostID] = *rp
 	}
-	return posts, nil
+	return out, rows.Err()
 }
 
 func (r *postRepository) GetPostByID(postID int) (*models.PostResponse, error) {
 	query := `SELECT p.post_id, p.post_author_user_id, u.username AS author_name,
 		p.post_title, p.post_description, p.post_visibility, p.post_document_id,
-		p.post_created_at, p.post_updated_at,
+		p.post_created_at, p.post_updated_at, p.post_status, p.post_publish_at, p.post_audience_list_id, p.post_edited_at, p.post_hidden_at,
 		COALESCE(ps.post_like_count, 0)  AS post_like_count,
 		COALESCE(ps.post_save_count, 0)  AS post_save_count,
+		COALESCE(ps.post_comment_count, 0) AS post_comment_count,
+		COALESCE(ps.post_repost_count, 0) AS post_repost_count,
 		d.document_url AS document_file_url,
 		d.document_name AS document_name,
 		p.post_cover_url, up.avatar_url,
@@ -360,8 +683,8 @@ func (r *postRepository) GetPostByID(postID int) (*models.PostResponse, error) {
 	LEFT JOIN tags t ON t.tag_id = pt.post_tag_tag_id
 	LEFT JOIN documents d ON d.document_id = p.post_document_id
 	LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
-	WHERE p.post_id = $1
-	GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count, d.document_url, d.document_name, up.avatar_url;`
+	WHERE p.post_id = $1 AND ` + visibility.PostNotTrashed + `
+	GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count, ps.post_comment_count, ps.post_repost_count, d.document_url, d.document_name, up.avatar_url;`
 
 	row := r.db.QueryRow(query, postID)
 	var (
@@ -377,8 +700,8 @@ func (r *postRepository) GetPostByID(postID int) (*models.PostResponse, error) {
 	if err := row.Scan(
 		&p.PostID, &p.AuthorID, &p.AuthorName,
 		&p.Title, &p.Description, &p.Visibility,
-		&docID, &p.CreatedAt, &p.UpdatedAt,
-		&p.LikeCount, &p.SaveCount,
+		&docID, &p.CreatedAt, &p.UpdatedAt, &p.Status, &p.PublishAt, &p.AudienceID, &p.EditedAt, &p.HiddenAt,
+		&p.LikeCount, &p.SaveCount, &p.CommentCount, &p.RepostCount,
 		&fileURL, &docName, &coverURL, &avatarURL, &tags,
 	); err != nil {
 		if err == sql.ErrNoRows {
@@ -404,16 +727,24 @@ func (r *postRepository) GetPostByID(postID int) (*models.PostResponse, error) {
 	}
 
 	p.Tags = []string(tags)
-	return &p, nil
+	p.Edited = p.EditedAt != nil
+
+	list := []models.PostResponse{p}
+	if err := r.loadDetails(0, list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
 }
 
 func (r *postRepository) GetPostByIDForViewer(viewerID, postID int) (*models.PostResponse, error) {
 	query := `
 	SELECT p.post_id, p.post_author_user_id, u.username AS author_name,
 		p.post_title, p.post_description, p.post_visibility, p.post_document_id,
-		p.post_created_at, p.post_updated_at,
+		p.post_created_at, p.post_updated_at, p.post_status, p.post_publish_at, p.post_audience_list_id, p.post_edited_at, p.post_hidden_at,
 		COALESCE(ps.post_like_count, 0) AS post_like_count,
 		COALESCE(ps.post_save_count, 0) AS post_save_count,
+		COALESCE(ps.post_comment_count, 0) AS post_comment_count,
+		COALESCE(ps.post_repost_count, 0) AS post_repost_count,
 		d.document_url AS document_file_url,
 		d.document_name AS document_name,
 		p.post_cover_url, up.avatar_url,
@@ -435,8 +766,8 @@ func (r *postRepository) GetPostByIDForViewer(viewerID, postID int) (*models.Pos
 	LEFT JOIN tags t ON t.tag_id = pt.post_tag_tag_id
 	LEFT JOIN documents d ON d.document_id = p.post_document_id
 	LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
-	WHERE p.post_id = $2
-	GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count,
+	WHERE p.post_id = $2 AND ` + visibility.PostNotTrashed + `
+	GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count, ps.post_comment_count, ps.post_repost_count,
 			 d.document_url, d.document_name, p.post_cover_url, up.avatar_url;
 	`
 
@@ -457,8 +788,8 @@ func (r *postRepository) GetPostByIDForViewer(viewerID, postID int) (*models.Pos
 	if err := row.Scan(
 		&p.PostID, &p.AuthorID, &p.AuthorName,
 		&p.Title, &p.Description, &p.Visibility,
-		&docID, &p.CreatedAt, &p.UpdatedAt,
-		&p.LikeCount, &p.SaveCount,
+		&docID, &p.CreatedAt, &p.UpdatedAt, &p.Status, &p.PublishAt, &p.AudienceID, &p.EditedAt, &p.HiddenAt,
+		&p.LikeCount, &p.SaveCount, &p.CommentCount, &p.RepostCount,
 		&fileURL, &docName, &coverURL, &avatarURL, &tags,
 		&isLiked, &isSaved,
 	); err != nil {
@@ -483,9 +814,15 @@ func (r *postRepository) GetPostByIDForViewer(viewerID, postID int) (*models.Pos
 	}
 
 	p.Tags = []string(tags)
+	p.Edited = p.EditedAt != nil
 	p.IsLiked = isLiked
 	p.IsSaved = isSaved
-	return &p, nil
+
+	list := []models.PostResponse{p}
+	if err := r.loadDetails(viewerID, list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
 }
 
 func (r *postRepository) GetPostOwnerID(postID int) (int, error) {
@@ -497,25 +834,179 @@ func (r *postRepository) GetPostOwnerID(postID int) (int, error) {
 	return owner, nil
 }
 
+// audience list ที่ถูกลบไปแล้ว = private (เหมือนตอนลบ list), ไฟล์แนบที่ถูกลบไปแล้วถูกตัดออก
+const qRevisionSelect = `
+	SELECT r.revision_id, r.revision_post_id, r.revision_number,
+		r.revision_title, COALESCE(r.revision_description, ''),
+		CASE WHEN r.revision_visibility = 'list' AND l.list_id IS NULL THEN 'private' ELSE r.revision_visibility END,
+		l.list_id, r.revision_tags,
+		ARRAY(
+			SELECT u.doc_id FROM unnest(r.revision_attachment_ids) WITH ORDINALITY AS u(doc_id, pos)
+			WHERE EXISTS (SELECT 1 FROM documents d WHERE d.document_id = u.doc_id AND d.document_deleted_at IS NULL)
+			ORDER BY u.pos
+		),
+		r.revision_created_at
+	FROM post_revisions r
+	LEFT JOIN audience_lists l ON l.list_id = r.revision_audience_list_id
+`
+
+func scanRevision(row interface{ Scan(...any) error }) (*models.PostRevision, error) {
+	var (
+		rev         models.PostRevision
+		audienceID  sql.NullInt64
+		tags        pq.StringArray
+		attachments pq.Int64Array
+	)
+	if err := row.Scan(&rev.RevisionID, &rev.PostID, &rev.Number,
+		&rev.Title, &rev.Description, &rev.Visibility,
+		&audienceID, &tags, &attachments, &rev.CreatedAt); err != nil {
+		return nil, err
+	}
+	if audienceID.Valid {
+		v := int(audienceID.Int64)
+		rev.AudienceID = &v
+	}
+	rev.Tags = []string(tags)
+	rev.AttachmentIDs = make([]int, len(attachments))
+	for i, id := range attachments {
+		rev.AttachmentIDs[i] = int(id)
+	}
+	return &rev, nil
+}
+
+// ใหม่→เก่า
+func (r *postRepository) ListRevisions(postID int) ([]models.PostRevision, error) {
+	rows, err := r.db.Query(qRevisionSelect+`
+		WHERE r.revision_post_id = $1
+		ORDER BY r.revision_number DESC;`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.PostRevision, 0)
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *rev)
+	}
+	return out, rows.Err()
+}
+
+func (r *postRepository) GetRevision(postID, revisionID int) (*models.PostRevision, error) {
+	rev, err := scanRevision(r.db.QueryRow(qRevisionSelect+`
+		WHERE r.revision_post_id = $1 AND r.revision_id = $2;`, postID, revisionID))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+func (r *postRepository) GetAudienceListOwner(listID int) (int, error) {
+	var owner int
+	err := r.db.QueryRow(`SELECT list_owner_user_id FROM audience_lists WHERE list_id = $1`, listID).Scan(&owner)
+	return owner, err
+}
+
+func (r *postRepository) IsAudienceMember(listID, userID int) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM audience_list_members
+			WHERE member_list_id = $1 AND member_user_id = $2
+		);`, listID, userID).Scan(&ok)
+	return ok, err
+}
+
 func (r *postRepository) CountByUserID(userID int) (int, error) {
 	var cnt int
-	err := r.db.QueryRow(`SELECT COUNT(*) FROM posts WHERE post_author_user_id = $1`, userID).Scan(&cnt)
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM posts WHERE post_author_user_id = $1 AND post_status = 'published' AND post_deleted_at IS NULL`, userID).Scan(&cnt)
 	return cnt, err
 }
 
-func (r *postRepository) GetSavedPosts(userID int) ([]models.PostResponse, error) {
+// draft + scheduled ของผู้เขียน เรียงใหม่→เก่าตามเวลาสร้าง
+func (r *postRepository) GetDraftPosts(userID int, after *pagination.Keyset, limit int) ([]models.PostResponse, error) {
 	query := `
-        SELECT p.post_id, p.post_author_user_id, u.username AS author_name,
-               p.post_title, p.post_description, p.post_visibility,
-               p.post_document_id, p.post_created_at, p.post_updated_at,
-               COALESCE(ps.post_like_count, 0) AS post_like_count,
-               COALESCE(ps.post_save_count, 0) AS post_save_count,
-               d.document_url AS document_file_url,
-			   d.document_name AS document_name,
-               p.post_cover_url, up.avatar_url,
-               ARRAY_REMOVE(ARRAY_AGG(DISTINCT t.tag_name), NULL) AS tags,
-
-			   EXISTS (
+		SELECT p.post_id, p.post_author_user_id, u.username AS author_name,
+			p.post_title, p.post_description, p.post_visibility,
+			p.post_document_id, p.post_created_at, p.post_updated_at, p.post_status, p.post_publish_at, p.post_audience_list_id, p.post_edited_at,
+			COALESCE(ps.post_like_count, 0) AS post_like_count,
+			COALESCE(ps.post_save_count, 0) AS post_save_count,
+			COALESCE(ps.post_comment_count, 0) AS post_comment_count,
+			COALESCE(ps.post_repost_count, 0) AS post_repost_count,
+			d.document_url AS document_file_url,
+			d.document_name AS document_name,
+			p.post_cover_url, up.avatar_url,
+			ARRAY_REMOVE(ARRAY_AGG(DISTINCT t.tag_name), NULL) AS tags,
+			FALSE AS is_liked,
+			FALSE AS is_saved
+		FROM posts p
+		JOIN users u ON u.user_id = p.post_author_user_id
+		LEFT JOIN post_stats ps ON ps.post_stats_post_id = p.post_id
+		LEFT JOIN post_tags pt ON pt.post_tag_post_id = p.post_id
+		LEFT JOIN tags t ON t.tag_id = pt.post_tag_tag_id
+		LEFT JOIN documents d ON d.document_id = p.post_document_id
+		LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
+		WHERE p.post_author_user_id = $1
+		  AND p.post_status <> 'published'
+		  AND ` + visibility.PostNotTrashed + `
+		  AND ` + keysetAfter(2, 3) + `
+		GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count, ps.post_comment_count, ps.post_repost_count,
+				 d.document_url, d.document_name, p.post_cover_url, up.avatar_url
+		ORDER BY p.post_created_at DESC, p.post_id DESC
+		LIMIT $4;
+	`
+
+	afterTS, afterID := keysetArgs(after)
+	rows, err := r.db.Query(query, userID, afterTS, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts, err := scanViewerPosts(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadDetails(userID, posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// เปลี่ยนโพสต์ scheduled ที่ถึงเวลาแล้วเป็น published
+// post_created_at = เวลาที่ตั้งไว้ เพื่อให้เรียงใน feed ตามเวลาเผยแพร่จริง
+func (r *postRepository) PublishDue() (int, error) {
+	res, err := r.db.Exec(`
+		UPDATE posts
+		SET post_status = 'published',
+			post_created_at = post_publish_at,
+			post_updated_at = now()
+		WHERE post_status = 'scheduled' AND post_publish_at <= now();`)
+	if err != nil {
+		return 0, fmt.Errorf("publish scheduled posts: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+func (r *postRepository) GetSavedPosts(userID int, after *pagination.Keyset, limit int) ([]models.PostResponse, error) {
+	query := `
+        SELECT p.post_id, p.post_author_user_id, u.username AS author_name,
+               p.post_title, p.post_description, p.post_visibility,
+               p.post_document_id, p.post_created_at, p.post_updated_at, p.post_status, p.post_publish_at, p.post_audience_list_id, p.post_edited_at,
+               COALESCE(ps.post_like_count, 0) AS post_like_count,
+               COALESCE(ps.post_save_count, 0) AS post_save_count,
+               COALESCE(ps.post_comment_count, 0) AS post_comment_count,
+               COALESCE(ps.post_repost_count, 0) AS post_repost_count,
+               d.document_url AS document_file_url,
+			   d.document_name AS document_name,
+               p.post_cover_url, up.avatar_url,
+               ARRAY_REMOVE(ARRAY_AGG(DISTINCT t.tag_name), NULL) AS tags,
+
+			   EXISTS (
 					SELECT 1 FROM likes l
 					WHERE l.like_user_id = $1 AND l.like_post_id = p.post_id
 			   ) AS is_liked,
@@ -533,89 +1024,57 @@ func (r *postRepository) GetSavedPosts(userID int) ([]models.PostResponse, error
         LEFT JOIN documents d ON d.document_id = p.post_document_id
         LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
         WHERE sp.save_user_id = $1
-          AND (
-              p.post_author_user_id = $1
-              OR p.post_visibility = 'public'
-              OR ( p.post_visibility = 'friends'
-                AND EXISTS (
-                    SELECT 1
-                    FROM friendships f
-                    WHERE
-                        f.user_id  = LEAST(p.post_author_user_id, $1)
-                        AND f.friend_id = GREATEST(p.post_author_user_id, $1)
-                )
-              )
-          )
-        GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count,
+          AND ` + visibility.PostVisible + `
+          AND ` + visibility.PostPublished + `
+          AND ` + keysetAfter(2, 3) + `
+        GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count, ps.post_comment_count, ps.post_repost_count,
                  d.document_url, d.document_name, p.post_cover_url, up.avatar_url
-        ORDER BY p.post_created_at DESC;
+        ORDER BY p.post_created_at DESC, p.post_id DESC
+        LIMIT $4;
     `
 
-	rows, err := r.db.Query(query, userID)
+	afterTS, afterID := keysetArgs(after)
+	rows, err := r.db.Query(query, userID, afterTS, afterID, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var posts []models.PostResponse
-	for rows.Next() {
-		var (
-			p         models.PostResponse
-			tags      pq.StringArray
-			fileURL   sql.NullString
-			docName   sql.NullString
-			coverURL  sql.NullString
-			avatarURL sql.NullString
-			docID     sql.NullInt64
-			isLiked   bool
-			isSaved   bool
-		)
-
-		if err := rows.Scan(
-			&p.PostID, &p.AuthorID, &p.AuthorName,
-			&p.Title, &p.Description, &p.Visibility,
-			&docID, &p.CreatedAt, &p.UpdatedAt,
-			&p.LikeCount, &p.SaveCount,
-			&fileURL, &docName, &coverURL, &avatarURL, &tags,
-			&isLiked, &isSaved,
-		); err != nil {
-			return nil, err
-		}
-		if docID.Valid {
-			v := int(docID.Int64)
-			p.DocumentID = &v
-		}
-		if fileURL.Valid {
-			p.FileURL = &fileURL.String
-		}
-		if docName.Valid {
-			p.DocumentName = &docName.String
-		}
-		if coverURL.Valid {
-			p.CoverURL = &coverURL.String
-		}
-		if avatarURL.Valid {
-			p.AvatarURL = &avatarURL.String
-		}
-		p.IsLiked = isLiked
-		p.IsSaved = isSaved
-		p.Tags = []string(tags)
-		posts = append(posts, p)
+	posts, err := scanViewerPosts(rows)
+	if err != nil {
+		return nil, err
 	}
-
-	if err := rows.Err(); err != nil {
+	if err := r.loadDetails(userID, posts); err != nil {
 		return nil, err
 	}
 	return posts, nil
 }
 
-func (r *postRepository) GetPopularPosts(viewerID, limit int) ([]models.PostResponse, error) {
+// คืน key ของแต่ละโพสต์คู่กันไว้สร้าง cursor ($2, $3 = keyset ของหน้าก่อน)
+func (r *postRepository) GetPopularPosts(viewerID int, orderBy string, after *models.PopularCursor, limit int) ([]models.PostResponse, []models.PopularCursor, error) {
+	score, scoreType := `COALESCE(ps.post_like_count, 0)`, "int"
+	switch orderBy {
+	case models.PopularBySaves:
+		score = `COALESCE(ps.post_save_count, 0)`
+	case models.PopularByRecent:
+		score, scoreType = `COALESCE(ps.post_last_activity_at, p.post_created_at)`, "timestamptz"
+	}
+	var afterScore, afterID interface{}
+	if after != nil {
+		afterScore, afterID = after.Count, after.ID
+		if orderBy == models.PopularByRecent {
+			afterScore = after.At
+		}
+	}
+
 	query := `
 		SELECT p.post_id, p.post_author_user_id, u.username AS author_name,
 			p.post_title, p.post_description, p.post_visibility,
-			p.post_document_id, p.post_created_at, p.post_updated_at,
+			p.post_document_id, p.post_created_at, p.post_updated_at, p.post_status, p.post_publish_at, p.post_audience_list_id, p.post_edited_at,
 			COALESCE(ps.post_like_count, 0) AS post_like_count,
 			COALESCE(ps.post_save_count, 0) AS post_save_count,
+			COALESCE(ps.post_comment_count, 0) AS post_comment_count,
+			COALESCE(ps.post_repost_count, 0) AS post_repost_count,
 			d.document_url AS document_file_url,
 			d.document_name AS document_name,
 			p.post_cover_url, up.avatar_url,
@@ -629,7 +1088,8 @@ func (r *postRepository) GetPopularPosts(viewerID, limit int) ([]models.PostResp
 			EXISTS (
 				SELECT 1 FROM saved_posts sp
 				WHERE sp.save_user_id = $1 AND sp.save_post_id = p.post_id
-			) AS is_saved
+			) AS is_saved,
+			COALESCE(ps.post_last_activity_at, p.post_created_at) AS activity_at
 
 		FROM posts p
 		JOIN users u ON u.user_id = p.post_author_user_id
@@ -638,21 +1098,26 @@ func (r *postRepository) GetPopularPosts(viewerID, limit int) ([]models.PostResp
 		LEFT JOIN tags t ON t.tag_id = pt.post_tag_tag_id
 		LEFT JOIN documents d ON d.document_id = p.post_document_id
 		LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
-		WHERE p.post_visibility = 'public'
-		GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count,
-				 d.document_url, d.document_name, p.post_cover_url, up.avatar_url
-
-		ORDER BY COALESCE(ps.post_like_count, 0) DESC, p.post_created_at DESC
-		LIMIT $2;
+		WHERE p.post_visibility = 'public' AND ` + visibility.PostPublished + `
+			AND ($2::` + scoreType + ` IS NULL OR (` + score + `, p.post_id) < ($2::` + scoreType + `, $3::int))
+		GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count, ps.post_comment_count, ps.post_repost_count,
+				 d.document_url, d.document_name, p.post_cover_url, up.avatar_url,
+				 ps.post_last_activity_at
+
+		ORDER BY ` + score + ` DESC, p.post_id DESC
+		LIMIT $4;
 	`
 
-	rows, err := r.db.Query(query, viewerID, limit)
+	rows, err := r.db.Query(query, viewerID, afterScore, afterID, limit)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	var posts []models.PostResponse
+	var (
+		posts []models.PostResponse
+		keys  []models.PopularCursor
+	)
 	for rows.Next() {
 		var (
 			p         models.PostResponse
@@ -664,17 +1129,18 @@ func (r *postRepository) GetPopularPosts(viewerID, limit int) ([]models.PostResp
 			docID     sql.NullInt64
 			isLiked   bool
 			isSaved   bool
+			key       = models.PopularCursor{Sort: orderBy}
 		)
 
 		if err := rows.Scan(
 			&p.PostID, &p.AuthorID, &p.AuthorName,
 			&p.Title, &p.Description, &p.Visibility,
-			&docID, &p.CreatedAt, &p.UpdatedAt,
-			&p.LikeCount, &p.SaveCount,
+			&docID, &p.CreatedAt, &p.UpdatedAt, &p.Status, &p.PublishAt, &p.AudienceID, &p.EditedAt,
+			&p.LikeCount, &p.SaveCount, &p.CommentCount, &p.RepostCount,
 			&fileURL, &docName, &coverURL, &avatarURL, &tags,
-			&isLiked, &isSaved,
+			&isLiked, &isSaved, &key.At,
 		); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		if docID.Valid {
@@ -695,49 +1161,34 @@ func (r *postRepository) GetPopularPosts(viewerID, limit int) ([]models.PostResp
 		}
 
 		p.Tags = []string(tags)
+		p.Edited = p.EditedAt != nil
 		p.IsLiked = isLiked
 		p.IsSaved = isSaved
 
+		key.ID = p.PostID
+		switch orderBy {
+		case models.PopularByLikes:
+			key.Count = p.LikeCount
+		case models.PopularBySaves:
+			key.Count = p.SaveCount
+		}
 		posts = append(posts, p)
+		keys = append(keys, key)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return posts, nil
-}
-
-func (r *postRepository) SearchPosts(viewerID int, search string, page, size int) ([]models.PostResponse, int, error) {
-	if page < 1 {
-		page = 1
+		return nil, nil, err
 	}
-	if size <= 0 || size > 100 {
-		size = 20
+	if err := r.loadDetails(viewerID, posts); err != nil {
+		return nil, nil, err
 	}
+	return posts, keys, nil
+}
 
-	search = strings.TrimSpace(search)
-	pattern := "%" + search + "%"
-	offset := (page - 1) * size
-
-	// count post for page
-	countQ := `
-		SELECT COUNT(DISTINCT p.post_id)
-		FROM posts p
-		WHERE
-			(
-				p.post_author_user_id = $1
-				OR p.post_visibility = 'public'
-				OR (
-					p.post_visibility = 'friends'
-					AND EXISTS (
-						SELECT 1
-						FROM friendships f
-						WHERE
-							f.user_id = LEAST(p.post_author_user_id, $1)
-							AND f.friend_id = GREATEST(p.post_author_user_id, $1)
-					)
-				)
-			)
+// เงื่อนไขค้นหาที่ใช้ร่วมกันระหว่าง count และ list ($1 = viewer, $2 = search, $3 = pattern)
+const qSearchWhere = `
+			` + visibility.PostVisible + `
+			AND ` + visibility.PostPublished + `
 			AND (
 				$2 = ''
 				OR p.post_title ILIKE $3
@@ -748,21 +1199,81 @@ func (r *postRepository) SearchPosts(viewerID int, search string, page, size int
 					WHERE pt2.post_tag_post_id = p.post_id
 					  AND t2.tag_name ILIKE $3
 				)
-			);
-	`
+			)`
+
+func (r *postRepository) CountSearchPosts(viewerID int, search string) (int, error) {
+	search = strings.TrimSpace(search)
+	pattern := "%" + search + "%"
+
+	countQ := `
+		SELECT COUNT(DISTINCT p.post_id)
+		FROM posts p
+		WHERE` + qSearchWhere + `;`
 
 	var total int
 	if err := r.db.QueryRow(countQ, viewerID, search, pattern).Scan(&total); err != nil {
-		return nil, 0, fmt.Errorf("count search feed: %w", err)
+		return 0, fmt.Errorf("count search feed: %w", err)
+	}
+	return total, nil
+}
+
+// คะแนน relevance: ชื่อตรง 2, แท็กตรงทั้งคำ 2, แท็กตรงบางส่วน 1 + ยอด like/save เล็กน้อยเป็นตัวตัดสิน
+const qSearchRelevance = `(
+			(p.post_title ILIKE $3)::int * 2
+			+ (EXISTS (
+				SELECT 1 FROM post_tags pt3 JOIN tags t3 ON t3.tag_id = pt3.post_tag_tag_id
+				WHERE pt3.post_tag_post_id = p.post_id AND t3.tag_name = lower($2)
+			))::int * 2
+			+ (EXISTS (
+				SELECT 1 FROM post_tags pt3 JOIN tags t3 ON t3.tag_id = pt3.post_tag_tag_id
+				WHERE pt3.post_tag_post_id = p.post_id AND t3.tag_name ILIKE $3
+			))::int
+			+ ln(1 + COALESCE(ps.post_like_count, 0) + 2 * COALESCE(ps.post_save_count, 0)) * 0.1
+		)::float8`
+
+// คืนคะแนนความเกี่ยวข้องของแต่ละโพสต์คู่กันไว้สร้าง cursor (เฉพาะ relevance, recent = nil)
+func (r *postRepository) SearchPosts(viewerID int, search, orderBy string, after *models.SearchCursor, limit int) ([]models.PostResponse, []float64, error) {
+	search = strings.TrimSpace(search)
+	pattern := "%" + search + "%"
+
+	var afterScore, afterTS, afterID interface{}
+	if after != nil {
+		afterScore, afterTS, afterID = after.Score, after.CreatedAt, after.ID
+	}
+
+	// recent = keyset ($4, $5), relevance = keyset ($4 score, $5, $6)
+	relevance := orderBy == models.SearchByRelevance
+	score := ``
+	page := `
+			AND ` + keysetAfter(4, 5) + `
+
+		GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count, ps.post_comment_count, ps.post_repost_count,
+				 d.document_url, d.document_name, p.post_cover_url, up.avatar_url
+		ORDER BY p.post_created_at DESC, p.post_id DESC
+		LIMIT $6;`
+	args := []any{viewerID, search, pattern, afterTS, afterID, limit}
+	if relevance {
+		score = `,
+			` + qSearchRelevance + ` AS relevance`
+		page = `
+			AND ($4::float8 IS NULL OR (` + qSearchRelevance + `, p.post_created_at, p.post_id)
+				< ($4::float8, $5::timestamptz, $6::int))
+
+		GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count, ps.post_comment_count, ps.post_repost_count,
+				 d.document_url, d.document_name, p.post_cover_url, up.avatar_url
+		ORDER BY relevance DESC, p.post_created_at DESC, p.post_id DESC
+		LIMIT $7;`
+		args = []any{viewerID, search, pattern, afterScore, afterTS, afterID, limit}
 	}
 
-	// list query post
 	listQ := `
 		SELECT p.post_id, p.post_author_user_id, u.username AS author_name,
 			p.post_title, p.post_description, p.post_visibility,
-			p.post_document_id, p.post_created_at, p.post_updated_at,
+			p.post_document_id, p.post_created_at, p.post_updated_at, p.post_status, p.post_publish_at, p.post_audience_list_id, p.post_edited_at,
 			COALESCE(ps.post_like_count, 0) AS post_like_count,
 			COALESCE(ps.post_save_count, 0) AS post_save_count,
+			COALESCE(ps.post_comment_count, 0) AS post_comment_count,
+			COALESCE(ps.post_repost_count, 0) AS post_repost_count,
 			d.document_url AS document_file_url,
 			d.document_name AS document_name,
 			p.post_cover_url, up.avatar_url,
@@ -775,7 +1286,7 @@ func (r *postRepository) SearchPosts(viewerID int, search string, page, size int
 			EXISTS (
 				SELECT 1 FROM saved_posts sp
 				WHERE sp.save_user_id = $1 AND sp.save_post_id = p.post_id
-			) AS is_saved
+			) AS is_saved` + score + `
 
 		FROM posts p
 		JOIN users u ON u.user_id = p.post_author_user_id
@@ -785,97 +1296,289 @@ func (r *postRepository) SearchPosts(viewerID int, search string, page, size int
 		LEFT JOIN documents d ON d.document_id = p.post_document_id
 		LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
 
-		WHERE
-			(
-				p.post_author_user_id = $1
-				OR p.post_visibility = 'public'
-				OR (
-					p.post_visibility = 'friends'
-					AND EXISTS (
-						SELECT 1
-						FROM friendships f
-						WHERE
-							f.user_id = LEAST(p.post_author_user_id, $1)
-							AND f.friend_id = GREATEST(p.post_author_user_id, $1)
-					)
-				)
+		WHERE` + qSearchWhere + page
+
+	rows, err := r.db.Query(listQ, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("search feed: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		posts  = make([]models.PostResponse, 0, limit)
+		scores []float64
+	)
+	for rows.Next() {
+		var (
+			p   models.PostResponse
+			err error
+		)
+		if relevance {
+			var rel float64
+			p, err = scanViewerPost(rows, &rel)
+			scores = append(scores, rel)
+		} else {
+			p, err = scanViewerPost(rows)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		posts = append(posts, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if err := r.loadDetails(viewerID, posts); err != nil {
+		return nil, nil, err
+	}
+	return posts, scores, nil
+}
+
+// น้ำหนักคะแนนความคล้ายของ similar posts
+const (
+	similarStyleWeight   = 0.3
+	similarContentWeight = 0.5
+	similarTagWeight     = 0.2
+
+	// จำนวนผู้สมัครต่อแหล่ง (เพื่อนบ้านใกล้สุดตาม content / style vector และโพสต์ที่มีแท็กร่วม)
+	similarCandidates = 200
+)
+
+func (r *postRepository) GetSimilarPosts(viewerID, postID, limit int) ([]models.PostResponse, error) {
+	query := `
+		WITH src AS (
+			SELECT df.style_vector_v16 AS style_vec, df.content_embedding AS content_vec
+			FROM posts p
+			LEFT JOIN document_features df
+				ON df.document_id = p.post_document_id AND df.feature_status = 'done'
+			WHERE p.post_id = $2
+		),
+		src_tags AS (
+			SELECT post_tag_tag_id AS tag_id FROM post_tags WHERE post_tag_post_id = $2
+		),
+		-- คัดผู้สมัครก่อนด้วย ORDER BY ระยะ <=> LIMIT (ใช้ HNSW index ได้) แล้วค่อยให้คะแนนรวม
+		-- ไม่ต้องคำนวณทุกโพสต์ต่อการเปิดหน้าโพสต์หนึ่งครั้ง
+		cand AS (
+			SELECT p.post_id FROM posts p
+			WHERE p.post_document_id IN (
+				SELECT df.document_id FROM document_features df
+				WHERE df.content_embedding IS NOT NULL AND df.feature_status = 'done'
+				  AND (SELECT content_vec FROM src) IS NOT NULL
+				ORDER BY df.content_embedding <=> (SELECT content_vec FROM src)
+				LIMIT $7
 			)
-			AND (
-				$2 = ''
-				OR p.post_title ILIKE $3
-				OR EXISTS (
-					SELECT 1
-					FROM post_tags pt2
-					JOIN tags t2 ON t2.tag_id = pt2.post_tag_tag_id
-					WHERE pt2.post_tag_post_id = p.post_id
-					  AND t2.tag_name ILIKE $3
-				)
+			UNION
+			SELECT p.post_id FROM posts p
+			WHERE p.post_document_id IN (
+				SELECT df.document_id FROM document_features df
+				WHERE df.style_vector_v16 IS NOT NULL AND df.feature_status = 'done'
+				  AND (SELECT style_vec FROM src) IS NOT NULL
+				ORDER BY df.style_vector_v16 <=> (SELECT style_vec FROM src)
+				LIMIT $7
 			)
+			UNION
+			(SELECT DISTINCT pt.post_tag_post_id FROM post_tags pt
+			WHERE pt.post_tag_tag_id IN (SELECT tag_id FROM src_tags)
+			ORDER BY pt.post_tag_post_id DESC
+			LIMIT $7)
+		),
+		scored AS (
+			SELECT p.post_id,
+				COALESCE(1 - (df.style_vector_v16 <=> src.style_vec), 0) * $4
+				+ COALESCE(1 - (df.content_embedding <=> src.content_vec), 0) * $5
+				+ (
+					SELECT COUNT(*) FROM post_tags pt2
+					WHERE pt2.post_tag_post_id = p.post_id
+					  AND pt2.post_tag_tag_id IN (SELECT tag_id FROM src_tags)
+				)::float8 / GREATEST((SELECT COUNT(*) FROM src_tags), 1) * $6 AS score
+			FROM cand
+			JOIN posts p ON p.post_id = cand.post_id
+			CROSS JOIN src
+			LEFT JOIN document_features df
+				ON df.document_id = p.post_document_id AND df.feature_status = 'done'
+			WHERE p.post_id <> $2
+			  AND ` + visibility.PostPublished + `
+			  AND ` + visibility.PostVisible + `
+		)
+		SELECT p.post_id, p.post_author_user_id, u.username AS author_name,
+			p.post_title, p.post_description, p.post_visibility,
+			p.post_document_id, p.post_created_at, p.post_updated_at, p.post_status, p.post_publish_at, p.post_audience_list_id, p.post_edited_at,
+			COALESCE(ps.post_like_count, 0) AS post_like_count,
+			COALESCE(ps.post_save_count, 0) AS post_save_count,
+			COALESCE(ps.post_comment_count, 0) AS post_comment_count,
+			COALESCE(ps.post_repost_count, 0) AS post_repost_count,
+			d.document_url AS document_file_url,
+			d.document_name AS document_name,
+			p.post_cover_url, up.avatar_url,
+			ARRAY_REMOVE(ARRAY_AGG(DISTINCT t.tag_name), NULL) AS tags,
+
+			EXISTS (
+				SELECT 1 FROM likes l
+				WHERE l.like_user_id = $1 AND l.like_post_id = p.post_id
+			) AS is_liked,
+			EXISTS (
+				SELECT 1 FROM saved_posts sp
+				WHERE sp.save_user_id = $1 AND sp.save_post_id = p.post_id
+			) AS is_saved
 
-		GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count,
+		FROM scored s
+		JOIN posts p ON p.post_id = s.post_id
+		JOIN users u ON u.user_id = p.post_author_user_id
+		LEFT JOIN post_stats ps ON ps.post_stats_post_id = p.post_id
+		LEFT JOIN post_tags pt ON pt.post_tag_post_id = p.post_id
+		LEFT JOIN tags t ON t.tag_id = pt.post_tag_tag_id
+		LEFT JOIN documents d ON d.document_id = p.post_document_id
+		LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
+		WHERE s.score > 0
+		GROUP BY p.post_id, s.score, u.username, ps.post_like_count, ps.post_save_count, ps.post_comment_count, ps.post_repost_count,
 				 d.document_url, d.document_name, p.post_cover_url, up.avatar_url
-		ORDER BY p.post_created_at DESC
-		LIMIT $4 OFFSET $5;
+		ORDER BY s.score DESC, p.post_created_at DESC
+		LIMIT $3;
 	`
 
-	rows, err := r.db.Query(listQ, viewerID, search, pattern, size, offset)
+	rows, err := r.db.Query(query, viewerID, postID, limit,
+		similarStyleWeight, similarContentWeight, similarTagWeight, similarCandidates)
 	if err != nil {
-		return nil, 0, fmt.Errorf("search feed: %w", err)
+		return nil, fmt.Errorf("similar posts: %w", err)
 	}
 	defer rows.Close()
 
-	var posts []models.PostResponse
-	for rows.Next() {
-		var (
-			p         models.PostResponse
-			tags      pq.StringArray
-			fileURL   sql.NullString
-			docName   sql.NullString
-			coverURL  sql.NullString
-			avatarURL sql.NullString
-			docID     sql.NullInt64
-			isLiked   bool
-			isSaved   bool
-		)
+	posts, err := scanViewerPosts(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadDetails(viewerID, posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
 
-		if err := rows.Scan(
-			&p.PostID, &p.AuthorID, &p.AuthorName,
-			&p.Title, &p.Description, &p.Visibility,
-			&docID, &p.CreatedAt, &p.UpdatedAt,
-			&p.LikeCount, &p.SaveCount,
-			&fileURL, &docName, &coverURL, &avatarURL, &tags,
-			&isLiked, &isSaved,
-		); err != nil {
-			return nil, 0, err
-		}
+// โหลดโพสต์ตาม id ที่ผู้ชมมีสิทธิ์เห็น เรียงตามลำดับใน postIDs (โพสต์ที่ดูไม่ได้/ถูกลบจะถูกตัดออก)
+func (r *postRepository) GetPostsByIDs(viewerID int, postIDs []int) ([]models.PostResponse, error) {
+	if len(postIDs) == 0 {
+		return []models.PostResponse{}, nil
+	}
+	ids := make([]int64, len(postIDs))
+	for i, id := range postIDs {
+		ids[i] = int64(id)
+	}
 
-		if docID.Valid {
-			v := int(docID.Int64)
-			p.DocumentID = &v
-		}
-		if fileURL.Valid {
-			p.FileURL = &fileURL.String
-		}
-		if docName.Valid {
-			p.DocumentName = &docName.String
-		}
-		if coverURL.Valid {
-			p.CoverURL = &coverURL.String
-		}
-		if avatarURL.Valid {
-			p.AvatarURL = &avatarURL.String
-		}
+	query := `
+		SELECT p.post_id, p.post_author_user_id, u.username AS author_name,
+			p.post_title, p.post_description, p.post_visibility,
+			p.post_document_id, p.post_created_at, p.post_updated_at, p.post_status, p.post_publish_at, p.post_audience_list_id, p.post_edited_at,
+			COALESCE(ps.post_like_count, 0) AS post_like_count,
+			COALESCE(ps.post_save_count, 0) AS post_save_count,
+			COALESCE(ps.post_comment_count, 0) AS post_comment_count,
+			COALESCE(ps.post_repost_count, 0) AS post_repost_count,
+			d.document_url AS document_file_url,
+			d.document_name AS document_name,
+			p.post_cover_url, up.avatar_url,
+			ARRAY_REMOVE(ARRAY_AGG(DISTINCT t.tag_name), NULL) AS tags,
 
-		p.Tags = []string(tags)
-		p.IsLiked = isLiked
-		p.IsSaved = isSaved
+			EXISTS (
+				SELECT 1 FROM likes l
+				WHERE l.like_user_id = $1 AND l.like_post_id = p.post_id
+			) AS is_liked,
+			EXISTS (
+				SELECT 1 FROM saved_posts sp
+				WHERE sp.save_user_id = $1 AND sp.save_post_id = p.post_id
+			) AS is_saved
 
+		FROM posts p
+		JOIN users u ON u.user_id = p.post_author_user_id
+		LEFT JOIN post_stats ps ON ps.post_stats_post_id = p.post_id
+		LEFT JOIN post_tags pt ON pt.post_tag_post_id = p.post_id
+		LEFT JOIN tags t ON t.tag_id = pt.post_tag_tag_id
+		LEFT JOIN documents d ON d.document_id = p.post_document_id
+		LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
+		WHERE p.post_id = ANY($2::int[])
+		  AND ` + visibility.PostPublished + `
+		  AND ` + visibility.PostVisible + `
+		GROUP BY p.post_id, u.username, ps.post_like_count, ps.post_save_count, ps.post_comment_count, ps.post_repost_count,
+				 d.document_url, d.document_name, p.post_cover_url, up.avatar_url
+		ORDER BY array_position($2::int[], p.post_id);
+	`
+
+	rows, err := r.db.Query(query, viewerID, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("posts by ids: %w", err)
+	}
+	defer rows.Close()
+
+	posts, err := scanViewerPosts(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadDetails(viewerID, posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// scan แถวโพสต์ที่มีคอลัมน์ is_liked / is_saved ของผู้ชมต่อท้าย
+func scanViewerPosts(rows *sql.Rows) ([]models.PostResponse, error) {
+	posts := make([]models.PostResponse, 0)
+	for rows.Next() {
+		p, err := scanViewerPost(rows)
+		if err != nil {
+			return nil, err
+		}
 		posts = append(posts, p)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+	return posts, nil
+}
+
+// อ่านหนึ่งแถวของ query รายการโพสต์ของผู้ชม (extra = คอลัมน์ที่ต่อท้าย เช่นคะแนน)
+func scanViewerPost(rows *sql.Rows, extra ...any) (models.PostResponse, error) {
+	var (
+		p         models.PostResponse
+		tags      pq.StringArray
+		fileURL   sql.NullString
+		docName   sql.NullString
+		coverURL  sql.NullString
+		avatarURL sql.NullString
+		docID     sql.NullInt64
+		isLiked   bool
+		isSaved   bool
+	)
+
+	dest := []any{
+		&p.PostID, &p.AuthorID, &p.AuthorName,
+		&p.Title, &p.Description, &p.Visibility,
+		&docID, &p.CreatedAt, &p.UpdatedAt, &p.Status, &p.PublishAt, &p.AudienceID, &p.EditedAt,
+		&p.LikeCount, &p.SaveCount, &p.CommentCount, &p.RepostCount,
+		&fileURL, &docName, &coverURL, &avatarURL, &tags,
+		&isLiked, &isSaved,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return p, err
 	}
 
-	return posts, total, nil
+	if docID.Valid {
+		v := int(docID.Int64)
+		p.DocumentID = &v
+	}
+	if fileURL.Valid {
+		p.FileURL = &fileURL.String
+	}
+	if docName.Valid {
+		p.DocumentName = &docName.String
+	}
+	if coverURL.Valid {
+		p.CoverURL = &coverURL.String
+	}
+	if avatarURL.Valid {
+		p.AvatarURL = &avatarURL.String
+	}
+
+	p.Tags = []string(tags)
+	p.Edited = p.EditedAt != nil
+	p.IsLiked = isLiked
+	p.IsSaved = isSaved
+	return p, nil
 }
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"chaladshare_backend/internal/posts/models"
+)
+
+type ReactionRepository interface {
+	SetReaction(userID, postID int, reactionType string, on bool) error
+	GetSummary(viewerID, postID int) (*models.ReactionSummary, error)
+}
+
+type reactionRepository struct {
+	db *sql.DB
+}
+
+func NewReactionRepository(db *sql.DB) ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+// บวก delta.n เข้า post_reaction_counts->$3 (แบบเดียวกับ counterTable.apply)
+const qApplyReactionDelta = `
+	INSERT INTO post_stats (post_stats_post_id, post_reaction_counts, post_last_activity_at)
+	SELECT target.post_id, jsonb_build_object($3::text, GREATEST(delta.n, 0)), now() FROM target, delta
+	ON CONFLICT (post_stats_post_id) DO UPDATE SET
+		post_reaction_counts = jsonb_set(
+			COALESCE(post_stats.post_reaction_counts, '{}'::jsonb), ARRAY[$3::text],
+			to_jsonb(GREATEST(COALESCE((post_stats.post_reaction_counts ->> $3::text)::int, 0) + (SELECT n FROM delta), 0))),
+		post_last_activity_at = CASE WHEN (SELECT n FROM delta) <> 0
+			THEN now() ELSE post_stats.post_last_activity_at END
+	RETURNING 1;`
+
+// กด (on = true) / ยกเลิก reaction ที่ไม่ใช่ like แบบ idempotent พร้อมตัวนับใน statement เดียว
+func (r *reactionRepository) SetReaction(userID, postID int, reactionType string, on bool) error {
+	var change string
+	if on {
+		change = `ins AS (
+		INSERT INTO reactions (reaction_user_id, reaction_post_id, reaction_type)
+		SELECT $1, post_id, $3 FROM target
+		ON CONFLICT DO NOTHING
+		RETURNING 1
+	),
+	delta AS (SELECT (SELECT COUNT(*) FROM ins) AS n)`
+	} else {
+		change = `del AS (
+		DELETE FROM reactions
+		WHERE reaction_user_id = $1 AND reaction_post_id = $2 AND reaction_type = $3
+		  AND EXISTS (SELECT 1 FROM target)
+		RETURNING 1
+	),
+	delta AS (SELECT -(SELECT COUNT(*) FROM del) AS n)`
+	}
+
+	var ok int
+	err := r.db.QueryRow(`WITH `+qCounterTarget+`, `+change+qApplyReactionDelta, userID, postID, reactionType).Scan(&ok)
+	if err == sql.ErrNoRows {
+		return models.ErrPostNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("set reaction: %w", err)
+	}
+	return nil
+}
+
+func (r *reactionRepository) GetSummary(viewerID, postID int) (*models.ReactionSummary, error) {
+	list := []models.PostResponse{{PostID: postID}}
+	if err := loadReactions(r.db, viewerID, list); err != nil {
+		return nil, err
+	}
+	return &models.ReactionSummary{PostID: postID, Reactions: list[0].Reactions, MyReactions: list[0].MyReactions}, nil
+}
+
+// เติม reactions / my_reactions ให้ทุกโพสต์ในหน้าด้วย query เดียว (viewerID = 0 → my_reactions ว่าง)
+func loadReactions(db *sql.DB, viewerID int, posts []models.PostResponse) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(posts))
+	index := make(map[int]int, len(posts))
+	for i := range posts {
+		ids[i] = int64(posts[i].PostID)
+		index[posts[i].PostID] = i
+		posts[i].Reactions = make(map[string]int, len(models.ReactionTypes))
+		for _, t := range models.ReactionTypes {
+			posts[i].Reactions[t] = 0
+		}
+		posts[i].MyReactions = []string{}
+	}
+
+	rows, err := db.Query(`
+		SELECT p.id,
+			COALESCE(ps.post_like_count, 0),
+			COALESCE(ps.post_reaction_counts, '{}'::jsonb)::text,
+			ARRAY(
+				SELECT 'like'::text FROM likes
+				WHERE like_user_id = $2 AND like_post_id = p.id
+				UNION ALL
+				SELECT reaction_type::text FROM reactions
+				WHERE reaction_user_id = $2 AND reaction_post_id = p.id
+			)
+		FROM unnest($1::int[]) AS p(id)
+		LEFT JOIN post_stats ps ON ps.post_stats_post_id = p.id;`, pq.Array(ids), viewerID)
+	if err != nil {
+		return fmt.Errorf("load reactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			postID, likes int
+			raw           string
+			mine          pq.StringArray
+			counts        map[string]int
+		)
+		if err := rows.Scan(&postID, &likes, &raw, &mine); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(raw), &counts); err != nil {
+			return fmt.Errorf("decode reaction counts: %w", err)
+		}
+		i, ok := index[postID]
+		if !ok {
+			continue
+		}
+		for t, n := range counts {
+			if _, known := posts[i].Reactions[t]; known {
+				posts[i].Reactions[t] = n
+			}
+		}
+		posts[i].Reactions[models.DefaultReaction] = likes
+
+		has := make(map[string]bool, len(mine))
+		for _, t := range mine {
+			has[t] = true
+		}
+		for _, t := range models.ReactionTypes {
+			if has[t] {
+				posts[i].MyReactions = append(posts[i].MyReactions, t)
+			}
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"chaladshare_backend/internal/pagination"
+	"chaladshare_backend/internal/posts/models"
+)
+
+type RepostRepository interface {
+	UpsertRepost(userID, postID int, quote *string) error
+	DeleteRepost(userID, postID int) error
+	ListReposts(postID int, after *pagination.Keyset, limit int) ([]models.Repost, error)
+	UpdateRepostCount(postID int) error
+	RepostCount(postID int) (int, error)
+}
+
+type repostRepository struct {
+	db *sql.DB
+}
+
+func NewRepostRepository(db *sql.DB) RepostRepository {
+	return &repostRepository{db: db}
+}
+
+const qRepostSelect = `
+	SELECT rp.repost_id, rp.repost_post_id, rp.repost_user_id, u.username, up.avatar_url,
+		rp.repost_quote, rp.repost_created_at
+	FROM reposts rp
+	JOIN users u ON u.user_id = rp.repost_user_id
+	LEFT JOIN user_profiles up ON up.profile_user_id = u.user_id
+`
+
+func scanRepost(row interface{ Scan(...any) error }) (*models.Repost, error) {
+	var (
+		rp     models.Repost
+		avatar sql.NullString
+		quote  sql.NullString
+	)
+	if err := row.Scan(&rp.RepostID, &rp.PostID, &rp.UserID, &rp.Username, &avatar,
+		&quote, &rp.CreatedAt); err != nil {
+		return nil, err
+	}
+	if avatar.Valid {
+		rp.AvatarURL = &avatar.String
+	}
+	if quote.Valid {
+		rp.Quote = &quote.String
+	}
+	return &rp, nil
+}
+
+// รีโพสต์ซ้ำ = แก้ข้อความ quote (เวลารีโพสต์คงเดิม)
+func (r *repostRepository) UpsertRepost(userID, postID int, quote *string) error {
+	query := `
+		INSERT INTO reposts (repost_user_id, repost_post_id, repost_quote)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (repost_user_id, repost_post_id)
+		DO UPDATE SET repost_quote = EXCLUDED.repost_quote;
+	`
+	if _, err := r.db.Exec(query, userID, postID, quote); err != nil {
+		return fmt.Errorf("failed to repost: %v", err)
+	}
+	return r.UpdateRepostCount(postID)
+}
+
+func (r *repostRepository) DeleteRepost(userID, postID int) error {
+	res, err := r.db.Exec(`DELETE FROM reposts WHERE repost_user_id=$1 AND repost_post_id=$2`, userID, postID)
+	if err != nil {
+		return fmt.Errorf("failed to undo repost: %v", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrRepostNotFound
+	}
+	return r.UpdateRepostCount(postID)
+}
+
+// รีโพสต์ของโพสต์นี้ ใหม่→เก่า (keyset บน repost_created_at, repost_id)
+func (r *repostRepository) ListReposts(postID int, after *pagination.Keyset, limit int) ([]models.Repost, error) {
+	afterTS, afterID := keysetArgs(after)
+	rows, err := r.db.Query(qRepostSelect+`
+		WHERE rp.repost_post_id = $1
+		  AND ($2::timestamptz IS NULL OR (rp.repost_created_at, rp.repost_id) < ($2::timestamptz, $3::int))
+		ORDER BY rp.repost_created_at DESC, rp.repost_id DESC
+		LIMIT $4;`, postID, afterTS, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.Repost, 0)
+	for rows.Next() {
+		rp, err := scanRepost(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *rp)
+	}
+	return out, rows.Err()
+}
+
+// อัปเดตจำนวนรีโพสต์ใน post_stats
+func (r *repostRepository) UpdateRepostCount(postID int) error {
+	query := `
+		INSERT INTO post_stats (post_stats_post_id, post_repost_count, post_last_activity_at)
+		VALUES (
+			$1,
+			(SELECT COUNT(*) FROM reposts WHERE repost_post_id = $1),
+			NOW()
+		)
+		ON CONFLICT (post_stats_post_id)
+		DO UPDATE SET
+			post_repost_count     = EXCLUDED.post_repost_count,
+			post_last_activity_at = EXCLUDED.post_last_activity_at;
+	`
+	_, err := r.db.Exec(query, postID)
+	return err
+}
+
+// จำนวนรีโพสต์ของโพสต์
+func (r *repostRepository) RepostCount(postID int) (int, error) {
+	query := `SELECT COUNT(*) FROM reposts WHERE repost_post_id = $1`
+	var count int
+	if err := r.db.QueryRow(query, postID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to get repost count: %v", err)
+	}
+	return count, nil
+}
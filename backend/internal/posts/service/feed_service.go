@@ -0,0 +1,112 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"chaladshare_backend/internal/pagination"
+	"chaladshare_backend/internal/posts/models"
+	"chaladshare_backend/internal/posts/repository"
+)
+
+type FeedService interface {
+	GetRankedFeed(viewerID int, cursor string, limit int) (*models.PostPage, error)
+}
+
+const (
+	// จำนวนโพสต์สูงสุดที่จัดอันดับต่อ snapshot (หลังจากนั้นต่อด้วยโพสต์ใหม่→เก่า)
+	feedSnapshotSize = 300
+	// อายุ snapshot: cursor ที่อ้าง snapshot เก่ากว่านี้ต้องโหลดฟีดใหม่
+	feedSnapshotTTL = 6 * time.Hour
+)
+
+type feedService struct {
+	feedRepo repository.FeedRepository
+	postRepo repository.PostRepository
+	cursors  *pagination.Codec
+}
+
+func NewFeedService(feedRepo repository.FeedRepository, postRepo repository.PostRepository, cursors *pagination.Codec) FeedService {
+	return &feedService{
+		feedRepo: feedRepo,
+		postRepo: postRepo,
+		cursors:  cursors,
+	}
+}
+
+// หน้าแรกสร้าง snapshot ใหม่ หน้าถัดไปอ่านต่อจาก snapshot เดิม
+// เมื่อ snapshot หมด จะต่อด้วยโพสต์ใหม่→เก่าที่ไม่เคยอยู่ใน snapshot
+func (s *feedService) GetRankedFeed(viewerID int, cursor string, limit int) (*models.PostPage, error) {
+	limit = pagination.ClampLimit(limit)
+
+	var cur models.FeedCursor
+	ok, err := s.cursors.Decode(cursor, &cur)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshotAt time.Time
+	if !ok {
+		cur.Snapshot, snapshotAt, err = s.feedRepo.BuildSnapshot(viewerID, feedSnapshotSize, feedSnapshotTTL)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var owner int
+		owner, snapshotAt, err = s.feedRepo.GetSnapshot(cur.Snapshot)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && owner != viewerID) ||
+			(err == nil && time.Since(snapshotAt) > feedSnapshotTTL) {
+			return nil, models.ErrFeedExpired
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int, 0, limit)
+	if cur.Tail == nil {
+		items, err := s.feedRepo.ListSnapshotItems(cur.Snapshot, cur.Rank, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range items {
+			ids = append(ids, it.PostID)
+			cur.Rank = it.Rank
+		}
+		if len(items) < limit {
+			// snapshot หมดแล้ว เริ่มส่วน tail จากเวลาที่สร้าง snapshot
+			cur.Tail = &pagination.Keyset{CreatedAt: snapshotAt, ID: math.MaxInt32}
+		}
+	}
+
+	exhausted := false
+	if cur.Tail != nil && len(ids) < limit {
+		want := limit - len(ids)
+		tail, err := s.feedRepo.ListTail(viewerID, cur.Snapshot, *cur.Tail, want)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range tail {
+			ids = append(ids, k.ID)
+			last := k
+			cur.Tail = &last
+		}
+		exhausted = len(tail) < want
+	}
+
+	// โหลดรายละเอียดตามลำดับ (ตรวจสิทธิ์การมองเห็นซ้ำ เผื่อโพสต์ถูกเปลี่ยนหลังสร้าง snapshot)
+	posts, err := s.postRepo.GetPostsByIDs(viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.PostPage{Items: posts}
+	if !exhausted {
+		if page.NextCursor, err = s.cursors.Encode(cur); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
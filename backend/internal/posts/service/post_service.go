@@ -2,43 +2,60 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	friendservice "chaladshare_backend/internal/friends/service"
+	"chaladshare_backend/internal/pagination"
 	"chaladshare_backend/internal/posts/models"
 	"chaladshare_backend/internal/posts/repository"
+	"chaladshare_backend/internal/tagname"
 )
 
 type PostService interface {
 	CreatePost(post *models.Post, tags []string) (int, error)
 	UpdatePost(post *models.Post, tags []string) error
 	DeletePost(postID int) error
+	RestorePost(postID int) error
+	GetTrashedPosts(userID int) ([]models.TrashedPost, error)
+	PurgeTrash() (int, error)
+	ReconcileStats() (int, error)
 
-	GetAllPosts() ([]models.PostResponse, error)
-	GetFeedPosts(viewerID int) ([]models.PostResponse, error)
+	GetAllPosts(cursor string, limit int) (*models.PostPage, error)
+	GetFeedPosts(viewerID int, cursor string, limit int) (*models.PostPage, error)
 	GetPostByID(postID int) (*models.PostResponse, error)
 	GetPostByIDForViewer(viewerID, postID int) (*models.PostResponse, error)
 	CountByUserID(userID int) (int, error)
 
 	IsOwner(postID int, userID int) (bool, error)
+	ListRevisions(postID int) ([]models.PostRevision, error)
+	RestoreRevision(postID, revisionID int) error
 	ViewPost(viewerID, postID int) (bool, string, error)
 	Friends(viewerID, authorID int) (bool, error)
 
-	GetSavedPosts(userID int) ([]models.PostResponse, error)
-	GetPopularPosts(viewerID, limit int) ([]models.PostResponse, error)
-	SearchPosts(viewerID int, search string, page, size int) ([]models.PostResponse, int, error)
+	GetSavedPosts(userID int, cursor string, limit int) (*models.PostPage, error)
+	GetDraftPosts(userID int, cursor string, limit int) (*models.PostPage, error)
+	PublishDue() (int, error)
+	GetPopularPosts(viewerID int, orderBy, cursor string, limit int) (*models.PostPage, error)
+	SearchPosts(viewerID int, search, orderBy, cursor string, limit int) (*models.PostPage, error)
+	GetSimilarPosts(viewerID, postID, limit int) ([]models.PostResponse, error)
+	GetPostsByIDs(viewerID int, postIDs []int) ([]models.PostResponse, error)
 }
 
 type postService struct {
 	postRepo  repository.PostRepository
 	friendSvc friendservice.FriendService
+	cursors   *pagination.Codec
 }
 
-func NewPostService(postRepo repository.PostRepository, friendSvc friendservice.FriendService) PostService {
+func NewPostService(postRepo repository.PostRepository, friendSvc friendservice.FriendService, cursors *pagination.Codec) PostService {
 	return &postService{
 		postRepo:  postRepo,
 		friendSvc: friendSvc,
+		cursors:   cursors,
 	}
 }
 
@@ -47,10 +64,79 @@ func normalizeVisibility(v string) (string, error) {
 	switch vis {
 	case "", models.VisibilityPublic:
 		return models.VisibilityPublic, nil
-	case models.VisibilityFriends:
-		return models.VisibilityFriends, nil
+	case models.VisibilityFriends, models.VisibilityPrivate, models.VisibilityList:
+		return vis, nil
 	default:
-		return "", fmt.Errorf("unsupported visibility: %s", v)
+		return "", models.ErrInvalidVisibility
+	}
+}
+
+// ไฟล์แนบไม่ซ้ำ เรียงตามที่ส่งมา (1-10 ไฟล์); client เก่าส่งแค่ document_id
+func normalizeAttachments(ids []int, documentID *int) ([]int, error) {
+	if len(ids) == 0 && documentID != nil {
+		ids = []int{*documentID}
+	}
+	seen := make(map[int]struct{}, len(ids))
+	out := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, models.ErrInvalidAttachments
+		}
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	if len(out) == 0 || len(out) > models.MaxAttachments {
+		return nil, models.ErrInvalidAttachments
+	}
+	return out, nil
+}
+
+// list ต้องผูกกับ audience list ของผู้เขียนเอง; visibility อื่นไม่ผูก list
+func (s *postService) resolveAudience(post *models.Post, authorID int) error {
+	if post.Visibility != models.VisibilityList {
+		post.AudienceID = nil
+		return nil
+	}
+	if post.AudienceID == nil {
+		return models.ErrInvalidAudienceList
+	}
+	ownerID, err := s.postRepo.GetAudienceListOwner(*post.AudienceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrInvalidAudienceList
+	}
+	if err != nil {
+		return fmt.Errorf("get audience list: %w", err)
+	}
+	if ownerID != authorID {
+		return models.ErrInvalidAudienceList
+	}
+	return nil
+}
+
+// ค่าว่าง = published; ถ้ามี publishAt ในอนาคตจะกลายเป็น scheduled
+// draft ไม่เก็บเวลาเผยแพร่, published เผยแพร่ทันที
+func normalizeStatus(status string, publishAt *time.Time) (string, *time.Time, error) {
+	st := strings.ToLower(strings.TrimSpace(status))
+	switch st {
+	case "":
+		if publishAt != nil && publishAt.After(time.Now()) {
+			return models.StatusScheduled, publishAt, nil
+		}
+		return models.StatusPublished, nil, nil
+	case models.StatusPublished:
+		return models.StatusPublished, nil, nil
+	case models.StatusDraft:
+		return models.StatusDraft, nil, nil
+	case models.StatusScheduled:
+		if publishAt == nil || !publishAt.After(time.Now()) {
+			return "", nil, models.ErrInvalidPublishAt
+		}
+		return models.StatusScheduled, publishAt, nil
+	default:
+		return "", nil, models.ErrInvalidStatus
 	}
 }
 
@@ -68,6 +154,16 @@ func (s *postService) CreatePost(post *models.Post, tags []string) (int, error)
 		return 0, err
 	}
 	post.Visibility = vis
+	if err := s.resolveAudience(post, post.AuthorUserID); err != nil {
+		return 0, err
+	}
+
+	if post.Status, post.PublishAt, err = normalizeStatus(post.Status, post.PublishAt); err != nil {
+		return 0, err
+	}
+	if post.Attachments, err = normalizeAttachments(post.Attachments, post.DocumentID); err != nil {
+		return 0, err
+	}
 
 	normTags := normalizeTags(tags)
 	postID, err := s.postRepo.CreatePost(post, normTags)
@@ -85,15 +181,16 @@ func (s *postService) UpdatePost(post *models.Post, tags []string) error {
 		return fmt.Errorf("post_title is required")
 	}
 
+	existing, err := s.postRepo.GetPostByID(post.PostID)
+	if err != nil {
+		return fmt.Errorf("get existing post: %w", err)
+	}
+	if existing == nil {
+		return models.ErrPostNotFound
+	}
+
 	visInput := strings.TrimSpace(post.Visibility)
 	if visInput == "" {
-		existing, err := s.postRepo.GetPostByID(post.PostID)
-		if err != nil {
-			return fmt.Errorf("get existing post: %w", err)
-		}
-		if existing == nil {
-			return fmt.Errorf("post not found")
-		}
 		visInput = existing.Visibility
 	}
 
@@ -102,6 +199,35 @@ func (s *postService) UpdatePost(post *models.Post, tags []string) error {
 		return err
 	}
 	post.Visibility = vis
+	if post.AudienceID == nil {
+		post.AudienceID = existing.AudienceID
+	}
+	if err := s.resolveAudience(post, existing.AuthorID); err != nil {
+		return err
+	}
+
+	// ไม่ส่งไฟล์แนบมา (nil) = คงเดิม
+	post.AuthorUserID = existing.AuthorID
+	if post.Attachments != nil {
+		if post.Attachments, err = normalizeAttachments(post.Attachments, nil); err != nil {
+			return err
+		}
+	}
+
+	// ไม่ส่งสถานะมา = คงเดิม; โพสต์ที่เผยแพร่แล้วย้อนกลับเป็น draft / scheduled ไม่ได้
+	if post.Status == "" && post.PublishAt == nil {
+		post.Status, post.PublishAt = existing.Status, existing.PublishAt
+	} else {
+		if post.Status == "" {
+			post.Status = models.StatusScheduled
+		}
+		if post.Status, post.PublishAt, err = normalizeStatus(post.Status, post.PublishAt); err != nil {
+			return err
+		}
+		if existing.Status == models.StatusPublished && post.Status != models.StatusPublished {
+			return models.ErrAlreadyPublished
+		}
+	}
 
 	var normTags []string
 	if tags != nil {
@@ -120,24 +246,10 @@ func normalizeTags(in []string) []string {
 
 	const (
 		maxTags = 10
-		maxLen  = 30
 	)
 	for _, t := range in {
-		tag := strings.TrimSpace(t)
-		tag = strings.TrimPrefix(tag, "#")
-		tag = strings.ToLower(tag)
-
-		if tag == "" || len(tag) > maxLen {
-			continue
-		}
-		valid := true
-		for _, r := range tag {
-			if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '_' && r != '-' {
-				valid = false
-				break
-			}
-		}
-		if !valid {
+		tag := tagname.Normalize(t)
+		if tag == "" {
 			continue
 		}
 
@@ -153,16 +265,188 @@ func normalizeTags(in []string) []string {
 	return out
 }
 
+// ผู้เรียกต้องเช็คว่าเป็นเจ้าของโพสต์ก่อน
+func (s *postService) ListRevisions(postID int) ([]models.PostRevision, error) {
+	return s.postRepo.ListRevisions(postID)
+}
+
+// กู้คืนเนื้อหาจาก revision ผ่าน UpdatePost ปกติ (สถานะปัจจุบันถูกเก็บเป็น revision ใหม่ก่อน)
+// สถานะการเผยแพร่คงเดิม; ไฟล์แนบที่ถูกลบไปแล้วจะถูกข้าม ถ้าไม่เหลือเลยใช้ไฟล์แนบปัจจุบัน
+func (s *postService) RestoreRevision(postID, revisionID int) error {
+	rev, err := s.postRepo.GetRevision(postID, revisionID)
+	if err != nil {
+		return err
+	}
+
+	post := &models.Post{
+		PostID:      postID,
+		Title:       rev.Title,
+		Description: rev.Description,
+		Visibility:  rev.Visibility,
+		AudienceID:  rev.AudienceID,
+	}
+	if len(rev.AttachmentIDs) > 0 {
+		post.Attachments = rev.AttachmentIDs
+	}
+	tags := rev.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return s.UpdatePost(post, tags)
+}
+
+// ย้ายลงถังขยะ กู้คืนได้ภายใน TrashRetentionDays วัน
 func (s *postService) DeletePost(postID int) error {
 	return s.postRepo.DeletePost(postID)
 }
 
-func (s *postService) GetAllPosts() ([]models.PostResponse, error) {
-	return s.postRepo.GetAllPosts()
+func (s *postService) RestorePost(postID int) error {
+	return s.postRepo.RestorePost(postID)
 }
 
-func (s *postService) GetFeedPosts(viewerID int) ([]models.PostResponse, error) {
-	return s.postRepo.GetFeedPosts(viewerID)
+func (s *postService) GetTrashedPosts(userID int) ([]models.TrashedPost, error) {
+	return s.postRepo.GetTrashedPosts(userID)
+}
+
+// เรียกจาก background job
+func (s *postService) PurgeTrash() (int, error) {
+	return s.postRepo.PurgeTrash()
+}
+
+func (s *postService) ReconcileStats() (int, error) {
+	return s.postRepo.ReconcileStats()
+}
+
+// ถอด cursor ของหน้าก่อน (nil = หน้าแรก)
+func (s *postService) decodeCursor(cursor string) (*pagination.Keyset, error) {
+	var k pagination.Keyset
+	ok, err := s.cursors.Decode(cursor, &k)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// repo ดึงมา limit+1 แถว ถ้าเกิน limit แปลว่ายังมีหน้าถัดไป
+func (s *postService) toPage(items []models.PostResponse, after *pagination.Keyset, limit int) (*models.PostPage, error) {
+	page := &models.PostPage{Items: items}
+	if after != nil {
+		page.Offset = after.Offset
+	}
+	if len(items) <= limit {
+		return page, nil
+	}
+	page.Items = items[:limit]
+
+	last := page.Items[limit-1]
+	next := pagination.Keyset{CreatedAt: last.CreatedAt, ID: last.PostID, Offset: page.Offset + limit}
+	// รายการรีโพสต์ใน feed เรียงตามเวลารีโพสต์
+	if last.Repost != nil {
+		next.CreatedAt = last.Repost.CreatedAt
+	}
+	cursor, err := s.cursors.Encode(next)
+	if err != nil {
+		return nil, err
+	}
+	page.NextCursor = cursor
+	return page, nil
+}
+
+func (s *postService) GetAllPosts(cursor string, limit int) (*models.PostPage, error) {
+	after, err := s.decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit = pagination.ClampLimit(limit)
+	items, err := s.postRepo.GetAllPosts(after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	return s.toPage(items, after, limit)
+}
+
+func (s *postService) GetFeedPosts(viewerID int, cursor string, limit int) (*models.PostPage, error) {
+	var after *models.FeedEntryCursor
+	var k models.FeedEntryCursor
+	ok, err := s.cursors.Decode(cursor, &k)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		after = &k
+	}
+	limit = pagination.ClampLimit(limit)
+	entries, err := s.postRepo.GetFeedEntries(viewerID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	// cursor คิดจากรายการดิบก่อนกรอง: โพสต์ที่โหลดไม่ได้แล้วถูกตัดออกจากหน้า แต่ไม่ทำให้ฟีดจบก่อนเวลา
+	page := &models.PostPage{}
+	if after != nil {
+		page.Offset = after.Offset
+	}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		last := entries[limit-1]
+		next := models.FeedEntryCursor{SortAt: last.SortAt, PostID: last.PostID, Offset: page.Offset + limit}
+		if last.RepostID != nil {
+			next.RepostID = *last.RepostID
+		}
+		if page.NextCursor, err = s.cursors.Encode(next); err != nil {
+			return nil, err
+		}
+	}
+	if page.Items, err = s.feedItems(viewerID, entries); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// โหลดรายละเอียดโพสต์ของแต่ละรายการตามลำดับ
+func (s *postService) feedItems(viewerID int, entries []models.FeedEntry) ([]models.PostResponse, error) {
+	postIDs := make([]int, 0, len(entries))
+	repostIDs := make([]int, 0)
+	seen := make(map[int]bool, len(entries))
+	for _, e := range entries {
+		if !seen[e.PostID] {
+			seen[e.PostID] = true
+			postIDs = append(postIDs, e.PostID)
+		}
+		if e.RepostID != nil {
+			repostIDs = append(repostIDs, *e.RepostID)
+		}
+	}
+
+	posts, err := s.postRepo.GetPostsByIDs(viewerID, postIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.PostResponse, len(posts))
+	for _, p := range posts {
+		byID[p.PostID] = p
+	}
+	reposts, err := s.postRepo.GetRepostsByIDs(repostIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.PostResponse, 0, len(entries))
+	for _, e := range entries {
+		p, ok := byID[e.PostID]
+		if !ok {
+			continue
+		}
+		if e.RepostID != nil {
+			rp, ok := reposts[*e.RepostID]
+			if !ok {
+				continue
+			}
+			p.Repost = &rp
+		}
+		items = append(items, p)
+	}
+	return items, nil
 }
 
 // each post by ID
@@ -203,6 +487,14 @@ func (s *postService) ViewPost(viewerID, postID int) (bool, string, error) {
 	if viewerID == authorID {
 		return true, "owner", nil
 	}
+	// draft / scheduled: คนอื่นเห็นเหมือนไม่มีโพสต์นี้
+	if post.Status != models.StatusPublished {
+		return false, "not_found", nil
+	}
+	// ถูกซ่อนจากการรายงาน
+	if post.HiddenAt != nil {
+		return false, "not_found", nil
+	}
 
 	switch vis {
 	case models.VisibilityPublic:
@@ -216,6 +508,18 @@ func (s *postService) ViewPost(viewerID, postID int) (bool, string, error) {
 			return true, "friends", nil
 		}
 		return false, "friends_only", nil
+	case models.VisibilityList:
+		if post.AudienceID == nil {
+			return false, "denied", nil
+		}
+		ok, err := s.postRepo.IsAudienceMember(*post.AudienceID, viewerID)
+		if err != nil {
+			return false, "error", err
+		}
+		if ok {
+			return true, "list", nil
+		}
+		return false, "list_only", nil
 	default:
 		return false, "denied", nil
 	}
@@ -237,11 +541,38 @@ func (s *postService) Friends(viewerID, authorID int) (bool, error) {
 	return ok, nil
 }
 
-func (s *postService) GetSavedPosts(userID int) ([]models.PostResponse, error) {
-	return s.postRepo.GetSavedPosts(userID)
+func (s *postService) GetSavedPosts(userID int, cursor string, limit int) (*models.PostPage, error) {
+	after, err := s.decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit = pagination.ClampLimit(limit)
+	items, err := s.postRepo.GetSavedPosts(userID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	return s.toPage(items, after, limit)
 }
 
-func (s *postService) GetPopularPosts(viewerID, limit int) ([]models.PostResponse, error) {
+func (s *postService) GetDraftPosts(userID int, cursor string, limit int) (*models.PostPage, error) {
+	after, err := s.decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit = pagination.ClampLimit(limit)
+	items, err := s.postRepo.GetDraftPosts(userID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	return s.toPage(items, after, limit)
+}
+
+func (s *postService) PublishDue() (int, error) {
+	return s.postRepo.PublishDue()
+}
+
+// cursor ผูกกับลำดับ (sort) ที่ใช้สร้าง ถ้าลำดับเปลี่ยนระหว่างหน้าถือว่า cursor ใช้ไม่ได้
+func (s *postService) GetPopularPosts(viewerID int, orderBy, cursor string, limit int) (*models.PostPage, error) {
 	if viewerID <= 0 {
 		return nil, fmt.Errorf("invalid viewer id")
 	}
@@ -251,21 +582,120 @@ func (s *postService) GetPopularPosts(viewerID, limit int) ([]models.PostRespons
 	if limit > 20 {
 		limit = 20
 	}
-	return s.postRepo.GetPopularPosts(viewerID, limit)
+	switch orderBy {
+	case models.PopularByLikes, models.PopularBySaves, models.PopularByRecent:
+	default:
+		orderBy = models.PopularByLikes
+	}
+
+	var after *models.PopularCursor
+	var k models.PopularCursor
+	ok, err := s.cursors.Decode(cursor, &k)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if k.Sort != orderBy {
+			return nil, pagination.ErrInvalidCursor
+		}
+		after = &k
+	}
+
+	items, keys, err := s.postRepo.GetPopularPosts(viewerID, orderBy, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &models.PostPage{Items: items}
+	if after != nil {
+		page.Offset = after.Offset
+	}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		next := keys[limit-1]
+		next.Offset = page.Offset + limit
+		if page.NextCursor, err = s.cursors.Encode(next); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
 }
 
-func (s *postService) SearchPosts(viewerID int, search string, page, size int) ([]models.PostResponse, int, error) {
+// relevance แบ่งหน้าด้วย keyset บน (คะแนน, เวลาสร้าง, post_id) โดยเก็บคะแนนของรายการสุดท้ายใน cursor
+func (s *postService) SearchPosts(viewerID int, search, orderBy, cursor string, limit int) (*models.PostPage, error) {
 	if viewerID <= 0 {
-		return nil, 0, fmt.Errorf("invalid viewer id")
+		return nil, fmt.Errorf("invalid viewer id")
 	}
 
 	search = strings.TrimSpace(search)
+	if orderBy != models.SearchByRelevance {
+		orderBy = models.SearchByRecent
+	}
+
+	// cursor ผูกกับลำดับที่ใช้สร้าง (variant เปลี่ยนระหว่างหน้า = cursor ใช้ไม่ได้)
+	var after *models.SearchCursor
+	var k models.SearchCursor
+	ok, err := s.cursors.Decode(cursor, &k)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if k.Sort != orderBy {
+			return nil, pagination.ErrInvalidCursor
+		}
+		after = &k
+	}
+	limit = pagination.ClampLimit(limit)
+
+	items, scores, err := s.postRepo.SearchPosts(viewerID, search, orderBy, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &models.PostPage{Items: items}
+	if after != nil {
+		page.Offset = after.Offset
+	}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := items[limit-1]
+		next := models.SearchCursor{Sort: orderBy, CreatedAt: last.CreatedAt, ID: last.PostID, Offset: page.Offset + limit}
+		if orderBy == models.SearchByRelevance {
+			next.Score = scores[limit-1]
+		}
+		if page.NextCursor, err = s.cursors.Encode(next); err != nil {
+			return nil, err
+		}
+	}
+
+	// นับทั้งหมดเฉพาะหน้าแรก หน้าถัดไปไม่ต้อง COUNT ซ้ำ
+	if after == nil {
+		total, err := s.postRepo.CountSearchPosts(viewerID, search)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+	return page, nil
+}
+
+// คืนตามลำดับของ postIDs เฉพาะโพสต์ที่ผู้ชมเห็นได้
+func (s *postService) GetPostsByIDs(viewerID int, postIDs []int) ([]models.PostResponse, error) {
+	return s.postRepo.GetPostsByIDs(viewerID, postIDs)
+}
 
-	if page < 1 {
-		page = 1
+// โพสต์ที่คล้ายกัน (style vector + content embedding + แท็กร่วม)
+// ผู้เรียกต้องเช็คสิทธิ์ดูโพสต์ต้นทางด้วย ViewPost ก่อน
+func (s *postService) GetSimilarPosts(viewerID, postID, limit int) ([]models.PostResponse, error) {
+	if viewerID <= 0 {
+		return nil, fmt.Errorf("invalid viewer id")
 	}
-	if size <= 0 || size > 100 {
-		size = 20
+	if postID <= 0 {
+		return nil, fmt.Errorf("invalid post_id")
+	}
+	if limit <= 0 {
+		limit = 6
+	}
+	if limit > 20 {
+		limit = 20
 	}
-	return s.postRepo.SearchPosts(viewerID, search, page, size)
+	return s.postRepo.GetSimilarPosts(viewerID, postID, limit)
 }
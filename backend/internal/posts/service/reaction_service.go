@@ -0,0 +1,61 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"chaladshare_backend/internal/posts/models"
+	"chaladshare_backend/internal/posts/repository"
+)
+
+type ReactionService interface {
+	SetReaction(userID, postID int, reactionType string, on bool) (*models.ReactionSummary, error)
+}
+
+type reactionService struct {
+	reactionRepo repository.ReactionRepository
+	likeRepo     repository.LikeRepository
+	postService  PostService
+}
+
+func NewReactionService(reactionRepo repository.ReactionRepository, likeRepo repository.LikeRepository, postService PostService) ReactionService {
+	return &reactionService{reactionRepo: reactionRepo, likeRepo: likeRepo, postService: postService}
+}
+
+func normalizeReaction(reactionType string) (string, error) {
+	t := strings.ToLower(strings.TrimSpace(reactionType))
+	for _, known := range models.ReactionTypes {
+		if t == known {
+			return t, nil
+		}
+	}
+	return "", models.ErrInvalidReaction
+}
+
+// กด / ยกเลิก reaction แบบ idempotent คืนสรุป reaction ล่าสุดของโพสต์
+// "like" ไปที่ตาราง likes (เหมือน PUT/DELETE /posts/:id/like)
+func (s *reactionService) SetReaction(userID, postID int, reactionType string, on bool) (*models.ReactionSummary, error) {
+	t, err := normalizeReaction(reactionType)
+	if err != nil {
+		return nil, err
+	}
+	// โพสต์ที่มองไม่เห็น = ไม่มีโพสต์ (ไม่คืนจำนวน reaction ของโพสต์นั้น)
+	ok, _, err := s.postService.ViewPost(userID, postID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if !ok {
+		return nil, models.ErrPostNotFound
+	}
+
+	if t == models.DefaultReaction {
+		_, err = s.likeRepo.SetLike(userID, postID, on)
+	} else {
+		err = s.reactionRepo.SetReaction(userID, postID, t, on)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.reactionRepo.GetSummary(userID, postID)
+}
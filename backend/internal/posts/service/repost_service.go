@@ -0,0 +1,122 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"chaladshare_backend/internal/pagination"
+	"chaladshare_backend/internal/posts/models"
+	"chaladshare_backend/internal/posts/repository"
+)
+
+type RepostService interface {
+	Repost(userID, postID int, quote *string) (repostCount int, err error)
+	Unrepost(userID, postID int) (repostCount int, err error)
+	ListReposts(viewerID, postID int, cursor string, limit int) (*models.RepostPage, error)
+}
+
+type repostService struct {
+	repostRepo  repository.RepostRepository
+	postService PostService
+	cursors     *pagination.Codec
+}
+
+func NewRepostService(repostRepo repository.RepostRepository, postService PostService, cursors *pagination.Codec) RepostService {
+	return &repostService{repostRepo: repostRepo, postService: postService, cursors: cursors}
+}
+
+// ผู้ใช้ต้องเห็นโพสต์ต้นทางได้ (visibility + เผยแพร่แล้ว + ไม่อยู่ในถังขยะ)
+func (s *repostService) canView(viewerID, postID int) (string, error) {
+	ok, reason, err := s.postService.ViewPost(viewerID, postID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", models.ErrPostNotFound
+		}
+		return "", err
+	}
+	if !ok {
+		if reason == "not_found" {
+			return "", models.ErrPostNotFound
+		}
+		return "", models.ErrForbidden
+	}
+	return reason, nil
+}
+
+func normalizeQuote(quote *string) (*string, error) {
+	if quote == nil {
+		return nil, nil
+	}
+	q := strings.TrimSpace(*quote)
+	if q == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(q) > models.MaxQuoteLen {
+		return nil, models.ErrInvalidQuote
+	}
+	return &q, nil
+}
+
+// รีโพสต์ซ้ำ = แก้ quote
+func (s *repostService) Repost(userID, postID int, quote *string) (int, error) {
+	q, err := normalizeQuote(quote)
+	if err != nil {
+		return 0, err
+	}
+	reason, err := s.canView(userID, postID)
+	if err != nil {
+		return 0, err
+	}
+	if reason == "owner" {
+		return 0, models.ErrRepostOwnPost
+	}
+	if err := s.repostRepo.UpsertRepost(userID, postID, q); err != nil {
+		return 0, err
+	}
+	return s.repostRepo.RepostCount(postID)
+}
+
+func (s *repostService) Unrepost(userID, postID int) (int, error) {
+	if err := s.repostRepo.DeleteRepost(userID, postID); err != nil {
+		return 0, err
+	}
+	return s.repostRepo.RepostCount(postID)
+}
+
+func (s *repostService) decodeCursor(cursor string) (*pagination.Keyset, error) {
+	var k pagination.Keyset
+	ok, err := s.cursors.Decode(cursor, &k)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (s *repostService) ListReposts(viewerID, postID int, cursor string, limit int) (*models.RepostPage, error) {
+	after, err := s.decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.canView(viewerID, postID); err != nil {
+		return nil, err
+	}
+
+	limit = pagination.ClampLimit(limit)
+	items, err := s.repostRepo.ListReposts(postID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.RepostPage{Items: items}
+	if len(items) <= limit {
+		return page, nil
+	}
+	page.Items = items[:limit]
+	last := page.Items[limit-1]
+	if page.NextCursor, err = s.cursors.Encode(pagination.Keyset{CreatedAt: last.CreatedAt, ID: last.RepostID}); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
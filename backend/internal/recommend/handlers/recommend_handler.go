@@ -1,23 +1,30 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	expmodels "chaladshare_backend/internal/experiments/models"
+	expservice "chaladshare_backend/internal/experiments/service"
+	"chaladshare_backend/internal/pagination"
+	recmodels "chaladshare_backend/internal/recommend/models"
 	recservice "chaladshare_backend/internal/recommend/service"
 )
 
 type RecommendHandler struct {
-	svc recservice.RecommendService
+	svc    recservice.RecommendService
+	expSvc expservice.ExperimentService
 }
 
-func NewRecommendHandler(svc recservice.RecommendService) *RecommendHandler {
-	return &RecommendHandler{svc: svc}
+func NewRecommendHandler(svc recservice.RecommendService, expSvc expservice.ExperimentService) *RecommendHandler {
+	return &RecommendHandler{svc: svc, expSvc: expSvc}
 }
 
-// GET /api/v1/recommend?limit=3
+// GET /api/v1/recommend?limit=3&cursor=...
 func (h *RecommendHandler) GetRecommend(c *gin.Context) {
 	uid := c.GetInt("user_id")
 	if uid <= 0 {
@@ -31,16 +38,127 @@ func (h *RecommendHandler) GetRecommend(c *gin.Context) {
 			limit = n
 		}
 	}
-	if limit > 10 {
-		limit = 10
+	if limit > 50 {
+		limit = 50
 	}
 
-	posts, err := h.svc.RecommendForUser(uid, limit)
+	page, err := h.svc.GetRecommendPage(uid, c.Query("cursor"), limit)
 	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// cache ถูกสร้างใหม่ระหว่างเลื่อน: ให้ client โหลดหน้าแรกใหม่
+		if errors.Is(err, recmodels.ErrCacheExpired) {
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := gin.H{
+		"data":        page.Items,
+		"next_cursor": page.NextCursor,
+	}
+	// น้ำหนัก variant ถูกใช้ตอนสร้าง cache แล้ว (ผ่าน WeightsFunc) ที่นี่แค่บันทึก impression
+	if a := h.expSvc.Assign(uid, expmodels.SurfaceRecommend); a != nil {
+		ids := make([]int, 0, len(page.Items))
+		ranks := make([]int, 0, len(page.Items))
+		for _, it := range page.Items {
+			ids = append(ids, it.PostID)
+			ranks = append(ranks, it.Rank)
+		}
+		h.expSvc.TrackRankedImpressions(a, uid, ids, ranks)
+		resp["experiment"] = a
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ใส่ต่อท้าย route ที่เปลี่ยน signal ของผู้ใช้ (like/save/follow/feedback)
+// ถ้า handler ตอบสำเร็จ จะ invalidate cache ผลแนะนำของผู้ใช้คนนั้น
+func (h *RecommendHandler) InvalidateOnSuccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if st := c.Writer.Status(); st >= 200 && st < 300 {
+			h.svc.InvalidateUser(c.GetInt("user_id"))
+		}
+	}
+}
+
+// POST /api/v1/recommend/:post_id/feedback
+// body: {"type": "not_interested" | "hide_author" | "hide_tag", "tag": "..."}
+func (h *RecommendHandler) AddFeedback(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid <= 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	postID, err := strconv.Atoi(c.Param("post_id"))
+	if err != nil || postID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post_id"})
+		return
+	}
+
+	var req recmodels.FeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	fb, err := h.svc.AddFeedback(uid, postID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, recmodels.ErrInvalidFeedbackType), errors.Is(err, recmodels.ErrFeedbackTagRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "post or tag not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": fb})
+}
+
+// GET /api/v1/recommend/feedback
+func (h *RecommendHandler) ListFeedback(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid <= 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	items, err := h.svc.ListFeedback(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+// DELETE /api/v1/recommend/feedback/:id (undo)
+func (h *RecommendHandler) DeleteFeedback(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid <= 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.svc.DeleteFeedback(uid, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "feedback not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{
-		"data": posts,
-	})
+	c.Status(http.StatusNoContent)
 }
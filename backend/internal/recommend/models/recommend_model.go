@@ -1,9 +1,15 @@
 package models
 
+import (
+	"errors"
+	"time"
+)
+
 type Seedpost struct {
-	PostID int
-	Label  string
-	Vec    []float64
+	PostID    int
+	Label     string
+	ClusterID *int
+	Vec       []float64
 }
 
 type Candidatepost struct {
@@ -22,7 +28,11 @@ type Candidatepost struct {
 	IsLiked   bool `json:"is_liked"`
 	IsSaved   bool `json:"is_saved"`
 
-	Vec []float64 `json:"-"`
+	Rank int `json:"-"` // cache_rank (ตำแหน่งจริงในรายการแนะนำ)
+
+	Vec       []float64 `json:"-"`
+	ClusterID *int      `json:"-"`
+	CFScore   float64   `json:"-"`
 }
 
 type RecommendPost struct {
@@ -39,3 +49,50 @@ type RecommendPost struct {
 
 	Score float64 `json:"score,omitempty"`
 }
+
+const (
+	FeedbackNotInterested = "not_interested"
+	FeedbackHideAuthor    = "hide_author"
+	FeedbackHideTag       = "hide_tag"
+)
+
+var (
+	ErrInvalidFeedbackType = errors.New("invalid feedback type")
+	ErrFeedbackTagRequired = errors.New("tag is required")
+	ErrCacheExpired        = errors.New("recommendations changed, reload the list")
+)
+
+type FeedbackRequest struct {
+	Type string `json:"type" binding:"required"`
+	Tag  string `json:"tag"`
+}
+
+type Feedback struct {
+	FeedbackID int       `json:"feedback_id"`
+	Type       string    `json:"type"`
+	PostID     *int      `json:"post_id,omitempty"`
+	AuthorID   *int      `json:"author_id,omitempty"`
+	AuthorName *string   `json:"author_name,omitempty"`
+	TagName    *string   `json:"tag_name,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// สถานะ cache ผลแนะนำของผู้ใช้
+type CacheMeta struct {
+	GeneratedAt time.Time
+	ExpiresAt   time.Time
+	Stale       bool
+}
+
+// cursor ของผลแนะนำ: ชุด cache ที่อ่าน (Generated = meta_generated_at) + rank ล่าสุดของหน้าก่อน
+// cache ถูกสร้างใหม่ระหว่างเลื่อน = rank เดิมไม่มีความหมาย (ErrCacheExpired)
+type RecommendCursor struct {
+	Generated time.Time `json:"g"`
+	Rank      int       `json:"r"`
+}
+
+// หน้าผลแนะนำจาก cache
+type RecommendPage struct {
+	Items      []Candidatepost `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
@@ -1,17 +1,42 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
+
+	"github.com/lib/pq"
 
 	recmodels "chaladshare_backend/internal/recommend/models"
+	"chaladshare_backend/internal/visibility"
 )
 
 type RecommendRepo interface {
 	GetLatestLikedSeed(userID int) (*recmodels.Seedpost, error)
 	ListCandidates(userID, seedPostID int, label string, limit int) ([]recmodels.Candidatepost, error)
 	ListFallback(userID int, limit int) ([]recmodels.Candidatepost, error)
+	ListNegativeVectors(userID int, limit int) ([][]float64, error)
+
+	// collaborative filtering
+	ListCollaborative(userID int, limit int) ([]recmodels.Candidatepost, error)
+	RefreshPostSimilarity(minCoCount, topK, userCap int) (int, error)
+
+	// cold-start จากหัวข้อที่ผู้ใช้เลือกตอน onboarding
+	ListByInterests(userID int, limit int) ([]recmodels.Candidatepost, error)
+
+	// cache ผลแนะนำ
+	GetCacheMeta(userID int) (*recmodels.CacheMeta, error)
+	ReplaceCache(userID int, postIDs []int, ttl time.Duration) error
+	MarkCacheStale(userID int) error
+	ClearAllCaches() (int, error)
+	ListCachePage(userID int, after *recmodels.RecommendCursor, limit int) ([]recmodels.Candidatepost, time.Time, error)
+
+	// feedback
+	AddFeedback(userID, postID int, fbType, tag string) (*recmodels.Feedback, error)
+	ListFeedback(userID int) ([]recmodels.Feedback, error)
+	DeleteFeedback(userID, feedbackID int) error
 }
 
 type recommendRepo struct{ db *sql.DB }
@@ -25,10 +50,36 @@ func nsToStr(ns sql.NullString) string {
 	return ""
 }
 
+// ตัดโพสต์ที่ผู้ใช้กด "ไม่สนใจ" / ซ่อนผู้เขียน / ซ่อนแท็ก ออกจากผลแนะนำ ($1 = user_id)
+const qExcludeFeedback = `
+		NOT EXISTS (
+			SELECT 1 FROM recommend_feedback rf
+			WHERE rf.feedback_user_id = $1
+			AND (
+				(rf.feedback_type = 'not_interested' AND rf.feedback_post_id = p.post_id)
+				OR (rf.feedback_type = 'hide_author' AND rf.feedback_author_id = p.post_author_user_id)
+				OR (rf.feedback_type = 'hide_tag' AND EXISTS (
+					SELECT 1 FROM post_tags pt3
+					WHERE pt3.post_tag_post_id = p.post_id
+					AND pt3.post_tag_tag_id = rf.feedback_tag_id
+				))
+			)
+		)
+		`
+
+func nullIntPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
+
 const qSeed = `
 		SELECT
 		p.post_id,
 		df.style_label,
+		df.cluster_id,
 		df.style_vector_raw
 		FROM likes l
 		JOIN posts p
@@ -69,7 +120,8 @@ const qCandidates = `
 			JOIN tags t ON t.tag_id = pt.post_tag_tag_id
 			WHERE pt.post_tag_post_id = p.post_id
 		) AS tags,
-		df.style_vector_raw
+		df.style_vector_raw,
+		df.cluster_id
 		FROM posts p
 		JOIN documents d
 		ON d.document_id = p.post_document_id
@@ -85,21 +137,14 @@ const qCandidates = `
 		AND df.style_label = $2
 		AND df.style_vector_raw IS NOT NULL
 		AND p.post_id <> $3
+		AND p.post_author_user_id <> $1
 		AND NOT EXISTS (
 		SELECT 1 FROM likes l2
 		WHERE l2.like_user_id = $1 AND l2.like_post_id = p.post_id
 		)
-		AND (
-			p.post_visibility = 'public'
-			OR (
-			p.post_visibility = 'friends'
-			AND EXISTS (
-				SELECT 1 FROM friendships f
-				WHERE (f.user_id = LEAST($1, p.post_author_user_id)
-				AND f.friend_id = GREATEST($1, p.post_author_user_id))
-			)
-			)
-		)
+		AND ` + visibility.PostVisible + `
+		AND ` + visibility.PostPublished + `
+		AND ` + qExcludeFeedback + `
 		ORDER BY p.post_created_at DESC
 		LIMIT $4;
 		`
@@ -140,29 +185,272 @@ const qFallback = `
 		ON up.profile_user_id = u.user_id
 		LEFT JOIN post_stats ps
 		ON ps.post_stats_post_id = p.post_id
-		WHERE
-		-- visibility เงื่อนไขเหมือนเดิม
-		(
-			p.post_visibility = 'public'
-			OR (
-			p.post_visibility = 'friends'
-			AND EXISTS (
-				SELECT 1 FROM friendships f
-				WHERE (f.user_id = LEAST($1, p.post_author_user_id)
-				AND f.friend_id = GREATEST($1, p.post_author_user_id))
-			)
-			)
-		)
+		WHERE p.post_author_user_id <> $1
+		AND ` + visibility.PostVisible + `
+		AND ` + visibility.PostPublished + `
+		AND ` + qExcludeFeedback + `
 		ORDER BY COALESCE(ps.post_like_count, 0) DESC, p.post_created_at DESC
 		LIMIT $2;
 		`
 
+// vector ของโพสต์ที่กด "ไม่สนใจ" ล่าสุด ใช้เป็น negative signal
+const qNegativeVectors = `
+		SELECT df.style_vector_raw
+		FROM recommend_feedback rf
+		JOIN posts p
+		ON p.post_id = rf.feedback_post_id
+		JOIN document_features df
+		ON df.document_id = p.post_document_id
+		WHERE rf.feedback_user_id = $1
+		AND rf.feedback_type = 'not_interested'
+		AND df.feature_status = 'done'
+		AND df.style_vector_raw IS NOT NULL
+		ORDER BY rf.feedback_created_at DESC
+		LIMIT $2;
+		`
+
+// โพสต์ที่คล้ายกับโพสต์ที่ผู้ใช้เคย like/save (จาก post_similarity)
+const qCollaborative = `
+		WITH mine AS (
+			SELECT like_post_id AS post_id FROM likes WHERE like_user_id = $1
+			UNION
+			SELECT save_post_id FROM saved_posts WHERE save_user_id = $1
+		),
+		cf AS (
+			SELECT s.similar_post_id AS post_id, SUM(s.similarity) AS cf_score
+			FROM post_similarity s
+			JOIN mine m ON m.post_id = s.post_id
+			WHERE s.similar_post_id NOT IN (SELECT post_id FROM mine)
+			GROUP BY s.similar_post_id
+		)
+		SELECT
+		p.post_id,
+		p.post_author_user_id,
+		p.post_title,
+		p.post_description,
+		p.post_cover_url,
+		p.post_visibility,
+		u.username AS author_name,
+		up.avatar_url AS author_img,
+		COALESCE(ps.post_like_count, 0) AS like_count,
+		FALSE AS is_liked,
+		EXISTS (
+			SELECT 1 FROM saved_posts sp
+			WHERE sp.save_user_id = $1 AND sp.save_post_id = p.post_id
+		) AS is_saved,
+		( SELECT string_agg(t.tag_name, ', ')
+			FROM post_tags pt
+			JOIN tags t ON t.tag_id = pt.post_tag_tag_id
+			WHERE pt.post_tag_post_id = p.post_id
+		) AS tags,
+		CASE WHEN df.feature_status = 'done' THEN df.style_vector_raw END,
+		cf.cf_score
+		FROM cf
+		JOIN posts p
+		ON p.post_id = cf.post_id
+		LEFT JOIN documents d
+		ON d.document_id = p.post_document_id
+		LEFT JOIN document_features df
+		ON df.document_id = d.document_id
+		JOIN users u
+		ON u.user_id = p.post_author_user_id
+		LEFT JOIN user_profiles up
+		ON up.profile_user_id = u.user_id
+		LEFT JOIN post_stats ps
+		ON ps.post_stats_post_id = p.post_id
+		WHERE p.post_author_user_id <> $1
+		AND ` + visibility.PostVisible + `
+		AND ` + visibility.PostPublished + `
+		AND ` + qExcludeFeedback + `
+		ORDER BY cf.cf_score DESC
+		LIMIT $2;
+		`
+
+// โพสต์ที่มีแท็กอยู่ในหัวข้อที่ผู้ใช้สนใจ (user_interests → topic_tags → post_tags)
+const qByInterests = `
+		WITH matched AS (
+			SELECT pt.post_tag_post_id AS post_id, COUNT(DISTINCT ui.interest_topic_id) AS topic_hits
+			FROM user_interests ui
+			JOIN topic_tags tt ON tt.topic_tag_topic_id = ui.interest_topic_id
+			JOIN post_tags pt ON pt.post_tag_tag_id = tt.topic_tag_tag_id
+			WHERE ui.interest_user_id = $1
+			GROUP BY pt.post_tag_post_id
+		)
+		SELECT
+		p.post_id,
+		p.post_author_user_id,
+		p.post_title,
+		p.post_description,
+		p.post_cover_url,
+		p.post_visibility,
+		u.username AS author_name,
+		up.avatar_url AS author_img,
+		COALESCE(ps.post_like_count, 0) AS like_count,
+		EXISTS (
+			SELECT 1 FROM likes l2
+			WHERE l2.like_user_id = $1 AND l2.like_post_id = p.post_id
+		) AS is_liked,
+		EXISTS (
+			SELECT 1 FROM saved_posts sp
+			WHERE sp.save_user_id = $1 AND sp.save_post_id = p.post_id
+		) AS is_saved,
+		( SELECT string_agg(t.tag_name, ', ')
+			FROM post_tags pt
+			JOIN tags t ON t.tag_id = pt.post_tag_tag_id
+			WHERE pt.post_tag_post_id = p.post_id
+		) AS tags,
+		CASE WHEN df.feature_status = 'done' THEN df.style_vector_raw END
+		FROM matched m
+		JOIN posts p
+		ON p.post_id = m.post_id
+		LEFT JOIN documents d
+		ON d.document_id = p.post_document_id
+		LEFT JOIN document_features df
+		ON df.document_id = d.document_id
+		JOIN users u
+		ON u.user_id = p.post_author_user_id
+		LEFT JOIN user_profiles up
+		ON up.profile_user_id = u.user_id
+		LEFT JOIN post_stats ps
+		ON ps.post_stats_post_id = p.post_id
+		WHERE p.post_author_user_id <> $1
+		AND ` + visibility.PostVisible + `
+		AND ` + visibility.PostPublished + `
+		AND ` + qExcludeFeedback + `
+		ORDER BY m.topic_hits DESC, COALESCE(ps.post_like_count, 0) DESC, p.post_created_at DESC
+		LIMIT $2;
+		`
+
+// อ่านหน้าผลแนะนำจาก cache พร้อมข้อมูลโพสต์ล่าสุด
+// เช็ค visibility / feedback ซ้ำตอนอ่าน เพื่อให้ซ่อนโพสต์ได้ทันทีโดยไม่ต้องรอ refresh
+const qCachePage = `
+		SELECT
+		rc.cache_rank,
+		p.post_id,
+		p.post_author_user_id,
+		p.post_title,
+		p.post_description,
+		p.post_cover_url,
+		p.post_visibility,
+		u.username AS author_name,
+		up.avatar_url AS author_img,
+		COALESCE(ps.post_like_count, 0) AS like_count,
+		EXISTS (
+			SELECT 1 FROM likes l2
+			WHERE l2.like_user_id = $1 AND l2.like_post_id = p.post_id
+		) AS is_liked,
+		EXISTS (
+			SELECT 1 FROM saved_posts sp
+			WHERE sp.save_user_id = $1 AND sp.save_post_id = p.post_id
+		) AS is_saved,
+		( SELECT string_agg(t.tag_name, ', ')
+			FROM post_tags pt
+			JOIN tags t ON t.tag_id = pt.post_tag_tag_id
+			WHERE pt.post_tag_post_id = p.post_id
+		) AS tags
+		FROM recommend_cache rc
+		JOIN posts p
+		ON p.post_id = rc.cache_post_id
+		JOIN users u
+		ON u.user_id = p.post_author_user_id
+		LEFT JOIN user_profiles up
+		ON up.profile_user_id = u.user_id
+		LEFT JOIN post_stats ps
+		ON ps.post_stats_post_id = p.post_id
+		WHERE rc.cache_user_id = $1
+		AND rc.cache_rank > $2
+		AND p.post_author_user_id <> $1
+		AND ` + visibility.PostVisible + `
+		AND ` + visibility.PostPublished + `
+		AND ` + qExcludeFeedback + `
+		ORDER BY rc.cache_rank
+		LIMIT $3;
+		`
+
+// คำนวณ item-item cosine similarity จาก co-occurrence ของ likes + saved_posts
+// sim(i, j) = co(i, j) / sqrt(n(i) * n(j)) เก็บไว้ top-K ต่อโพสต์
+// ใช้เฉพาะ interaction ล่าสุดไม่เกิน $2 รายการต่อผู้ใช้ และคำนวณเฉพาะโพสต์ใน similarity_dirty
+const qSimilarityScored = `
+		CREATE TEMP TABLE similarity_scored ON COMMIT DROP AS
+		WITH all_interactions AS (
+			SELECT user_id, post_id, MAX(at) AS at
+			FROM (
+				SELECT like_user_id AS user_id, like_post_id AS post_id, like_created_at AS at FROM likes
+				UNION ALL
+				SELECT save_user_id, save_post_id, save_created_at FROM saved_posts
+			) x
+			GROUP BY user_id, post_id
+		),
+		interactions AS (
+			SELECT user_id, post_id
+			FROM (
+				SELECT user_id, post_id,
+				ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY at DESC NULLS LAST, post_id DESC) AS rn
+				FROM all_interactions
+			) r
+			WHERE rn <= $2
+		),
+		post_counts AS (
+			SELECT post_id, COUNT(*) AS n
+			FROM interactions
+			GROUP BY post_id
+		),
+		pairs AS (
+			SELECT a.post_id, b.post_id AS similar_post_id, COUNT(*) AS co
+			FROM interactions a
+			JOIN similarity_dirty d ON d.post_id = a.post_id
+			JOIN interactions b
+			ON b.user_id = a.user_id AND b.post_id <> a.post_id
+			GROUP BY a.post_id, b.post_id
+			HAVING COUNT(*) >= $1
+		)
+		SELECT pr.post_id, pr.similar_post_id, pr.co,
+		pr.co::float8 / SQRT(ca.n::float8 * cb.n::float8) AS similarity
+		FROM pairs pr
+		JOIN post_counts ca ON ca.post_id = pr.post_id
+		JOIN post_counts cb ON cb.post_id = pr.similar_post_id;
+		`
+
+// โพสต์ที่มี like/save ใหม่ตั้งแต่รอบก่อน ($1 = NULL คือยังไม่เคยคำนวณ เอาทุกโพสต์)
+// ย้อนเผื่อไว้ 5 นาที กัน transaction ที่ commit หลังรอบก่อนเริ่มแต่เวลาเก่ากว่า
+const qSimilarityDirty = `
+		CREATE TEMP TABLE similarity_dirty ON COMMIT DROP AS
+		SELECT like_post_id AS post_id FROM likes
+		WHERE $1::timestamptz IS NULL OR like_created_at > $1::timestamptz - interval '5 minutes'
+		UNION
+		SELECT save_post_id FROM saved_posts
+		WHERE $1::timestamptz IS NULL OR save_created_at > $1::timestamptz - interval '5 minutes';
+		`
+
+const qInsertSimilarity = `
+		INSERT INTO post_similarity (post_id, similar_post_id, similarity, co_count, updated_at)
+		SELECT post_id, similar_post_id, similarity, co, now()
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (
+				PARTITION BY post_id ORDER BY similarity DESC, co DESC, similar_post_id
+			) AS rn
+			FROM similarity_scored
+		) ranked
+		WHERE rn <= $1;
+		`
+
+// sim สมมาตร: อัปเดตคะแนนฝั่งกลับในรายการของโพสต์อื่นที่มีคู่นี้อยู่แล้ว
+const qUpdateReverseSimilarity = `
+		UPDATE post_similarity s
+		SET similarity = sc.similarity, co_count = sc.co, updated_at = now()
+		FROM similarity_scored sc
+		WHERE s.post_id = sc.similar_post_id
+		AND s.similar_post_id = sc.post_id
+		AND NOT EXISTS (SELECT 1 FROM similarity_dirty d WHERE d.post_id = s.post_id);
+		`
+
 func (r *recommendRepo) GetLatestLikedSeed(userID int) (*recmodels.Seedpost, error) {
 	var postID int
 	var label string
+	var clusterID sql.NullInt64
 	var raw []byte
 
-	if err := r.db.QueryRow(qSeed, userID).Scan(&postID, &label, &raw); err != nil {
+	if err := r.db.QueryRow(qSeed, userID).Scan(&postID, &label, &clusterID, &raw); err != nil {
 		return nil, err
 	}
 
@@ -171,7 +459,7 @@ func (r *recommendRepo) GetLatestLikedSeed(userID int) (*recmodels.Seedpost, err
 		return nil, fmt.Errorf("unmarshal seed vector: %w", err)
 	}
 
-	return &recmodels.Seedpost{PostID: postID, Label: label, Vec: vec}, nil
+	return &recmodels.Seedpost{PostID: postID, Label: label, ClusterID: nullIntPtr(clusterID), Vec: vec}, nil
 }
 
 func (r *recommendRepo) ListCandidates(userID, seedPostID int, label string, limit int) ([]recmodels.Candidatepost, error) {
@@ -189,6 +477,7 @@ func (r *recommendRepo) ListCandidates(userID, seedPostID int, label string, lim
 		IsSaved     bool
 		Tags        sql.NullString
 		RawVec      []byte
+		ClusterID   sql.NullInt64
 	}
 
 	rows, err := r.db.Query(qCandidates, userID, label, seedPostID, limit)
@@ -206,6 +495,7 @@ func (r *recommendRepo) ListCandidates(userID, seedPostID int, label string, lim
 			&rr.LikeCount, &rr.IsLiked, &rr.IsSaved,
 			&rr.Tags,
 			&rr.RawVec,
+			&rr.ClusterID,
 		); err != nil {
 			return nil, err
 		}
@@ -230,6 +520,7 @@ func (r *recommendRepo) ListCandidates(userID, seedPostID int, label string, lim
 			IsLiked:     rr.IsLiked,
 			IsSaved:     rr.IsSaved,
 			Vec:         vec,
+			ClusterID:   nullIntPtr(rr.ClusterID),
 		})
 	}
 
@@ -302,3 +593,488 @@ func (r *recommendRepo) ListFallback(userID int, limit int) ([]recmodels.Candida
 	}
 	return out, nil
 }
+
+func (r *recommendRepo) ListNegativeVectors(userID int, limit int) ([][]float64, error) {
+	rows, err := r.db.Query(qNegativeVectors, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([][]float64, 0, limit)
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var vec []float64
+		if err := json.Unmarshal(raw, &vec); err != nil {
+			continue
+		}
+		out = append(out, vec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ผู้ใช้ต้องมองเห็นโพสต์ได้ ($1 = ผู้ชม) ไม่งั้นถือว่าไม่พบ (ไม่บอกว่ามีโพสต์อยู่)
+func (r *recommendRepo) AddFeedback(userID, postID int, fbType, tag string) (*recmodels.Feedback, error) {
+	var q string
+	args := []any{userID, postID}
+
+	switch fbType {
+	case recmodels.FeedbackNotInterested:
+		q = `
+		INSERT INTO recommend_feedback (feedback_user_id, feedback_type, feedback_post_id)
+		SELECT $1, 'not_interested', p.post_id
+		FROM posts p
+		WHERE p.post_id = $2
+		  AND ` + visibility.PostPublished + `
+		  AND ` + visibility.PostVisible + `
+		ON CONFLICT (feedback_user_id, feedback_post_id) WHERE feedback_type = 'not_interested'
+		DO UPDATE SET feedback_created_at = now()
+		RETURNING feedback_id;
+		`
+	case recmodels.FeedbackHideAuthor:
+		q = `
+		INSERT INTO recommend_feedback (feedback_user_id, feedback_type, feedback_post_id, feedback_author_id)
+		SELECT $1, 'hide_author', p.post_id, p.post_author_user_id
+		FROM posts p
+		WHERE p.post_id = $2 AND p.post_author_user_id <> $1
+		  AND ` + visibility.PostPublished + `
+		  AND ` + visibility.PostVisible + `
+		ON CONFLICT (feedback_user_id, feedback_author_id) WHERE feedback_type = 'hide_author'
+		DO UPDATE SET feedback_created_at = now(), feedback_post_id = EXCLUDED.feedback_post_id
+		RETURNING feedback_id;
+		`
+	case recmodels.FeedbackHideTag:
+		// แท็กต้องติดอยู่กับโพสต์นี้จริง
+		q = `
+		INSERT INTO recommend_feedback (feedback_user_id, feedback_type, feedback_post_id, feedback_tag_id)
+		SELECT $1, 'hide_tag', pt.post_tag_post_id, pt.post_tag_tag_id
+		FROM post_tags pt
+		JOIN tags t ON t.tag_id = pt.post_tag_tag_id
+		JOIN posts p ON p.post_id = pt.post_tag_post_id
+		WHERE pt.post_tag_post_id = $2 AND t.tag_name = $3
+		  AND ` + visibility.PostPublished + `
+		  AND ` + visibility.PostVisible + `
+		ON CONFLICT (feedback_user_id, feedback_tag_id) WHERE feedback_type = 'hide_tag'
+		DO UPDATE SET feedback_created_at = now(), feedback_post_id = EXCLUDED.feedback_post_id
+		RETURNING feedback_id;
+		`
+		args = append(args, tag)
+	default:
+		return nil, recmodels.ErrInvalidFeedbackType
+	}
+
+	var id int
+	if err := r.db.QueryRow(q, args...).Scan(&id); err != nil {
+		return nil, err
+	}
+	return r.getFeedback(userID, id)
+}
+
+const qFeedbackSelect = `
+		SELECT rf.feedback_id, rf.feedback_type, rf.feedback_post_id,
+		rf.feedback_author_id, u.username, t.tag_name, rf.feedback_created_at
+		FROM recommend_feedback rf
+		LEFT JOIN users u ON u.user_id = rf.feedback_author_id
+		LEFT JOIN tags t ON t.tag_id = rf.feedback_tag_id
+		WHERE rf.feedback_user_id = $1
+		`
+
+func scanFeedback(sc interface{ Scan(...any) error }) (*recmodels.Feedback, error) {
+	var (
+		fb         recmodels.Feedback
+		postID     sql.NullInt64
+		authorID   sql.NullInt64
+		authorName sql.NullString
+		tagName    sql.NullString
+	)
+	if err := sc.Scan(&fb.FeedbackID, &fb.Type, &postID, &authorID, &authorName, &tagName, &fb.CreatedAt); err != nil {
+		return nil, err
+	}
+	if postID.Valid {
+		v := int(postID.Int64)
+		fb.PostID = &v
+	}
+	if authorID.Valid {
+		v := int(authorID.Int64)
+		fb.AuthorID = &v
+	}
+	if authorName.Valid {
+		fb.AuthorName = &authorName.String
+	}
+	if tagName.Valid {
+		fb.TagName = &tagName.String
+	}
+	return &fb, nil
+}
+
+func (r *recommendRepo) getFeedback(userID, feedbackID int) (*recmodels.Feedback, error) {
+	return scanFeedback(r.db.QueryRow(qFeedbackSelect+` AND rf.feedback_id = $2;`, userID, feedbackID))
+}
+
+func (r *recommendRepo) ListFeedback(userID int) ([]recmodels.Feedback, error) {
+	rows, err := r.db.Query(qFeedbackSelect+` ORDER BY rf.feedback_created_at DESC;`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]recmodels.Feedback, 0, 16)
+	for rows.Next() {
+		fb, err := scanFeedback(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *fb)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *recommendRepo) DeleteFeedback(userID, feedbackID int) error {
+	res, err := r.db.Exec(`DELETE FROM recommend_feedback WHERE feedback_id = $1 AND feedback_user_id = $2`, feedbackID, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *recommendRepo) ListCollaborative(userID int, limit int) ([]recmodels.Candidatepost, error) {
+	type row struct {
+		PostID      int
+		AuthorID    int
+		Title       string
+		Description sql.NullString
+		CoverURL    sql.NullString
+		Visibility  string
+		AuthorName  string
+		AuthorImg   sql.NullString
+		LikeCount   int
+		IsLiked     bool
+		IsSaved     bool
+		Tags        sql.NullString
+		RawVec      []byte
+		CFScore     float64
+	}
+
+	rows, err := r.db.Query(qCollaborative, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]recmodels.Candidatepost, 0, 64)
+	for rows.Next() {
+		var rr row
+		if err := rows.Scan(
+			&rr.PostID, &rr.AuthorID, &rr.Title, &rr.Description, &rr.CoverURL, &rr.Visibility,
+			&rr.AuthorName, &rr.AuthorImg,
+			&rr.LikeCount, &rr.IsLiked, &rr.IsSaved,
+			&rr.Tags,
+			&rr.RawVec,
+			&rr.CFScore,
+		); err != nil {
+			return nil, err
+		}
+
+		var vec []float64
+		if len(rr.RawVec) > 0 {
+			_ = json.Unmarshal(rr.RawVec, &vec)
+		}
+
+		out = append(out, recmodels.Candidatepost{
+			PostID:      rr.PostID,
+			AuthorID:    rr.AuthorID,
+			Title:       rr.Title,
+			Description: nsToStr(rr.Description),
+			CoverURL:    nsToStr(rr.CoverURL),
+			Visibility:  rr.Visibility,
+			AuthorName:  rr.AuthorName,
+			AuthorImg:   nsToStr(rr.AuthorImg),
+			Tags:        nsToStr(rr.Tags),
+			LikeCount:   rr.LikeCount,
+			IsLiked:     rr.IsLiked,
+			IsSaved:     rr.IsSaved,
+			Vec:         vec,
+			CFScore:     rr.CFScore,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// สร้าง post_similarity ใหม่ทั้งตารางใน transaction เดียว (อ่านระหว่างรีเฟรชจะเห็นชุดเดิม)
+// คำนวณใหม่เฉพาะโพสต์ที่มี like/save ตั้งแต่รอบก่อน คืนจำนวนคู่ที่เขียนใหม่
+func (r *recommendRepo) RefreshPostSimilarity(minCoCount, topK, userCap int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// ล็อกแถวสถานะไว้ กันสอง instance คำนวณซ้อนกัน
+	var since sql.NullTime
+	err = tx.QueryRow(`SELECT refreshed_at FROM post_similarity_refreshes FOR UPDATE`).Scan(&since)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("read last refresh: %w", err)
+	}
+
+	if _, err := tx.Exec(qSimilarityDirty, since); err != nil {
+		return 0, fmt.Errorf("collect touched posts: %w", err)
+	}
+	if _, err := tx.Exec(qSimilarityScored, minCoCount, userCap); err != nil {
+		return 0, fmt.Errorf("compute post_similarity: %w", err)
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM post_similarity s
+		USING similarity_dirty d
+		WHERE s.post_id = d.post_id;`); err != nil {
+		return 0, fmt.Errorf("clear touched post_similarity: %w", err)
+	}
+
+	res, err := tx.Exec(qInsertSimilarity, topK)
+	if err != nil {
+		return 0, fmt.Errorf("insert post_similarity: %w", err)
+	}
+	n, _ := res.RowsAffected()
+
+	if _, err := tx.Exec(qUpdateReverseSimilarity); err != nil {
+		return 0, fmt.Errorf("update reverse post_similarity: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO post_similarity_refreshes (refresh_key, refreshed_at) VALUES (true, now())
+		ON CONFLICT (refresh_key) DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at;`); err != nil {
+		return 0, fmt.Errorf("mark post_similarity refresh: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return int(n), nil
+}
+
+func (r *recommendRepo) ListByInterests(userID int, limit int) ([]recmodels.Candidatepost, error) {
+	type row struct {
+		PostID      int
+		AuthorID    int
+		Title       string
+		Description sql.NullString
+		CoverURL    sql.NullString
+		Visibility  string
+		AuthorName  string
+		AuthorImg   sql.NullString
+		LikeCount   int
+		IsLiked     bool
+		IsSaved     bool
+		Tags        sql.NullString
+		RawVec      []byte
+	}
+
+	rows, err := r.db.Query(qByInterests, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]recmodels.Candidatepost, 0, limit)
+	for rows.Next() {
+		var rr row
+		if err := rows.Scan(
+			&rr.PostID, &rr.AuthorID, &rr.Title, &rr.Description, &rr.CoverURL, &rr.Visibility,
+			&rr.AuthorName, &rr.AuthorImg,
+			&rr.LikeCount, &rr.IsLiked, &rr.IsSaved,
+			&rr.Tags,
+			&rr.RawVec,
+		); err != nil {
+			return nil, err
+		}
+
+		var vec []float64
+		if len(rr.RawVec) > 0 {
+			_ = json.Unmarshal(rr.RawVec, &vec)
+		}
+
+		out = append(out, recmodels.Candidatepost{
+			PostID:      rr.PostID,
+			AuthorID:    rr.AuthorID,
+			Title:       rr.Title,
+			Description: nsToStr(rr.Description),
+			CoverURL:    nsToStr(rr.CoverURL),
+			Visibility:  rr.Visibility,
+			AuthorName:  rr.AuthorName,
+			AuthorImg:   nsToStr(rr.AuthorImg),
+			Tags:        nsToStr(rr.Tags),
+			LikeCount:   rr.LikeCount,
+			IsLiked:     rr.IsLiked,
+			IsSaved:     rr.IsSaved,
+			Vec:         vec,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *recommendRepo) GetCacheMeta(userID int) (*recmodels.CacheMeta, error) {
+	var m recmodels.CacheMeta
+	err := r.db.QueryRow(`
+		SELECT meta_generated_at, meta_expires_at, meta_stale
+		FROM recommend_cache_meta
+		WHERE meta_user_id = $1`, userID).Scan(&m.GeneratedAt, &m.ExpiresAt, &m.Stale)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// แทนที่ cache ทั้งชุดของผู้ใช้ (rank เริ่มที่ 1 ตามลำดับ postIDs)
+func (r *recommendRepo) ReplaceCache(userID int, postIDs []int, ttl time.Duration) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM recommend_cache WHERE cache_user_id = $1`, userID); err != nil {
+		return fmt.Errorf("clear recommend_cache: %w", err)
+	}
+
+	if len(postIDs) > 0 {
+		if _, err := tx.Exec(`
+			INSERT INTO recommend_cache (cache_user_id, cache_rank, cache_post_id)
+			SELECT $1, v.rank, v.post_id
+			FROM UNNEST($2::int[]) WITH ORDINALITY AS v(post_id, rank);
+		`, userID, pq.Array(postIDs)); err != nil {
+			return fmt.Errorf("insert recommend_cache: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO recommend_cache_meta (meta_user_id, meta_generated_at, meta_expires_at, meta_stale)
+		VALUES ($1, now(), now() + make_interval(secs => $2), FALSE)
+		ON CONFLICT (meta_user_id) DO UPDATE SET
+			meta_generated_at = EXCLUDED.meta_generated_at,
+			meta_expires_at   = EXCLUDED.meta_expires_at,
+			meta_stale        = FALSE;
+	`, userID, ttl.Seconds()); err != nil {
+		return fmt.Errorf("upsert recommend_cache_meta: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+func (r *recommendRepo) MarkCacheStale(userID int) error {
+	_, err := r.db.Exec(`UPDATE recommend_cache_meta SET meta_stale = TRUE WHERE meta_user_id = $1`, userID)
+	return err
+}
+
+// ลบ cache ของทุกคน: การอ่านครั้งถัดไปสร้างใหม่ทันที (ไม่เสิร์ฟผลเก่าระหว่างรอ refresh)
+func (r *recommendRepo) ClearAllCaches() (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM recommend_cache_meta`)
+	if err != nil {
+		return 0, fmt.Errorf("clear recommend_cache_meta: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM recommend_cache`); err != nil {
+		return 0, fmt.Errorf("clear recommend_cache: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// คืนรายการในหน้า + เวลาสร้างของชุด cache ที่อ่าน
+// อ่าน meta และรายการใน snapshot เดียว (ReplaceCache สลับทั้งชุดใน transaction)
+// after มาจากชุดอื่น = ErrCacheExpired
+func (r *recommendRepo) ListCachePage(userID int, after *recmodels.RecommendCursor, limit int) ([]recmodels.Candidatepost, time.Time, error) {
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	var generated time.Time
+	err = tx.QueryRow(`SELECT meta_generated_at FROM recommend_cache_meta WHERE meta_user_id = $1`, userID).Scan(&generated)
+	switch {
+	case err == sql.ErrNoRows:
+		if after != nil {
+			return nil, time.Time{}, recmodels.ErrCacheExpired
+		}
+		return []recmodels.Candidatepost{}, time.Time{}, nil
+	case err != nil:
+		return nil, time.Time{}, err
+	}
+	afterRank := 0
+	if after != nil {
+		if !after.Generated.Equal(generated) {
+			return nil, time.Time{}, recmodels.ErrCacheExpired
+		}
+		afterRank = after.Rank
+	}
+
+	rows, err := tx.Query(qCachePage, userID, afterRank, limit)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer rows.Close()
+
+	out := make([]recmodels.Candidatepost, 0, limit)
+	for rows.Next() {
+		var (
+			c           recmodels.Candidatepost
+			rank        int
+			description sql.NullString
+			coverURL    sql.NullString
+			authorImg   sql.NullString
+			tags        sql.NullString
+		)
+		if err := rows.Scan(
+			&rank,
+			&c.PostID, &c.AuthorID, &c.Title, &description, &coverURL, &c.Visibility,
+			&c.AuthorName, &authorImg,
+			&c.LikeCount, &c.IsLiked, &c.IsSaved,
+			&tags,
+		); err != nil {
+			return nil, time.Time{}, err
+		}
+		c.Description = nsToStr(description)
+		c.CoverURL = nsToStr(coverURL)
+		c.AuthorImg = nsToStr(authorImg)
+		c.Tags = nsToStr(tags)
+
+		c.Rank = rank
+		out = append(out, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, time.Time{}, err
+	}
+	return out, generated, nil
+}
@@ -3,23 +3,116 @@ package service
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"log"
 	"math"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"chaladshare_backend/internal/pagination"
 	recmodels "chaladshare_backend/internal/recommend/models"
 	recrepo "chaladshare_backend/internal/recommend/repository"
+	"chaladshare_backend/internal/tagname"
 )
 
 type RecommendService interface {
 	RecommendForUser(userID int, limit int) ([]recmodels.Candidatepost, error)
+
+	AddFeedback(userID, postID int, req recmodels.FeedbackRequest) (*recmodels.Feedback, error)
+	ListFeedback(userID int) ([]recmodels.Feedback, error)
+	DeleteFeedback(userID, feedbackID int) error
+
+	RefreshSimilarity() (int, error)
+
+	// cache ผลแนะนำ
+	GetRecommendPage(userID int, cursor string, limit int) (*recmodels.RecommendPage, error)
+	InvalidateUser(userID int)
+	InvalidateAll()
+	RunCacheWorker()
 }
 
+const (
+	// จำนวนโพสต์ "ไม่สนใจ" ล่าสุดที่ใช้เป็น negative signal
+	negativeSeedLimit = 20
+	// น้ำหนักหักคะแนนเมื่อคล้ายโพสต์ที่ไม่สนใจ
+	negativeWeight = 0.5
+	// โบนัสเมื่ออยู่ cluster เดียวกับ seed (cluster_id จาก k-means job)
+	sameClusterBonus = 0.1
+)
+
+// น้ำหนักรวมคะแนน: content (style vector) + collaborative filtering (likes/saves)
+type BlendWeights struct {
+	Content       float64
+	Collaborative float64
+}
+
+// ให้ระบบภายนอก (A/B experiment) กำหนดน้ำหนักรายผู้ใช้ คืน false = ใช้ค่า default
+type WeightsFunc func(userID int) (BlendWeights, bool)
+
+const (
+	// เกณฑ์ของ job สร้าง post_similarity
+	cfMinCoCount = 1
+	cfTopK       = 50
+	// ใช้ like/save ล่าสุดไม่เกินเท่านี้ต่อผู้ใช้ จำกัดขนาด self-join
+	cfUserCap = 200
+)
+
+const (
+	// จำนวนโพสต์ที่เก็บใน cache ต่อผู้ใช้
+	cacheSize = 100
+	// ขนาดคิว refresh cache (เต็มแล้วจะข้าม รอ TTL/stale รอบหน้า)
+	cacheQueueSize = 256
+)
+
 type recommendService struct {
-	repo recrepo.RecommendRepo
+	repo       recrepo.RecommendRepo
+	weights    BlendWeights
+	weightsFor WeightsFunc
+	cacheTTL   time.Duration
+	cursors    *pagination.Codec
+
+	refreshQueue chan int
+	pendingMu    sync.Mutex
+	pending      map[int]bool
+}
+
+func NewRecommendService(repo recrepo.RecommendRepo, weights BlendWeights, cacheTTL time.Duration, weightsFor WeightsFunc, cursors *pagination.Codec) RecommendService {
+	if cacheTTL <= 0 {
+		cacheTTL = time.Hour
+	}
+	return &recommendService{
+		repo:         repo,
+		weights:      sanitizeWeights(weights),
+		weightsFor:   weightsFor,
+		cacheTTL:     cacheTTL,
+		cursors:      cursors,
+		refreshQueue: make(chan int, cacheQueueSize),
+		pending:      make(map[int]bool),
+	}
+}
+
+func sanitizeWeights(w BlendWeights) BlendWeights {
+	if w.Content < 0 {
+		w.Content = 0
+	}
+	if w.Collaborative < 0 {
+		w.Collaborative = 0
+	}
+	if w.Content == 0 && w.Collaborative == 0 {
+		w.Content = 1
+	}
+	return w
 }
 
-func NewRecommendService(repo recrepo.RecommendRepo) RecommendService {
-	return &recommendService{repo: repo}
+func (s *recommendService) userWeights(userID int) BlendWeights {
+	if s.weightsFor != nil {
+		if w, ok := s.weightsFor(userID); ok {
+			return sanitizeWeights(w)
+		}
+	}
+	return s.weights
 }
 
 func (s *recommendService) RecommendForUser(userID int, limit int) ([]recmodels.Candidatepost, error) {
@@ -30,35 +123,91 @@ func (s *recommendService) RecommendForUser(userID int, limit int) ([]recmodels.
 		limit = 10
 	}
 
+	weights := s.userWeights(userID)
+
 	seed, err := s.repo.GetLatestLikedSeed(userID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return []recmodels.Candidatepost{}, nil
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
 		}
-		return nil, err
+		seed = nil
 	}
 
-	candidates, err := s.repo.ListCandidates(userID, seed.PostID, seed.Label, limit*10)
-	if err != nil {
-		return nil, err
+	var candidates []recmodels.Candidatepost
+	if seed != nil {
+		candidates, err = s.repo.ListCandidates(userID, seed.PostID, seed.Label, limit*10)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var cfCandidates []recmodels.Candidatepost
+	if weights.Collaborative > 0 {
+		cfCandidates, err = s.repo.ListCollaborative(userID, limit*10)
+		if err != nil {
+			return nil, err
+		}
 	}
-	if len(candidates) == 0 {
+
+	if len(candidates) == 0 && len(cfCandidates) == 0 {
+		if seed == nil {
+			return s.coldStart(userID, limit)
+		}
 		return s.repo.ListFallback(userID, limit)
 	}
 
+	negatives, err := s.repo.ListNegativeVectors(userID, negativeSeedLimit)
+	if err != nil {
+		return nil, err
+	}
+
 	// คำนวณ similarity + sort
 	type scored struct {
 		p     recmodels.Candidatepost
 		score float64
 	}
-	scoredList := make([]scored, 0, len(candidates))
+	byID := make(map[int]*scored, len(candidates)+len(cfCandidates))
+	scoredList := make([]*scored, 0, len(candidates)+len(cfCandidates))
+
+	contentScore := func(c recmodels.Candidatepost) float64 {
+		if seed == nil || len(seed.Vec) == 0 || len(c.Vec) == 0 {
+			return 0
+		}
+		score := cosineSim(seed.Vec, c.Vec) - negativeWeight*maxSim(negatives, c.Vec)
+		if seed.ClusterID != nil && c.ClusterID != nil && *seed.ClusterID >= 0 && *seed.ClusterID == *c.ClusterID {
+			score += sameClusterBonus
+		}
+		return score
+	}
 
 	for _, c := range candidates {
-		if len(seed.Vec) == 0 || len(c.Vec) == 0 {
+		if len(c.Vec) == 0 {
+			continue
+		}
+		it := &scored{p: c, score: weights.Content * contentScore(c)}
+		byID[c.PostID] = it
+		scoredList = append(scoredList, it)
+	}
+
+	// normalize CF score ให้อยู่ช่วง 0..1 ก่อนผสม
+	cfMax := 0.0
+	for _, c := range cfCandidates {
+		if c.CFScore > cfMax {
+			cfMax = c.CFScore
+		}
+	}
+	for _, c := range cfCandidates {
+		if cfMax <= 0 {
+			break
+		}
+		cf := weights.Collaborative * (c.CFScore / cfMax)
+		if it, ok := byID[c.PostID]; ok {
+			it.score += cf
 			continue
 		}
-		score := cosineSim(seed.Vec, c.Vec)
-		scoredList = append(scoredList, scored{p: c, score: score})
+		it := &scored{p: c, score: weights.Content*contentScore(c) + cf}
+		byID[c.PostID] = it
+		scoredList = append(scoredList, it)
 	}
 
 	// sort score มาก -> น้อย
@@ -100,6 +249,208 @@ func (s *recommendService) RecommendForUser(userID int, limit int) ([]recmodels.
 	return out, nil
 }
 
+// cursor ลงลายเซ็นกันแก้ค่า (nil = หน้าแรก)
+func (s *recommendService) decodeCursor(cursor string) (*recmodels.RecommendCursor, error) {
+	var k recmodels.RecommendCursor
+	ok, err := s.cursors.Decode(cursor, &k)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if k.Rank < 0 {
+		return nil, pagination.ErrInvalidCursor
+	}
+	return &k, nil
+}
+
+// อ่านผลแนะนำจาก cache: ยังไม่มี cache = คำนวณทันที, หมดอายุ/stale = เสิร์ฟของเดิมแล้ว refresh เบื้องหลัง
+func (s *recommendService) GetRecommendPage(userID int, cursor string, limit int) (*recmodels.RecommendPage, error) {
+	if userID <= 0 {
+		return nil, errors.New("invalid userid")
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	after, err := s.decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := s.repo.GetCacheMeta(userID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if err := s.rebuildCache(userID); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case meta.Stale || time.Now().After(meta.ExpiresAt):
+		s.enqueueRefresh(userID)
+	}
+
+	items, generated, err := s.repo.ListCachePage(userID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &recmodels.RecommendPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		next := recmodels.RecommendCursor{Generated: generated, Rank: items[limit-1].Rank}
+		if page.NextCursor, err = s.cursors.Encode(next); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+func (s *recommendService) rebuildCache(userID int) error {
+	items, err := s.RecommendForUser(userID, cacheSize)
+	if err != nil {
+		return fmt.Errorf("compute recommendations: %w", err)
+	}
+	ids := make([]int, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.PostID)
+	}
+	return s.repo.ReplaceCache(userID, ids, s.cacheTTL)
+}
+
+// เรียกหลัง like/save/follow/feedback: mark stale แล้วเข้าคิว refresh
+func (s *recommendService) InvalidateUser(userID int) {
+	if userID <= 0 {
+		return
+	}
+	if err := s.repo.MarkCacheStale(userID); err != nil {
+		log.Printf("mark recommend cache stale (user %d): %v", userID, err)
+	}
+	s.enqueueRefresh(userID)
+}
+
+// ใช้เมื่อวิธีจัดอันดับเปลี่ยนทั้งระบบ เช่น เริ่ม/หยุด experiment บน surface recommend
+func (s *recommendService) InvalidateAll() {
+	n, err := s.repo.ClearAllCaches()
+	if err != nil {
+		log.Printf("clear recommend caches: %v", err)
+		return
+	}
+	log.Printf("clear recommend caches: %d user(s)", n)
+}
+
+func (s *recommendService) enqueueRefresh(userID int) {
+	s.pendingMu.Lock()
+	if s.pending[userID] {
+		s.pendingMu.Unlock()
+		return
+	}
+	s.pending[userID] = true
+	s.pendingMu.Unlock()
+
+	select {
+	case s.refreshQueue <- userID:
+	default:
+		s.clearPending(userID)
+	}
+}
+
+func (s *recommendService) clearPending(userID int) {
+	s.pendingMu.Lock()
+	delete(s.pending, userID)
+	s.pendingMu.Unlock()
+}
+
+// worker refresh cache (รันใน goroutine เดียวจาก main)
+func (s *recommendService) RunCacheWorker() {
+	for userID := range s.refreshQueue {
+		s.clearPending(userID)
+		if err := s.rebuildCache(userID); err != nil {
+			log.Printf("refresh recommend cache (user %d): %v", userID, err)
+		}
+	}
+}
+
+// ผู้ใช้ใหม่ที่ยังไม่มี like/save: ใช้หัวข้อที่เลือกตอน onboarding แล้วเติมด้วยโพสต์ยอดนิยม
+func (s *recommendService) coldStart(userID int, limit int) ([]recmodels.Candidatepost, error) {
+	out, err := s.repo.ListByInterests(userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) >= limit {
+		return out, nil
+	}
+
+	seen := make(map[int]bool, len(out))
+	for _, p := range out {
+		seen[p.PostID] = true
+	}
+	fb, err := s.repo.ListFallback(userID, limit*2)
+	if err != nil {
+		return out, nil
+	}
+	for _, p := range fb {
+		if len(out) >= limit {
+			break
+		}
+		if seen[p.PostID] {
+			continue
+		}
+		seen[p.PostID] = true
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func (s *recommendService) AddFeedback(userID, postID int, req recmodels.FeedbackRequest) (*recmodels.Feedback, error) {
+	if userID <= 0 || postID <= 0 {
+		return nil, errors.New("invalid id")
+	}
+
+	fbType := strings.ToLower(strings.TrimSpace(req.Type))
+	tag := tagname.Normalize(req.Tag)
+
+	switch fbType {
+	case recmodels.FeedbackNotInterested, recmodels.FeedbackHideAuthor:
+	case recmodels.FeedbackHideTag:
+		if tag == "" {
+			return nil, recmodels.ErrFeedbackTagRequired
+		}
+	default:
+		return nil, recmodels.ErrInvalidFeedbackType
+	}
+
+	return s.repo.AddFeedback(userID, postID, fbType, tag)
+}
+
+func (s *recommendService) ListFeedback(userID int) ([]recmodels.Feedback, error) {
+	if userID <= 0 {
+		return nil, errors.New("invalid userid")
+	}
+	return s.repo.ListFeedback(userID)
+}
+
+func (s *recommendService) DeleteFeedback(userID, feedbackID int) error {
+	if userID <= 0 || feedbackID <= 0 {
+		return errors.New("invalid id")
+	}
+	return s.repo.DeleteFeedback(userID, feedbackID)
+}
+
+// อัปเดต post_similarity ของโพสต์ที่มี like/save ใหม่ (เรียกจาก background job)
+func (s *recommendService) RefreshSimilarity() (int, error) {
+	return s.repo.RefreshPostSimilarity(cfMinCoCount, cfTopK, cfUserCap)
+}
+
+// similarity สูงสุดเทียบกับชุด vector (ใช้กับ negative signal)
+func maxSim(vecs [][]float64, v []float64) float64 {
+	best := 0.0
+	for _, n := range vecs {
+		if sim := cosineSim(n, v); sim > best {
+			best = sim
+		}
+	}
+	return best
+}
+
 // ===== cosine similarity =====
 func cosineSim(a, b []float64) float64 {
 	n := min(len(a), len(b))
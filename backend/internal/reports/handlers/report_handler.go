@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"chaladshare_backend/internal/middleware"
+	"chaladshare_backend/internal/pagination"
+	"chaladshare_backend/internal/reports/models"
+	"chaladshare_backend/internal/reports/service"
+)
+
+type ReportHandler struct {
+	reportService service.ReportService
+}
+
+func NewReportHandler(reportService service.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrTargetNotFound),
+		errors.Is(err, models.ErrNoOpenReports),
+		errors.Is(err, models.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrInvalidTarget),
+		errors.Is(err, models.ErrInvalidReason),
+		errors.Is(err, models.ErrDetailsRequired),
+		errors.Is(err, models.ErrDetailsTooLong),
+		errors.Is(err, models.ErrInvalidAction),
+		errors.Is(err, models.ErrActionNotAllowed),
+		errors.Is(err, pagination.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrReportSelf), errors.Is(err, models.ErrSuspendSelf):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func paramID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return id, true
+}
+
+func pageQuery(c *gin.Context) (string, int) {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+	return c.Query("cursor"), pagination.ClampLimit(limit)
+}
+
+// POST /api/v1/reports
+// body: {target_type: post|comment|user, target_id, reason, details}
+// รายงานซ้ำขณะที่ยังไม่ถูกพิจารณา = อัปเดตเหตุผลของรายงานเดิม
+func (h *ReportHandler) CreateReport(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var req models.CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	report, err := h.reportService.CreateReport(uid, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// GET /api/v1/reports?cursor=&limit=
+// รายงานของฉันพร้อมสถานะการพิจารณา
+func (h *ReportHandler) ListMyReports(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	cursor, limit := pageQuery(c)
+	page, err := h.reportService.ListMyReports(uid, cursor, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": page.Items, "next_cursor": page.NextCursor})
+}
+
+// GET /api/v1/admin/reports?type=post|comment|user&cursor=&limit=
+// คิว moderation: เป้าหมายที่มีรายงานเปิดอยู่ เรียงตามจำนวนผู้รายงาน
+func (h *ReportHandler) ListQueue(c *gin.Context) {
+	cursor, limit := pageQuery(c)
+	page, err := h.reportService.ListQueue(c.Query("type"), cursor, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": page.Items, "next_cursor": page.NextCursor})
+}
+
+// GET /api/v1/admin/reports/:type/:id
+func (h *ReportHandler) GetQueueItem(c *gin.Context) {
+	targetID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	detail, err := h.reportService.GetQueueItem(c.Param("type"), targetID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": detail})
+}
+
+// POST /api/v1/admin/reports/:type/:id/resolve
+// body: {action: dismiss|hide|suspend, note}
+func (h *ReportHandler) Resolve(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	targetID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req models.ResolveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	result, err := h.reportService.Resolve(uid, c.Param("type"), targetID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// POST /api/v1/admin/users/:id/reinstate
+// ยกเลิกการระงับบัญชี
+func (h *ReportHandler) ReinstateUser(c *gin.Context) {
+	userID, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.reportService.ReinstateUser(userID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "user reinstated"})
+}
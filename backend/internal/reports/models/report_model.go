@@ -0,0 +1,136 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidTarget    = errors.New("target_type must be post, comment or user")
+	ErrInvalidReason    = errors.New("reason must be spam, plagiarism, inappropriate, harassment or other")
+	ErrDetailsRequired  = errors.New("details are required when reason is other")
+	ErrDetailsTooLong   = errors.New("details must be at most 1000 characters")
+	ErrTargetNotFound   = errors.New("report target not found")
+	ErrReportSelf       = errors.New("cannot report yourself or your own content")
+	ErrInvalidAction    = errors.New("action must be dismiss, hide or suspend")
+	ErrActionNotAllowed = errors.New("hide applies to posts and comments only")
+	ErrSuspendSelf      = errors.New("cannot suspend yourself")
+	ErrNoOpenReports    = errors.New("no open reports for this target")
+	ErrUserNotFound     = errors.New("user not found")
+)
+
+// ประเภทเป้าหมายที่รายงานได้
+const (
+	TargetPost    = "post"
+	TargetComment = "comment"
+	TargetUser    = "user"
+)
+
+// เหตุผลการรายงาน
+const (
+	ReasonSpam          = "spam"
+	ReasonPlagiarism    = "plagiarism"
+	ReasonInappropriate = "inappropriate"
+	ReasonHarassment    = "harassment"
+	ReasonOther         = "other"
+)
+
+var Reasons = []string{ReasonSpam, ReasonPlagiarism, ReasonInappropriate, ReasonHarassment, ReasonOther}
+
+// สถานะรายงาน
+const (
+	StatusOpen      = "open"
+	StatusDismissed = "dismissed"
+	StatusActioned  = "actioned"
+)
+
+// การตัดสินของผู้ดูแล
+// dismiss = ไม่ผิด (ยกเลิกการซ่อนอัตโนมัติ), hide = ซ่อนโพสต์/คอมเมนต์, suspend = ระงับบัญชีเจ้าของ
+const (
+	ActionDismiss = "dismiss"
+	ActionHide    = "hide"
+	ActionSuspend = "suspend"
+)
+
+// ข้อความแจ้งผู้รายงานตามผลการพิจารณา
+var OutcomeMessages = map[string]string{
+	ActionDismiss: "Thanks for your report. We reviewed it and found no violation.",
+	ActionHide:    "Thanks for your report. The reported content has been hidden.",
+	ActionSuspend: "Thanks for your report. The account responsible has been suspended.",
+}
+
+const MaxDetailsLen = 1000
+
+// รายงานของผู้รายงานเอง
+type Report struct {
+	ReportID   int64      `json:"report_id"`
+	TargetType string     `json:"target_type"`
+	TargetID   int        `json:"target_id"`
+	Reason     string     `json:"reason"`
+	Details    *string    `json:"details,omitempty"`
+	Status     string     `json:"status"`
+	Action     *string    `json:"action,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+type ReportPage struct {
+	Items      []Report `json:"items"`
+	NextCursor string   `json:"next_cursor"`
+}
+
+type CreateReportRequest struct {
+	TargetType string  `json:"target_type" binding:"required"`
+	TargetID   int     `json:"target_id" binding:"required"`
+	Reason     string  `json:"reason" binding:"required"`
+	Details    *string `json:"details"`
+}
+
+// หนึ่งรายการในคิว = หนึ่งเป้าหมายที่มีรายงานเปิดอยู่
+type QueueItem struct {
+	TargetType      string     `json:"target_type"`
+	TargetID        int        `json:"target_id"`
+	ReportCount     int        `json:"report_count"` // ผู้รายงานไม่ซ้ำ
+	Reasons         []string   `json:"reasons"`
+	FirstReportedAt time.Time  `json:"first_reported_at"`
+	LastReportedAt  time.Time  `json:"last_reported_at"`
+	OwnerID         *int       `json:"owner_id"`
+	OwnerName       *string    `json:"owner_name"`
+	OwnerStatus     *string    `json:"owner_status"`
+	Preview         *string    `json:"preview"` // ชื่อโพสต์ / เนื้อหาคอมเมนต์ / username
+	HiddenAt        *time.Time `json:"hidden_at"`
+}
+
+type QueuePage struct {
+	Items      []QueueItem `json:"items"`
+	NextCursor string      `json:"next_cursor"`
+}
+
+// รายงานแต่ละฉบับของเป้าหมาย (มุมมองผู้ดูแล)
+type ReportEntry struct {
+	ReportID     int64     `json:"report_id"`
+	ReporterID   int       `json:"reporter_id"`
+	ReporterName string    `json:"reporter_name"`
+	Reason       string    `json:"reason"`
+	Details      *string   `json:"details,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type QueueDetail struct {
+	QueueItem
+	Reports []ReportEntry `json:"reports"`
+}
+
+type ResolveRequest struct {
+	Action string  `json:"action" binding:"required"`
+	Note   *string `json:"note"`
+}
+
+type ResolveResult struct {
+	Action          string `json:"action"`
+	ResolvedReports int    `json:"resolved_reports"`
+	Notified        int    `json:"notified"`
+	SuspendedUserID *int   `json:"suspended_user_id,omitempty"`
+}
@@ -0,0 +1,425 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	notifmodels "chaladshare_backend/internal/notifications/models"
+	"chaladshare_backend/internal/pagination"
+	"chaladshare_backend/internal/reports/models"
+)
+
+type ReportRepository interface {
+	TargetOwner(targetType string, targetID int) (ownerID, postID int, err error)
+	UpsertReport(reporterID int, req models.CreateReportRequest) (*models.Report, error)
+	AutoHide(targetType string, targetID, threshold int) (bool, error)
+	ListMyReports(reporterID int, after *pagination.Keyset, limit int) ([]models.Report, error)
+
+	ListQueue(targetType string, offset, limit int) ([]models.QueueItem, error)
+	GetQueueItem(targetType string, targetID int) (*models.QueueItem, error)
+	ListOpenReports(targetType string, targetID int) ([]models.ReportEntry, error)
+	Resolve(adminID int, targetType string, targetID int, action string, note *string) (*models.ResolveResult, error)
+	ReinstateUser(userID int) error
+}
+
+type reportRepository struct {
+	db *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// เจ้าของเป้าหมาย (ผู้เขียนโพสต์/คอมเมนต์ หรือตัวผู้ใช้เอง) และโพสต์ที่เกี่ยวข้อง (0 = ไม่มี)
+// โพสต์ในถังขยะถือว่าไม่พบ
+func targetOwner(q queryer, targetType string, targetID int) (int, int, error) {
+	var (
+		ownerID int
+		postID  sql.NullInt64
+		err     error
+	)
+	switch targetType {
+	case models.TargetPost:
+		err = q.QueryRow(`
+			SELECT post_author_user_id, post_id FROM posts
+			WHERE post_id = $1 AND post_deleted_at IS NULL`, targetID).Scan(&ownerID, &postID)
+	case models.TargetComment:
+		err = q.QueryRow(`
+			SELECT comment_user_id, comment_post_id FROM comments
+			WHERE comment_id = $1`, targetID).Scan(&ownerID, &postID)
+	case models.TargetUser:
+		err = q.QueryRow(`SELECT user_id, NULL FROM users WHERE user_id = $1`, targetID).Scan(&ownerID, &postID)
+	default:
+		return 0, 0, models.ErrInvalidTarget
+	}
+	if err == sql.ErrNoRows {
+		return 0, 0, models.ErrTargetNotFound
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return ownerID, int(postID.Int64), nil
+}
+
+func (r *reportRepository) TargetOwner(targetType string, targetID int) (int, int, error) {
+	return targetOwner(r.db, targetType, targetID)
+}
+
+const qReportColumns = `report_id, report_target_type, report_target_id, report_reason, report_details,
+	report_status, report_action, report_created_at, report_updated_at, report_resolved_at`
+
+func scanReport(row interface{ Scan(...any) error }) (*models.Report, error) {
+	var rp models.Report
+	if err := row.Scan(&rp.ReportID, &rp.TargetType, &rp.TargetID, &rp.Reason, &rp.Details,
+		&rp.Status, &rp.Action, &rp.CreatedAt, &rp.UpdatedAt, &rp.ResolvedAt); err != nil {
+		return nil, err
+	}
+	return &rp, nil
+}
+
+// กันซ้ำต่อผู้รายงาน: มีรายงานเปิดอยู่แล้ว = อัปเดตเหตุผล/รายละเอียดแทนการสร้างใหม่
+func (r *reportRepository) UpsertReport(reporterID int, req models.CreateReportRequest) (*models.Report, error) {
+	row := r.db.QueryRow(`
+		INSERT INTO reports (report_reporter_id, report_target_type, report_target_id, report_reason, report_details)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (report_reporter_id, report_target_type, report_target_id) WHERE report_status = 'open'
+		DO UPDATE SET report_reason = EXCLUDED.report_reason,
+			report_details = EXCLUDED.report_details,
+			report_updated_at = now()
+		RETURNING `+qReportColumns+`;`,
+		reporterID, req.TargetType, req.TargetID, req.Reason, req.Details)
+	return scanReport(row)
+}
+
+// ซ่อนโพสต์/คอมเมนต์อัตโนมัติเมื่อผู้รายงานไม่ซ้ำที่ยังเปิดอยู่ครบ threshold (true = เพิ่งถูกซ่อน)
+func (r *reportRepository) AutoHide(targetType string, targetID, threshold int) (bool, error) {
+	var query string
+	switch targetType {
+	case models.TargetPost:
+		query = `UPDATE posts SET post_hidden_at = now(), post_hidden_reason = 'auto'
+			WHERE post_id = $1 AND post_hidden_at IS NULL`
+	case models.TargetComment:
+		query = `UPDATE comments SET comment_hidden_at = now(), comment_hidden_reason = 'auto'
+			WHERE comment_id = $1 AND comment_hidden_at IS NULL`
+	default:
+		return false, nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(query+`
+		AND (SELECT COUNT(DISTINCT report_reporter_id) FROM reports
+			WHERE report_target_type = $2 AND report_target_id = $1 AND report_status = 'open') >= $3;`,
+		targetID, targetType, threshold)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := refreshCommentCount(tx, targetType, targetID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// keyset ใหม่→เก่า บน (report_created_at, report_id)
+func (r *reportRepository) ListMyReports(reporterID int, after *pagination.Keyset, limit int) ([]models.Report, error) {
+	var (
+		afterAt any
+		afterID any
+	)
+	if after != nil {
+		afterAt, afterID = after.CreatedAt, after.ID
+	}
+	rows, err := r.db.Query(`
+		SELECT `+qReportColumns+`
+		FROM reports
+		WHERE report_reporter_id = $1
+		  AND ($2::timestamptz IS NULL OR (report_created_at, report_id) < ($2::timestamptz, $3::bigint))
+		ORDER BY report_created_at DESC, report_id DESC
+		LIMIT $4;`, reporterID, afterAt, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.Report{}
+	for rows.Next() {
+		rp, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *rp)
+	}
+	return out, rows.Err()
+}
+
+// รวมรายงานที่เปิดอยู่ต่อเป้าหมาย + ข้อมูลเจ้าของ/ตัวอย่างเนื้อหา
+// $1 = target_type (ว่าง = ทุกประเภท), $2 = target_id (0 = ทุกเป้าหมาย)
+const qQueue = `
+	WITH g AS (
+		SELECT report_target_type AS target_type, report_target_id AS target_id,
+			COUNT(DISTINCT report_reporter_id) AS report_count,
+			ARRAY_AGG(DISTINCT report_reason) AS reasons,
+			MIN(report_created_at) AS first_at,
+			MAX(report_updated_at) AS last_at
+		FROM reports
+		WHERE report_status = 'open'
+		  AND ($1 = '' OR report_target_type = $1)
+		  AND ($2 = 0 OR report_target_id = $2)
+		GROUP BY report_target_type, report_target_id
+	)
+	SELECT g.target_type, g.target_id, g.report_count, g.reasons, g.first_at, g.last_at,
+		t.owner_id, ou.username, ou.user_status, t.preview, t.hidden_at
+	FROM g
+	LEFT JOIN LATERAL (
+		SELECT p.post_author_user_id AS owner_id, p.post_title::text AS preview, p.post_hidden_at AS hidden_at
+		FROM posts p WHERE g.target_type = 'post' AND p.post_id = g.target_id
+		UNION ALL
+		SELECT c.comment_user_id, LEFT(c.comment_body, 200), c.comment_hidden_at
+		FROM comments c WHERE g.target_type = 'comment' AND c.comment_id = g.target_id
+		UNION ALL
+		SELECT u.user_id, u.username::text, NULL::timestamptz
+		FROM users u WHERE g.target_type = 'user' AND u.user_id = g.target_id
+	) t ON true
+	LEFT JOIN users ou ON ou.user_id = t.owner_id
+	ORDER BY g.report_count DESC, g.first_at, g.target_type, g.target_id
+`
+
+func scanQueueItem(row interface{ Scan(...any) error }) (*models.QueueItem, error) {
+	var (
+		it      models.QueueItem
+		reasons pq.StringArray
+	)
+	if err := row.Scan(&it.TargetType, &it.TargetID, &it.ReportCount, &reasons,
+		&it.FirstReportedAt, &it.LastReportedAt,
+		&it.OwnerID, &it.OwnerName, &it.OwnerStatus, &it.Preview, &it.HiddenAt); err != nil {
+		return nil, err
+	}
+	it.Reasons = []string(reasons)
+	return &it, nil
+}
+
+// เป้าหมายที่ถูกรายงานมากสุดก่อน แล้วเรียงตามรายงานแรกที่เก่าสุด (offset เพราะเรียงตามค่าที่รวมได้)
+func (r *reportRepository) ListQueue(targetType string, offset, limit int) ([]models.QueueItem, error) {
+	rows, err := r.db.Query(qQueue+` OFFSET $3 LIMIT $4;`, targetType, 0, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.QueueItem{}
+	for rows.Next() {
+		it, err := scanQueueItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *it)
+	}
+	return out, rows.Err()
+}
+
+func (r *reportRepository) GetQueueItem(targetType string, targetID int) (*models.QueueItem, error) {
+	it, err := scanQueueItem(r.db.QueryRow(qQueue+`;`, targetType, targetID))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNoOpenReports
+	}
+	return it, err
+}
+
+func (r *reportRepository) ListOpenReports(targetType string, targetID int) ([]models.ReportEntry, error) {
+	rows, err := r.db.Query(`
+		SELECT r.report_id, r.report_reporter_id, u.username, r.report_reason, r.report_details,
+			r.report_created_at, r.report_updated_at
+		FROM reports r
+		JOIN users u ON u.user_id = r.report_reporter_id
+		WHERE r.report_target_type = $1 AND r.report_target_id = $2 AND r.report_status = 'open'
+		ORDER BY r.report_created_at, r.report_id;`, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.ReportEntry{}
+	for rows.Next() {
+		var e models.ReportEntry
+		if err := rows.Scan(&e.ReportID, &e.ReporterID, &e.ReporterName, &e.Reason, &e.Details,
+			&e.CreatedAt, &e.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// ปิดรายงานที่เปิดอยู่ทั้งหมดของเป้าหมาย + ลงมือตาม action + แจ้งผู้รายงาน ใน transaction เดียว
+func (r *reportRepository) Resolve(adminID int, targetType string, targetID int, action string, note *string) (*models.ResolveResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// ล็อกรายงานที่เปิดอยู่ (กันผู้ดูแลสองคนตัดสินเป้าหมายเดียวกันพร้อมกัน)
+	var open int
+	if err := tx.QueryRow(`
+		SELECT COUNT(*) FROM (
+			SELECT 1 FROM reports
+			WHERE report_target_type = $1 AND report_target_id = $2 AND report_status = 'open'
+			FOR UPDATE
+		) x;`, targetType, targetID).Scan(&open); err != nil {
+		return nil, fmt.Errorf("lock reports: %w", err)
+	}
+	if open == 0 {
+		return nil, models.ErrNoOpenReports
+	}
+
+	result := &models.ResolveResult{Action: action}
+	status := models.StatusActioned
+
+	switch action {
+	case models.ActionDismiss:
+		// ไม่ผิด: เลิกซ่อนเฉพาะที่ถูกซ่อนอัตโนมัติจากรายงานรอบนี้
+		status = models.StatusDismissed
+		if err := unhideAuto(tx, targetType, targetID); err != nil {
+			return nil, err
+		}
+	case models.ActionHide:
+		if err := hideByModerator(tx, targetType, targetID); err != nil {
+			return nil, err
+		}
+	case models.ActionSuspend:
+		ownerID, _, err := targetOwner(tx, targetType, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if ownerID == adminID {
+			return nil, models.ErrSuspendSelf
+		}
+		if _, err := tx.Exec(`UPDATE users SET user_status = 'suspended' WHERE user_id = $1`, ownerID); err != nil {
+			return nil, fmt.Errorf("suspend user: %w", err)
+		}
+		if _, err := tx.Exec(`
+			UPDATE auth_sessions SET revoked_at = now()
+			WHERE session_user_id = $1 AND revoked_at IS NULL;`, ownerID); err != nil {
+			return nil, fmt.Errorf("revoke sessions: %w", err)
+		}
+		result.SuspendedUserID = &ownerID
+	default:
+		return nil, models.ErrInvalidAction
+	}
+
+	// ปิดรายงาน + แจ้งผู้รายงานทุกคนของรอบนี้ (หนึ่งคนมีรายงานเปิดได้ฉบับเดียวต่อเป้าหมาย)
+	res, err := tx.Exec(`
+		WITH resolved AS (
+			UPDATE reports
+			SET report_status = $3, report_action = $4, report_note = $5,
+				report_resolved_by = $6, report_resolved_at = now(), report_updated_at = now()
+			WHERE report_target_type = $1 AND report_target_id = $2 AND report_status = 'open'
+			RETURNING report_id, report_reporter_id, report_target_type, report_target_id, report_action
+		)
+		INSERT INTO notifications (notification_user_id, notification_type, notification_message, notification_data)
+		SELECT report_reporter_id, $7, $8,
+			jsonb_build_object('report_id', report_id, 'target_type', report_target_type,
+				'target_id', report_target_id, 'action', report_action)
+		FROM resolved;`,
+		targetType, targetID, status, action, note, adminID,
+		notifmodels.TypeReportResolved, models.OutcomeMessages[action])
+	if err != nil {
+		return nil, fmt.Errorf("resolve reports: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	result.ResolvedReports = int(n)
+	result.Notified = int(n)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ซ่อนต่อ (หรือเริ่มซ่อน) โดยผู้ดูแล: dismiss รอบหลังจะไม่เลิกซ่อนให้
+func hideByModerator(tx *sql.Tx, targetType string, targetID int) error {
+	var query string
+	switch targetType {
+	case models.TargetPost:
+		query = `UPDATE posts SET post_hidden_at = COALESCE(post_hidden_at, now()), post_hidden_reason = 'moderator'
+			WHERE post_id = $1`
+	case models.TargetComment:
+		query = `UPDATE comments SET comment_hidden_at = COALESCE(comment_hidden_at, now()), comment_hidden_reason = 'moderator'
+			WHERE comment_id = $1`
+	default:
+		return models.ErrActionNotAllowed
+	}
+	if _, err := tx.Exec(query, targetID); err != nil {
+		return fmt.Errorf("hide target: %w", err)
+	}
+	return refreshCommentCount(tx, targetType, targetID)
+}
+
+// เลิกซ่อนเฉพาะที่ AutoHide ซ่อนหลังรายงานแรกของรอบที่ยังเปิดอยู่ (ต้องเรียกก่อนปิดรายงาน)
+func unhideAuto(tx *sql.Tx, targetType string, targetID int) error {
+	var query string
+	switch targetType {
+	case models.TargetPost:
+		query = `UPDATE posts SET post_hidden_at = NULL, post_hidden_reason = NULL
+			WHERE post_id = $1 AND post_hidden_reason = 'auto' AND post_hidden_at >= (` + qRoundStart + `)`
+	case models.TargetComment:
+		query = `UPDATE comments SET comment_hidden_at = NULL, comment_hidden_reason = NULL
+			WHERE comment_id = $1 AND comment_hidden_reason = 'auto' AND comment_hidden_at >= (` + qRoundStart + `)`
+	default:
+		return nil
+	}
+	if _, err := tx.Exec(query, targetID, targetType); err != nil {
+		return fmt.Errorf("unhide target: %w", err)
+	}
+	return refreshCommentCount(tx, targetType, targetID)
+}
+
+// คอมเมนต์ถูกซ่อน/เลิกซ่อน: นับ post_comment_count ของโพสต์แม่ใหม่ (นับเฉพาะที่มองเห็น เหมือน comments)
+// แยก statement จากการ UPDATE เพราะ CTE เดียวกันยังเห็นค่าก่อนเปลี่ยน
+func refreshCommentCount(tx *sql.Tx, targetType string, targetID int) error {
+	if targetType != models.TargetComment {
+		return nil
+	}
+	if _, err := tx.Exec(`
+		UPDATE post_stats ps
+		SET post_comment_count = (
+			SELECT COUNT(*) FROM comments
+			WHERE comment_post_id = ps.post_stats_post_id AND comment_hidden_at IS NULL)
+		FROM comments c
+		WHERE c.comment_id = $1 AND ps.post_stats_post_id = c.comment_post_id;`, targetID); err != nil {
+		return fmt.Errorf("refresh comment count: %w", err)
+	}
+	return nil
+}
+
+// เวลารายงานแรกของรอบที่ยังเปิดอยู่ ($1 = target_id, $2 = target_type)
+const qRoundStart = `SELECT MIN(report_created_at) FROM reports
+	WHERE report_target_type = $2 AND report_target_id = $1 AND report_status = 'open'`
+
+func (r *reportRepository) ReinstateUser(userID int) error {
+	res, err := r.db.Exec(`UPDATE users SET user_status = 'active' WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"log"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"chaladshare_backend/internal/pagination"
+	postservice "chaladshare_backend/internal/posts/service"
+	"chaladshare_backend/internal/reports/models"
+	"chaladshare_backend/internal/reports/repository"
+)
+
+type ReportService interface {
+	CreateReport(reporterID int, req models.CreateReportRequest) (*models.Report, error)
+	ListMyReports(reporterID int, cursor string, limit int) (*models.ReportPage, error)
+
+	ListQueue(targetType, cursor string, limit int) (*models.QueuePage, error)
+	GetQueueItem(targetType string, targetID int) (*models.QueueDetail, error)
+	Resolve(adminID int, targetType string, targetID int, req models.ResolveRequest) (*models.ResolveResult, error)
+	ReinstateUser(userID int) error
+}
+
+type reportService struct {
+	reportRepo        repository.ReportRepository
+	postService       postservice.PostService
+	cursors           *pagination.Codec
+	autoHideThreshold int // 0 = ปิดการซ่อนอัตโนมัติ
+}
+
+func NewReportService(reportRepo repository.ReportRepository, postService postservice.PostService, cursors *pagination.Codec, autoHideThreshold int) ReportService {
+	return &reportService{
+		reportRepo:        reportRepo,
+		postService:       postService,
+		cursors:           cursors,
+		autoHideThreshold: autoHideThreshold,
+	}
+}
+
+func normalizeTarget(targetType string) (string, error) {
+	t := strings.ToLower(strings.TrimSpace(targetType))
+	switch t {
+	case models.TargetPost, models.TargetComment, models.TargetUser:
+		return t, nil
+	}
+	return "", models.ErrInvalidTarget
+}
+
+func normalizeRequest(req models.CreateReportRequest) (models.CreateReportRequest, error) {
+	var err error
+	if req.TargetType, err = normalizeTarget(req.TargetType); err != nil {
+		return req, err
+	}
+	req.Reason = strings.ToLower(strings.TrimSpace(req.Reason))
+	if !slices.Contains(models.Reasons, req.Reason) {
+		return req, models.ErrInvalidReason
+	}
+	if req.Details != nil {
+		d := strings.TrimSpace(*req.Details)
+		if utf8.RuneCountInString(d) > models.MaxDetailsLen {
+			return req, models.ErrDetailsTooLong
+		}
+		req.Details = &d
+		if d == "" {
+			req.Details = nil
+		}
+	}
+	if req.Reason == models.ReasonOther && req.Details == nil {
+		return req, models.ErrDetailsRequired
+	}
+	return req, nil
+}
+
+// รายงานได้เฉพาะสิ่งที่ตัวเองมองเห็น และไม่ใช่ของตัวเอง
+func (s *reportService) CreateReport(reporterID int, req models.CreateReportRequest) (*models.Report, error) {
+	req, err := normalizeRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.TargetID <= 0 {
+		return nil, models.ErrTargetNotFound
+	}
+
+	ownerID, postID, err := s.reportRepo.TargetOwner(req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID == reporterID {
+		return nil, models.ErrReportSelf
+	}
+	if postID != 0 {
+		ok, _, err := s.postService.ViewPost(reporterID, postID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, models.ErrTargetNotFound
+		}
+	}
+
+	report, err := s.reportRepo.UpsertReport(reporterID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.autoHideThreshold > 0 {
+		hidden, err := s.reportRepo.AutoHide(req.TargetType, req.TargetID, s.autoHideThreshold)
+		if err != nil {
+			// รายงานบันทึกแล้ว ผู้ดูแลยังเห็นในคิว
+			log.Printf("[reports] auto-hide %s %d: %v", req.TargetType, req.TargetID, err)
+		} else if hidden {
+			log.Printf("[reports] auto-hid %s %d after %d reports", req.TargetType, req.TargetID, s.autoHideThreshold)
+		}
+	}
+	return report, nil
+}
+
+func (s *reportService) ListMyReports(reporterID int, cursor string, limit int) (*models.ReportPage, error) {
+	var after *pagination.Keyset
+	var k pagination.Keyset
+	ok, err := s.cursors.Decode(cursor, &k)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		after = &k
+	}
+
+	limit = pagination.ClampLimit(limit)
+	items, err := s.reportRepo.ListMyReports(reporterID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &models.ReportPage{Items: items}
+	if len(items) <= limit {
+		return page, nil
+	}
+	page.Items = items[:limit]
+	last := page.Items[limit-1]
+	if page.NextCursor, err = s.cursors.Encode(pagination.Keyset{CreatedAt: last.CreatedAt, ID: int(last.ReportID)}); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// cursor เก็บจำนวนรายการที่ส่งไปแล้ว (Keyset.Offset)
+func (s *reportService) ListQueue(targetType, cursor string, limit int) (*models.QueuePage, error) {
+	if targetType != "" {
+		var err error
+		if targetType, err = normalizeTarget(targetType); err != nil {
+			return nil, err
+		}
+	}
+	var after pagination.Keyset
+	if _, err := s.cursors.Decode(cursor, &after); err != nil {
+		return nil, err
+	}
+
+	limit = pagination.ClampLimit(limit)
+	items, err := s.reportRepo.ListQueue(targetType, after.Offset, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &models.QueuePage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		if page.NextCursor, err = s.cursors.Encode(pagination.Keyset{Offset: after.Offset + limit}); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+func (s *reportService) GetQueueItem(targetType string, targetID int) (*models.QueueDetail, error) {
+	targetType, err := normalizeTarget(targetType)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.reportRepo.GetQueueItem(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := s.reportRepo.ListOpenReports(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.QueueDetail{QueueItem: *item, Reports: reports}, nil
+}
+
+func (s *reportService) Resolve(adminID int, targetType string, targetID int, req models.ResolveRequest) (*models.ResolveResult, error) {
+	targetType, err := normalizeTarget(targetType)
+	if err != nil {
+		return nil, err
+	}
+	action := strings.ToLower(strings.TrimSpace(req.Action))
+	if _, ok := models.OutcomeMessages[action]; !ok {
+		return nil, models.ErrInvalidAction
+	}
+	if action == models.ActionHide && targetType == models.TargetUser {
+		return nil, models.ErrActionNotAllowed
+	}
+
+	var note *string
+	if req.Note != nil {
+		n := strings.TrimSpace(*req.Note)
+		if utf8.RuneCountInString(n) > models.MaxDetailsLen {
+			return nil, models.ErrDetailsTooLong
+		}
+		if n != "" {
+			note = &n
+		}
+	}
+	return s.reportRepo.Resolve(adminID, targetType, targetID, action, note)
+}
+
+func (s *reportService) ReinstateUser(userID int) error {
+	return s.reportRepo.ReinstateUser(userID)
+}
@@ -0,0 +1,45 @@
+package tagname
+
+// กติกาชื่อแท็กที่ใช้ร่วมกันทุกที่ที่รับชื่อแท็กจากผู้ใช้ (โพสต์, หัวข้อ, หน้าแท็ก, แอดมิน)
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ความยาวสูงสุด (ตัวอักษร ไม่ใช่ byte เพราะภาษาไทยใช้ 3 byte ต่อตัว)
+const MaxLen = 30
+
+// ตัด # และช่องว่าง, NFC, ตัวพิมพ์เล็ก
+// อนุญาตตัวอักษรทุกภาษา + สระ/วรรณยุกต์ (mark), ตัวเลข, _ และ -
+// คืน "" ถ้าชื่อใช้ไม่ได้
+func Normalize(raw string) string {
+	tag := strings.TrimPrefix(strings.TrimSpace(raw), "#")
+	tag = strings.ToLower(norm.NFC.String(tag))
+
+	if tag == "" || utf8.RuneCountInString(tag) > MaxLen {
+		return ""
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsMark(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return ""
+		}
+	}
+	// ต้องมีตัวอักษรหรือตัวเลขอย่างน้อยหนึ่งตัว (กัน "-" หรือ mark ลอย ๆ)
+	if strings.IndexFunc(tag, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		return ""
+	}
+	return tag
+}
+
+// $1 = ชื่อแท็กที่ normalize แล้ว
+// คืนชื่อ canonical (ตาม tag_aliases) หรือไม่คืนแถวถ้าชื่อถูกแบน
+const ResolveSQL = `
+	SELECT COALESCE(
+		(SELECT t.tag_name FROM tag_aliases a JOIN tags t ON t.tag_id = a.alias_tag_id
+		 WHERE a.alias_name = $1),
+		$1)
+	WHERE NOT EXISTS (SELECT 1 FROM banned_tags b WHERE b.banned_name = $1);`
@@ -0,0 +1,70 @@
+package tagname
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"ascii lowercased", "GoLang", "golang"},
+		{"hash stripped", "#math", "math"},
+		{"space then hash", "  #Math  ", "math"},
+		{"only one hash stripped", "##math", ""},
+		{"hash inside", "c#", ""},
+		{"underscore and dash", "Go-Lang_2", "go-lang_2"},
+		{"digits only", "101", "101"},
+		{"thai", "คณิตศาสตร์", "คณิตศาสตร์"},
+		{"thai with hash", "#ฟิสิกส์ม4", "ฟิสิกส์ม4"},
+		{"thai digits", "เลข๑๒๓", "เลข๑๒๓"},
+		{"other scripts", "ΑΒΓ日本", "αβγ日本"},
+		{"nfc composed", "cafe\u0301", "caf\u00e9"},
+		{"space inside", "hello world", ""},
+		{"punctuation", "math!", ""},
+		{"emoji", "🙂", ""},
+		{"empty", "", ""},
+		{"hash only", "#", ""},
+		{"dash only", "-", ""},
+		{"lone thai mark", "ั", ""},
+		{"max length thai", strings.Repeat("ก", MaxLen), strings.Repeat("ก", MaxLen)},
+		{"too long thai", strings.Repeat("ก", MaxLen+1), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.raw); got != tt.want {
+				t.Fatalf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+// การแบนและ alias เทียบชื่อแบบตรงตัวใน SQL จึงต้องได้ชื่อเดียวกันไม่ว่าผู้ใช้พิมพ์แบบไหน
+func TestNormalizeCanonicalForBanAndAlias(t *testing.T) {
+	tests := []struct {
+		want     string
+		variants []string
+	}{
+		{"spam", []string{"spam", "SPAM", "#Spam", " #spam "}},
+		{"js", []string{"js", "JS", "#Js"}},
+		{"caf\u00e9", []string{"caf\u00e9", "cafe\u0301", "#CAF\u00c9"}},
+	}
+	for _, tt := range tests {
+		for _, v := range tt.variants {
+			if got := Normalize(v); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", v, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestResolveSQL(t *testing.T) {
+	for _, part := range []string{"tag_aliases", "a.alias_name = $1", "banned_tags", "b.banned_name = $1"} {
+		if !strings.Contains(ResolveSQL, part) {
+			t.Errorf("ResolveSQL missing %q", part)
+		}
+	}
+}
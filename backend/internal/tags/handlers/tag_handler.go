@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"chaladshare_backend/internal/middleware"
+	"chaladshare_backend/internal/pagination"
+	"chaladshare_backend/internal/tags/models"
+	"chaladshare_backend/internal/tags/service"
+)
+
+type TagHandler struct {
+	tagService service.TagService
+}
+
+func NewTagHandler(tagService service.TagService) *TagHandler {
+	return &TagHandler{tagService: tagService}
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrTagNotFound),
+		errors.Is(err, models.ErrAliasNotFound),
+		errors.Is(err, models.ErrBanNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrInvalidSort),
+		errors.Is(err, models.ErrInvalidName),
+		errors.Is(err, models.ErrMergeSelf),
+		errors.Is(err, pagination.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrAliasIsTag), errors.Is(err, models.ErrTagBanned):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// GET /api/v1/tags/:name?sort=latest|popular&cursor=&limit=
+// สถิติของแท็ก + โพสต์ที่ผู้ชมเห็นได้
+func (h *TagHandler) GetTag(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+	page, err := h.tagService.GetTagPage(uid, c.Param("name"), c.Query("sort"), c.Query("cursor"), limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tag": page.Tag, "data": page.Items, "next_cursor": page.NextCursor})
+}
+
+// PUT /api/v1/tags/:name/follow
+func (h *TagHandler) FollowTag(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	tag, err := h.tagService.FollowTag(uid, c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tag})
+}
+
+// DELETE /api/v1/tags/:name/follow
+func (h *TagHandler) UnfollowTag(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	tag, err := h.tagService.UnfollowTag(uid, c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tag})
+}
+
+// GET /api/v1/interests/tags (แท็กที่ตัวเองติดตาม)
+func (h *TagHandler) ListFollowedTags(c *gin.Context) {
+	uid := c.GetInt(middleware.CtxUserID)
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	tags, err := h.tagService.ListFollowedTags(uid)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// GET /api/v1/admin/tags/aliases
+func (h *TagHandler) ListAliases(c *gin.Context) {
+	aliases, err := h.tagService.ListAliases()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": aliases})
+}
+
+// PUT /api/v1/admin/tags/aliases/:alias  body: {"tag": "calculus"}
+func (h *TagHandler) SetAlias(c *gin.Context) {
+	var req models.SetAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	alias, err := h.tagService.SetAlias(c.GetInt(middleware.CtxUserID), c.Param("alias"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": alias})
+}
+
+// DELETE /api/v1/admin/tags/aliases/:alias
+func (h *TagHandler) DeleteAlias(c *gin.Context) {
+	if err := h.tagService.DeleteAlias(c.Param("alias")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "alias deleted"})
+}
+
+// POST /api/v1/admin/tags/:name/merge  body: {"into": "calculus"}
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	var req models.MergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.tagService.MergeTags(c.GetInt(middleware.CtxUserID), c.Param("name"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// GET /api/v1/admin/tags/banned
+func (h *TagHandler) ListBanned(c *gin.Context) {
+	banned, err := h.tagService.ListBanned()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": banned})
+}
+
+// PUT /api/v1/admin/tags/banned/:name  body: {"reason": "..."} (ไม่ส่ง body ได้)
+func (h *TagHandler) BanTag(c *gin.Context) {
+	var req models.BanRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := h.tagService.BanTag(c.GetInt(middleware.CtxUserID), c.Param("name"), req); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "tag banned"})
+}
+
+// DELETE /api/v1/admin/tags/banned/:name
+func (h *TagHandler) UnbanTag(c *gin.Context) {
+	if err := h.tagService.UnbanTag(c.Param("name")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "tag unbanned"})
+}
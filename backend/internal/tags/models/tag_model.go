@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	postmodels "chaladshare_backend/internal/posts/models"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrInvalidSort = errors.New("sort must be latest or popular")
+
+	ErrInvalidName   = errors.New("invalid tag name")
+	ErrAliasNotFound = errors.New("alias not found")
+	ErrAliasIsTag    = errors.New("alias name is an existing tag, merge it instead")
+	ErrTagBanned     = errors.New("tag is banned")
+	ErrBanNotFound   = errors.New("tag is not banned")
+	ErrMergeSelf     = errors.New("cannot merge a tag into itself")
+)
+
+// ลำดับโพสต์ในหน้าแท็ก
+const (
+	SortLatest  = "latest"  // ใหม่→เก่า
+	SortPopular = "popular" // like + 2*save มากไปน้อย
+)
+
+// สถิติของแท็ก นับเฉพาะโพสต์ที่ผู้ชมเห็นได้
+type Tag struct {
+	TagID                int    `json:"tag_id"`
+	Name                 string `json:"tag_name"`
+	PostCount            int    `json:"post_count"`
+	PostsThisWeek        int    `json:"posts_this_week"`
+	FollowerCount        int    `json:"follower_count"`
+	NewFollowersThisWeek int    `json:"new_followers_this_week"`
+	IsFollowing          bool   `json:"is_following"`
+}
+
+type TagPage struct {
+	Tag        Tag                       `json:"tag"`
+	Items      []postmodels.PostResponse `json:"items"`
+	NextCursor string                    `json:"next_cursor"`
+}
+
+// ชื่อพ้อง → แท็ก canonical
+type TagAlias struct {
+	Alias     string    `json:"alias"`
+	TagID     int       `json:"tag_id"`
+	TagName   string    `json:"tag_name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BannedTag struct {
+	Name       string    `json:"tag_name"`
+	Reason     *string   `json:"reason,omitempty"`
+	BannedByID *int      `json:"banned_by,omitempty"`
+	BannedAt   time.Time `json:"banned_at"`
+}
+
+type SetAliasRequest struct {
+	Tag string `json:"tag" binding:"required"`
+}
+
+type MergeRequest struct {
+	Into string `json:"into" binding:"required"`
+}
+
+type BanRequest struct {
+	Reason *string `json:"reason"`
+}
+
+// ผลของการรวมแท็ก
+type MergeResult struct {
+	Tag        Tag `json:"tag"`
+	MovedPosts int `json:"moved_posts"`
+}
@@ -0,0 +1,429 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"chaladshare_backend/internal/pagination"
+	"chaladshare_backend/internal/tags/models"
+	"chaladshare_backend/internal/visibility"
+)
+
+type TagRepository interface {
+	GetTag(viewerID int, name string) (*models.Tag, error)
+	ListFollowedTags(userID int) ([]models.Tag, error)
+	ListTagPosts(viewerID, tagID int, sort string, after *pagination.Keyset, limit int) ([]pagination.Keyset, error)
+
+	FollowTag(userID, tagID int) error
+	UnfollowTag(userID, tagID int) error
+
+	// แอดมิน: alias / merge / แบน
+	ListAliases() ([]models.TagAlias, error)
+	SetAlias(alias, target string, adminID int) (*models.TagAlias, error)
+	DeleteAlias(alias string) error
+	MergeTags(source, target string, adminID int) (moved int, into string, err error)
+	ListBanned() ([]models.BannedTag, error)
+	BanTag(name string, reason *string, adminID int) error
+	UnbanTag(name string) error
+}
+
+type tagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// $1 = ผู้ชม (นับโพสต์ตาม visibility ของผู้ชม)
+const qTagSelect = `
+	SELECT t.tag_id, t.tag_name,
+		COALESCE(pc.total, 0), COALESCE(pc.this_week, 0),
+		(SELECT COUNT(*) FROM tag_follows tf WHERE tf.tag_follow_tag_id = t.tag_id) AS follower_count,
+		(
+			SELECT COUNT(*) FROM tag_follows tf
+			WHERE tf.tag_follow_tag_id = t.tag_id
+			  AND tf.tag_follow_created_at >= now() - interval '7 days'
+		) AS new_followers_this_week,
+		EXISTS (
+			SELECT 1 FROM tag_follows tf
+			WHERE tf.tag_follow_tag_id = t.tag_id AND tf.tag_follow_user_id = $1
+		) AS is_following
+	FROM tags t
+	LEFT JOIN LATERAL (
+		SELECT COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE p.post_created_at >= now() - interval '7 days') AS this_week
+		FROM post_tags pt
+		JOIN posts p ON p.post_id = pt.post_tag_post_id
+		WHERE pt.post_tag_tag_id = t.tag_id
+		  AND ` + visibility.PostPublished + `
+		  AND ` + visibility.PostVisible + `
+	) pc ON TRUE
+`
+
+// tag_id ของชื่อ (ถ้าเป็น alias ใช้แท็ก canonical) param = placeholder ของชื่อ
+func resolveTagID(param string) string {
+	return `COALESCE(
+		(SELECT a.alias_tag_id FROM tag_aliases a WHERE a.alias_name = ` + param + `),
+		(SELECT t2.tag_id FROM tags t2 WHERE t2.tag_name = ` + param + `))`
+}
+
+func scanTag(row interface{ Scan(...any) error }) (*models.Tag, error) {
+	var t models.Tag
+	if err := row.Scan(&t.TagID, &t.Name, &t.PostCount, &t.PostsThisWeek,
+		&t.FollowerCount, &t.NewFollowersThisWeek, &t.IsFollowing); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *tagRepository) GetTag(viewerID int, name string) (*models.Tag, error) {
+	t, err := scanTag(r.db.QueryRow(qTagSelect+` WHERE t.tag_id = `+resolveTagID("$2")+`;`, viewerID, name))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrTagNotFound
+	}
+	return t, err
+}
+
+func (r *tagRepository) ListFollowedTags(userID int) ([]models.Tag, error) {
+	rows, err := r.db.Query(qTagSelect+`
+		JOIN tag_follows me ON me.tag_follow_tag_id = t.tag_id AND me.tag_follow_user_id = $1
+		ORDER BY t.tag_name;`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.Tag, 0)
+	for rows.Next() {
+		t, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *t)
+	}
+	return out, rows.Err()
+}
+
+// โพสต์ที่ผู้ชมเห็นได้ในแท็กนี้ คืน (post_id, post_created_at) ตามลำดับ
+// latest ใช้ keyset, popular ใช้ offset (คะแนนเปลี่ยนได้ระหว่างหน้า)
+func (r *tagRepository) ListTagPosts(viewerID, tagID int, sort string, after *pagination.Keyset, limit int) ([]pagination.Keyset, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	switch sort {
+	case models.SortPopular:
+		offset := 0
+		if after != nil {
+			offset = after.Offset
+		}
+		rows, err = r.db.Query(`
+			SELECT p.post_id, p.post_created_at
+			FROM post_tags pt
+			JOIN posts p ON p.post_id = pt.post_tag_post_id
+			LEFT JOIN post_stats ps ON ps.post_stats_post_id = p.post_id
+			WHERE pt.post_tag_tag_id = $2
+			  AND `+visibility.PostPublished+`
+			  AND `+visibility.PostVisible+`
+			ORDER BY (COALESCE(ps.post_like_count, 0) + 2 * COALESCE(ps.post_save_count, 0)) DESC,
+				p.post_created_at DESC, p.post_id DESC
+			OFFSET $3 LIMIT $4;`, viewerID, tagID, offset, limit)
+	default:
+		var afterTS, afterID interface{}
+		if after != nil {
+			afterTS, afterID = after.CreatedAt, after.ID
+		}
+		rows, err = r.db.Query(`
+			SELECT p.post_id, p.post_created_at
+			FROM post_tags pt
+			JOIN posts p ON p.post_id = pt.post_tag_post_id
+			WHERE pt.post_tag_tag_id = $2
+			  AND `+visibility.PostPublished+`
+			  AND `+visibility.PostVisible+`
+			  AND ($3::timestamptz IS NULL OR (p.post_created_at, p.post_id) < ($3::timestamptz, $4::int))
+			ORDER BY p.post_created_at DESC, p.post_id DESC
+			LIMIT $5;`, viewerID, tagID, afterTS, afterID, limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list tag posts: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]pagination.Keyset, 0, limit)
+	for rows.Next() {
+		var k pagination.Keyset
+		if err := rows.Scan(&k.ID, &k.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
+
+// ติดตามซ้ำได้ (idempotent)
+func (r *tagRepository) FollowTag(userID, tagID int) error {
+	if _, err := r.db.Exec(`
+		INSERT INTO tag_follows (tag_follow_user_id, tag_follow_tag_id)
+		VALUES ($1, $2) ON CONFLICT DO NOTHING;`, userID, tagID); err != nil {
+		return fmt.Errorf("follow tag: %w", err)
+	}
+	return nil
+}
+
+func (r *tagRepository) UnfollowTag(userID, tagID int) error {
+	if _, err := r.db.Exec(`
+		DELETE FROM tag_follows
+		WHERE tag_follow_user_id = $1 AND tag_follow_tag_id = $2;`, userID, tagID); err != nil {
+		return fmt.Errorf("unfollow tag: %w", err)
+	}
+	return nil
+}
+
+type queryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// คืน (tag_id, ชื่อ canonical) ของชื่อหรือ alias
+func lookupTag(q queryer, name string) (int, string, error) {
+	var (
+		id        int
+		canonical string
+	)
+	err := q.QueryRow(`SELECT t.tag_id, t.tag_name FROM tags t WHERE t.tag_id = `+resolveTagID("$1"), name).
+		Scan(&id, &canonical)
+	if err == sql.ErrNoRows {
+		return 0, "", models.ErrTagNotFound
+	}
+	return id, canonical, err
+}
+
+const qAliasSelect = `
+	SELECT a.alias_name, a.alias_tag_id, t.tag_name, a.alias_created_at
+	FROM tag_aliases a
+	JOIN tags t ON t.tag_id = a.alias_tag_id
+`
+
+func (r *tagRepository) ListAliases() ([]models.TagAlias, error) {
+	rows, err := r.db.Query(qAliasSelect + ` ORDER BY t.tag_name, a.alias_name;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.TagAlias, 0)
+	for rows.Next() {
+		var a models.TagAlias
+		if err := rows.Scan(&a.Alias, &a.TagID, &a.TagName, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// ตั้ง alias (ชี้ไปแท็กใหม่ได้ถ้ามีอยู่แล้ว) target ที่เป็น alias จะใช้แท็ก canonical ของมัน
+func (r *tagRepository) SetAlias(alias, target string, adminID int) (*models.TagAlias, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var banned, isTag bool
+	if err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM banned_tags WHERE banned_name = $1),
+			EXISTS (SELECT 1 FROM tags WHERE tag_name = $1);`, alias).Scan(&banned, &isTag); err != nil {
+		return nil, err
+	}
+	if banned {
+		return nil, models.ErrTagBanned
+	}
+	if isTag {
+		return nil, models.ErrAliasIsTag
+	}
+
+	tagID, _, err := lookupTag(tx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO tag_aliases (alias_name, alias_tag_id, alias_created_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (alias_name) DO UPDATE SET
+			alias_tag_id     = EXCLUDED.alias_tag_id,
+			alias_created_by = EXCLUDED.alias_created_by,
+			alias_created_at = now();`, alias, tagID, adminID); err != nil {
+		return nil, fmt.Errorf("set alias: %w", err)
+	}
+
+	var a models.TagAlias
+	if err := tx.QueryRow(qAliasSelect+` WHERE a.alias_name = $1;`, alias).
+		Scan(&a.Alias, &a.TagID, &a.TagName, &a.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return &a, nil
+}
+
+func (r *tagRepository) DeleteAlias(alias string) error {
+	res, err := r.db.Exec(`DELETE FROM tag_aliases WHERE alias_name = $1`, alias)
+	if err != nil {
+		return fmt.Errorf("delete alias: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrAliasNotFound
+	}
+	return nil
+}
+
+// รวมแท็ก source เข้า target: ย้าย post_tags / ผู้ติดตาม / หัวข้อ / feedback
+// alias เดิมของ source ชี้ไป target แล้วลบ source และเก็บชื่อเป็น alias
+func (r *tagRepository) MergeTags(source, target string, adminID int) (int, string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, "", err
+	}
+	defer tx.Rollback()
+
+	var srcID int
+	err = tx.QueryRow(`SELECT tag_id FROM tags WHERE tag_name = $1`, source).Scan(&srcID)
+	if err == sql.ErrNoRows {
+		return 0, "", models.ErrTagNotFound
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	dstID, dstName, err := lookupTag(tx, target)
+	if err != nil {
+		return 0, "", err
+	}
+	if srcID == dstID {
+		return 0, "", models.ErrMergeSelf
+	}
+
+	// ล็อกทั้งสองแท็กตามลำดับ id (กัน deadlock เมื่อรวมสวนทางกัน)
+	if _, err := tx.Exec(`SELECT 1 FROM tags WHERE tag_id IN ($1, $2) ORDER BY tag_id FOR UPDATE`, srcID, dstID); err != nil {
+		return 0, "", fmt.Errorf("lock tags: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO post_tags (post_tag_post_id, post_tag_tag_id)
+		SELECT post_tag_post_id, $2 FROM post_tags WHERE post_tag_tag_id = $1
+		ON CONFLICT DO NOTHING;`, srcID, dstID); err != nil {
+		return 0, "", fmt.Errorf("move post tags: %w", err)
+	}
+	res, err := tx.Exec(`DELETE FROM post_tags WHERE post_tag_tag_id = $1`, srcID)
+	if err != nil {
+		return 0, "", fmt.Errorf("clear post tags: %w", err)
+	}
+	moved, _ := res.RowsAffected()
+
+	if _, err := tx.Exec(`
+		INSERT INTO tag_follows (tag_follow_user_id, tag_follow_tag_id, tag_follow_created_at)
+		SELECT tag_follow_user_id, $2, tag_follow_created_at FROM tag_follows WHERE tag_follow_tag_id = $1
+		ON CONFLICT DO NOTHING;`, srcID, dstID); err != nil {
+		return 0, "", fmt.Errorf("move tag follows: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO topic_tags (topic_tag_topic_id, topic_tag_tag_id)
+		SELECT topic_tag_topic_id, $2 FROM topic_tags WHERE topic_tag_tag_id = $1
+		ON CONFLICT DO NOTHING;`, srcID, dstID); err != nil {
+		return 0, "", fmt.Errorf("move topic tags: %w", err)
+	}
+	if _, err := tx.Exec(`
+		UPDATE recommend_feedback rf SET feedback_tag_id = $2
+		WHERE rf.feedback_type = 'hide_tag' AND rf.feedback_tag_id = $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM recommend_feedback x
+			WHERE x.feedback_type = 'hide_tag'
+			  AND x.feedback_user_id = rf.feedback_user_id
+			  AND x.feedback_tag_id = $2
+		  );`, srcID, dstID); err != nil {
+		return 0, "", fmt.Errorf("move hidden tags: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE tag_aliases SET alias_tag_id = $2 WHERE alias_tag_id = $1`, srcID, dstID); err != nil {
+		return 0, "", fmt.Errorf("move aliases: %w", err)
+	}
+
+	// แถวที่เหลือ (ซ้ำกับ target) ถูกลบตาม cascade
+	if _, err := tx.Exec(`DELETE FROM tags WHERE tag_id = $1`, srcID); err != nil {
+		return 0, "", fmt.Errorf("delete merged tag: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO tag_aliases (alias_name, alias_tag_id, alias_created_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (alias_name) DO UPDATE SET alias_tag_id = EXCLUDED.alias_tag_id;`,
+		source, dstID, adminID); err != nil {
+		return 0, "", fmt.Errorf("alias merged tag: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, "", fmt.Errorf("commit: %w", err)
+	}
+	return int(moved), dstName, nil
+}
+
+func (r *tagRepository) ListBanned() ([]models.BannedTag, error) {
+	rows, err := r.db.Query(`
+		SELECT banned_name, banned_reason, banned_by_user_id, banned_at
+		FROM banned_tags
+		ORDER BY banned_at DESC, banned_name;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.BannedTag, 0)
+	for rows.Next() {
+		var b models.BannedTag
+		if err := rows.Scan(&b.Name, &b.Reason, &b.BannedByID, &b.BannedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// แบนชื่อ: ลบ alias ชื่อนี้ และลบแท็กชื่อนี้ออกจากทุกโพสต์ (cascade)
+func (r *tagRepository) BanTag(name string, reason *string, adminID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO banned_tags (banned_name, banned_reason, banned_by_user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (banned_name) DO UPDATE SET
+			banned_reason     = EXCLUDED.banned_reason,
+			banned_by_user_id = EXCLUDED.banned_by_user_id;`, name, reason, adminID); err != nil {
+		return fmt.Errorf("ban tag: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM tag_aliases WHERE alias_name = $1`, name); err != nil {
+		return fmt.Errorf("delete banned alias: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM tags WHERE tag_name = $1`, name); err != nil {
+		return fmt.Errorf("delete banned tag: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+func (r *tagRepository) UnbanTag(name string) error {
+	res, err := r.db.Exec(`DELETE FROM banned_tags WHERE banned_name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("unban tag: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrBanNotFound
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"strings"
+
+	"chaladshare_backend/internal/pagination"
+	postservice "chaladshare_backend/internal/posts/service"
+	"chaladshare_backend/internal/tagname"
+	"chaladshare_backend/internal/tags/models"
+	"chaladshare_backend/internal/tags/repository"
+)
+
+type TagService interface {
+	GetTagPage(viewerID int, name, sort, cursor string, limit int) (*models.TagPage, error)
+	ListFollowedTags(userID int) ([]models.Tag, error)
+	FollowTag(userID int, name string) (*models.Tag, error)
+	UnfollowTag(userID int, name string) (*models.Tag, error)
+
+	ListAliases() ([]models.TagAlias, error)
+	SetAlias(adminID int, alias string, req models.SetAliasRequest) (*models.TagAlias, error)
+	DeleteAlias(alias string) error
+	MergeTags(adminID int, source string, req models.MergeRequest) (*models.MergeResult, error)
+	ListBanned() ([]models.BannedTag, error)
+	BanTag(adminID int, name string, req models.BanRequest) error
+	UnbanTag(name string) error
+}
+
+type tagService struct {
+	repo        repository.TagRepository
+	postService postservice.PostService
+	cursors     *pagination.Codec
+}
+
+func NewTagService(repo repository.TagRepository, postService postservice.PostService, cursors *pagination.Codec) TagService {
+	return &tagService{repo: repo, postService: postService, cursors: cursors}
+}
+
+// ชื่อใน URL อาจมี # หรือตัวพิมพ์ใหญ่ (กติกาเดียวกับแท็กของโพสต์)
+func normalizeName(name string) (string, error) {
+	n := tagname.Normalize(name)
+	if n == "" {
+		return "", models.ErrInvalidName
+	}
+	return n, nil
+}
+
+// ชื่อที่ใช้ไม่ได้ = ไม่มีแท็กนี้ (หน้าแท็ก / ติดตาม)
+func (s *tagService) getTag(viewerID int, name string) (*models.Tag, error) {
+	n, err := normalizeName(name)
+	if err != nil {
+		return nil, models.ErrTagNotFound
+	}
+	return s.repo.GetTag(viewerID, n)
+}
+
+func normalizeSort(sort string) (string, error) {
+	switch s := strings.ToLower(strings.TrimSpace(sort)); s {
+	case "", models.SortLatest:
+		return models.SortLatest, nil
+	case models.SortPopular:
+		return s, nil
+	default:
+		return "", models.ErrInvalidSort
+	}
+}
+
+func (s *tagService) decodeCursor(cursor string) (*pagination.Keyset, error) {
+	var k pagination.Keyset
+	ok, err := s.cursors.Decode(cursor, &k)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (s *tagService) GetTagPage(viewerID int, name, sort, cursor string, limit int) (*models.TagPage, error) {
+	sort, err := normalizeSort(sort)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := s.getTag(viewerID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = pagination.ClampLimit(limit)
+	keys, err := s.repo.ListTagPosts(viewerID, tag.TagID, sort, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.TagPage{Tag: *tag}
+	offset := 0
+	if after != nil {
+		offset = after.Offset
+	}
+	if len(keys) > limit {
+		keys = keys[:limit]
+		last := keys[limit-1]
+		next := pagination.Keyset{CreatedAt: last.CreatedAt, ID: last.ID, Offset: offset + limit}
+		if page.NextCursor, err = s.cursors.Encode(next); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int, len(keys))
+	for i, k := range keys {
+		ids[i] = k.ID
+	}
+	if page.Items, err = s.postService.GetPostsByIDs(viewerID, ids); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (s *tagService) ListFollowedTags(userID int) ([]models.Tag, error) {
+	return s.repo.ListFollowedTags(userID)
+}
+
+// คืนสถิติล่าสุดหลังติดตาม
+func (s *tagService) FollowTag(userID int, name string) (*models.Tag, error) {
+	tag, err := s.getTag(userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.FollowTag(userID, tag.TagID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTag(userID, tag.Name)
+}
+
+func (s *tagService) UnfollowTag(userID int, name string) (*models.Tag, error) {
+	tag, err := s.getTag(userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UnfollowTag(userID, tag.TagID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTag(userID, tag.Name)
+}
+
+func (s *tagService) ListAliases() ([]models.TagAlias, error) {
+	return s.repo.ListAliases()
+}
+
+func (s *tagService) SetAlias(adminID int, alias string, req models.SetAliasRequest) (*models.TagAlias, error) {
+	a, err := normalizeName(alias)
+	if err != nil {
+		return nil, err
+	}
+	target, err := normalizeName(req.Tag)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.SetAlias(a, target, adminID)
+}
+
+func (s *tagService) DeleteAlias(alias string) error {
+	a, err := normalizeName(alias)
+	if err != nil {
+		return models.ErrAliasNotFound
+	}
+	return s.repo.DeleteAlias(a)
+}
+
+func (s *tagService) MergeTags(adminID int, source string, req models.MergeRequest) (*models.MergeResult, error) {
+	src, err := normalizeName(source)
+	if err != nil {
+		return nil, err
+	}
+	into, err := normalizeName(req.Into)
+	if err != nil {
+		return nil, err
+	}
+	moved, canonical, err := s.repo.MergeTags(src, into, adminID)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := s.repo.GetTag(adminID, canonical)
+	if err != nil {
+		return nil, err
+	}
+	return &models.MergeResult{Tag: *tag, MovedPosts: moved}, nil
+}
+
+func (s *tagService) ListBanned() ([]models.BannedTag, error) {
+	return s.repo.ListBanned()
+}
+
+func (s *tagService) BanTag(adminID int, name string, req models.BanRequest) error {
+	n, err := normalizeName(name)
+	if err != nil {
+		return err
+	}
+	if req.Reason != nil {
+		r := strings.TrimSpace(*req.Reason)
+		if r == "" {
+			req.Reason = nil
+		} else {
+			req.Reason = &r
+		}
+	}
+	return s.repo.BanTag(n, req.Reason, adminID)
+}
+
+func (s *tagService) UnbanTag(name string) error {
+	n, err := normalizeName(name)
+	if err != nil {
+		return models.ErrBanNotFound
+	}
+	return s.repo.UnbanTag(n)
+}
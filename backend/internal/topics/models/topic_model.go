@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrTopicNotFound    = errors.New("topic not found")
+	ErrTopicNameTaken   = errors.New("topic name already exists")
+	ErrInvalidTopicName = errors.New("invalid topic name")
+	ErrTooManyInterests = errors.New("too many topics")
+)
+
+type Topic struct {
+	TopicID   int      `json:"topic_id"`
+	TopicName string   `json:"topic_name"`
+	Tags      []string `json:"tags"`
+}
+
+// ความสนใจของผู้ใช้
+type UserInterest struct {
+	TopicID   int       `json:"topic_id"`
+	TopicName string    `json:"topic_name"`
+	CreatedAt time.Time `json:"interest_created_at"`
+}
+
+// admin: สร้าง/แก้ไขหัวข้อ พร้อมแท็กที่ผูกกับหัวข้อ
+type TopicRequest struct {
+	TopicName string   `json:"topic_name" binding:"required"`
+	Tags      []string `json:"tags"`
+}
+
+// onboarding: เลือกหัวข้อที่สนใจ (แทนที่ชุดเดิมทั้งหมด)
+type SetInterestsRequest struct {
+	TopicIDs []int `json:"topic_ids"`
+}
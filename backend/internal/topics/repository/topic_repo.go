@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"chaladshare_backend/internal/tagname"
+	"chaladshare_backend/internal/topics/models"
+)
+
+type TopicRepository interface {
+	// topics (admin)
+	ListTopics() ([]models.Topic, error)
+	GetTopic(topicID int) (*models.Topic, error)
+	CreateTopic(name string, tags []string) (int, error)
+	UpdateTopic(topicID int, name string, tags []string) error
+	DeleteTopic(topicID int) error
+
+	// interests (onboarding)
+	ListInterests(userID int) ([]models.UserInterest, error)
+	SetInterests(userID int, topicIDs []int) error
+}
+
+type topicRepository struct {
+	db *sql.DB
+}
+
+func NewTopicRepository(db *sql.DB) TopicRepository {
+	return &topicRepository{db: db}
+}
+
+const qTopicSelect = `
+	SELECT tp.topic_id, tp.topic_name,
+		ARRAY_REMOVE(ARRAY_AGG(t.tag_name ORDER BY t.tag_name), NULL) AS tags
+	FROM topics tp
+	LEFT JOIN topic_tags tt ON tt.topic_tag_topic_id = tp.topic_id
+	LEFT JOIN tags t ON t.tag_id = tt.topic_tag_tag_id
+`
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func (r *topicRepository) ListTopics() ([]models.Topic, error) {
+	rows, err := r.db.Query(qTopicSelect + ` GROUP BY tp.topic_id ORDER BY tp.topic_name;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.Topic, 0, 16)
+	for rows.Next() {
+		var (
+			t    models.Topic
+			tags pq.StringArray
+		)
+		if err := rows.Scan(&t.TopicID, &t.TopicName, &tags); err != nil {
+			return nil, err
+		}
+		t.Tags = []string(tags)
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (r *topicRepository) GetTopic(topicID int) (*models.Topic, error) {
+	var (
+		t    models.Topic
+		tags pq.StringArray
+	)
+	err := r.db.QueryRow(qTopicSelect+` WHERE tp.topic_id = $1 GROUP BY tp.topic_id;`, topicID).
+		Scan(&t.TopicID, &t.TopicName, &tags)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrTopicNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Tags = []string(tags)
+	return &t, nil
+}
+
+// ผูกแท็กที่กำหนด + แท็กเดิมที่ชื่อตรงกับชื่อหัวข้อ (กติกาเดียวกับ trigger trg_tags_map_topics)
+func linkTopicTags(tx *sql.Tx, topicID int, name string, tags []string) error {
+	upsertTag := `INSERT INTO tags (tag_name) VALUES ($1) ON CONFLICT (tag_name) DO UPDATE
+				  SET tag_name = EXCLUDED.tag_name RETURNING tag_id;`
+	link := `INSERT INTO topic_tags (topic_tag_topic_id, topic_tag_tag_id)
+			 VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+
+	for _, t := range tags {
+		// alias → แท็ก canonical, ชื่อที่ถูกแบนข้ามไป
+		var name string
+		err := tx.QueryRow(tagname.ResolveSQL, t).Scan(&name)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("resolve tag %q: %w", t, err)
+		}
+
+		var tagID int
+		if err := tx.QueryRow(upsertTag, name).Scan(&tagID); err != nil {
+			return fmt.Errorf("upsert tag %q: %w", name, err)
+		}
+		if _, err := tx.Exec(link, topicID, tagID); err != nil {
+			return fmt.Errorf("link tag %q: %w", name, err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO topic_tags (topic_tag_topic_id, topic_tag_tag_id)
+		SELECT $1, t.tag_id FROM tags t
+		WHERE t.tag_name = lower($2)
+		ON CONFLICT DO NOTHING;`, topicID, name); err != nil {
+		return fmt.Errorf("auto map tags: %w", err)
+	}
+	return nil
+}
+
+func (r *topicRepository) CreateTopic(name string, tags []string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var topicID int
+	if err := tx.QueryRow(`INSERT INTO topics (topic_name) VALUES ($1) RETURNING topic_id;`, name).
+		Scan(&topicID); err != nil {
+		if isUniqueViolation(err) {
+			return 0, models.ErrTopicNameTaken
+		}
+		return 0, fmt.Errorf("create topic: %w", err)
+	}
+
+	if err := linkTopicTags(tx, topicID, name, tags); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return topicID, nil
+}
+
+// tags == nil คือไม่แก้แท็ก, slice ว่างคือเคลียร์แท็กที่ผูกไว้
+func (r *topicRepository) UpdateTopic(topicID int, name string, tags []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE topics SET topic_name = $1 WHERE topic_id = $2;`, name, topicID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrTopicNameTaken
+		}
+		return fmt.Errorf("update topic: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrTopicNotFound
+	}
+
+	if tags != nil {
+		if _, err := tx.Exec(`DELETE FROM topic_tags WHERE topic_tag_topic_id = $1`, topicID); err != nil {
+			return fmt.Errorf("clear topic tags: %w", err)
+		}
+	}
+	if err := linkTopicTags(tx, topicID, name, tags); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+func (r *topicRepository) DeleteTopic(topicID int) error {
+	res, err := r.db.Exec(`DELETE FROM topics WHERE topic_id = $1`, topicID)
+	if err != nil {
+		return fmt.Errorf("delete topic: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrTopicNotFound
+	}
+	return nil
+}
+
+func (r *topicRepository) ListInterests(userID int) ([]models.UserInterest, error) {
+	rows, err := r.db.Query(`
+		SELECT tp.topic_id, tp.topic_name, ui.interest_created_at
+		FROM user_interests ui
+		JOIN topics tp ON tp.topic_id = ui.interest_topic_id
+		WHERE ui.interest_user_id = $1
+		ORDER BY tp.topic_name;`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]models.UserInterest, 0, 8)
+	for rows.Next() {
+		var it models.UserInterest
+		if err := rows.Scan(&it.TopicID, &it.TopicName, &it.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// แทนที่ความสนใจทั้งหมดของผู้ใช้ (หัวข้อที่เลือกไว้แล้วจะคง interest_created_at เดิม)
+func (r *topicRepository) SetInterests(userID int, topicIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var matched int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM topics WHERE topic_id = ANY($1)`, pq.Array(topicIDs)).
+		Scan(&matched); err != nil {
+		return err
+	}
+	if matched != len(topicIDs) {
+		return models.ErrTopicNotFound
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM user_interests
+		WHERE interest_user_id = $1 AND NOT (interest_topic_id = ANY($2));`,
+		userID, pq.Array(topicIDs)); err != nil {
+		return fmt.Errorf("clear interests: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO user_interests (interest_user_id, interest_topic_id)
+		SELECT $1, UNNEST($2::int[])
+		ON CONFLICT DO NOTHING;`, userID, pq.Array(topicIDs)); err != nil {
+		return fmt.Errorf("insert interests: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
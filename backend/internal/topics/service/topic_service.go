@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"chaladshare_backend/internal/tagname"
+	"chaladshare_backend/internal/topics/models"
+	"chaladshare_backend/internal/topics/repository"
+)
+
+type TopicService interface {
+	ListTopics() ([]models.Topic, error)
+	GetTopic(topicID int) (*models.Topic, error)
+	CreateTopic(req models.TopicRequest) (*models.Topic, error)
+	UpdateTopic(topicID int, req models.TopicRequest) (*models.Topic, error)
+	DeleteTopic(topicID int) error
+
+	ListInterests(userID int) ([]models.UserInterest, error)
+	SetInterests(userID int, topicIDs []int) ([]models.UserInterest, error)
+}
+
+type topicService struct {
+	topicRepo repository.TopicRepository
+}
+
+func NewTopicService(topicRepo repository.TopicRepository) TopicService {
+	return &topicService{topicRepo: topicRepo}
+}
+
+const (
+	maxTopicNameLen = 20 // ตาม topics.topic_name varchar(20)
+	maxTopicTags    = 30
+	maxInterests    = 20
+)
+
+func normalizeTopicName(name string) (string, error) {
+	n := strings.ToLower(strings.TrimSpace(name))
+	if n == "" || utf8.RuneCountInString(n) > maxTopicNameLen {
+		return "", models.ErrInvalidTopicName
+	}
+	return n, nil
+}
+
+// แท็กของหัวข้อ: กติกาเดียวกับแท็กของโพสต์ ไม่ซ้ำ
+func normalizeTopicTags(in []string) []string {
+	if in == nil {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(in))
+	out := make([]string, 0, len(in))
+	for _, t := range in {
+		tag := tagname.Normalize(t)
+		if tag == "" {
+			continue
+		}
+		if _, dup := seen[tag]; dup {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+		if len(out) >= maxTopicTags {
+			break
+		}
+	}
+	return out
+}
+
+func (s *topicService) ListTopics() ([]models.Topic, error) {
+	return s.topicRepo.ListTopics()
+}
+
+func (s *topicService) GetTopic(topicID int) (*models.Topic, error) {
+	if topicID <= 0 {
+		return nil, models.ErrTopicNotFound
+	}
+	return s.topicRepo.GetTopic(topicID)
+}
+
+func (s *topicService) CreateTopic(req models.TopicRequest) (*models.Topic, error) {
+	name, err := normalizeTopicName(req.TopicName)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.topicRepo.CreateTopic(name, normalizeTopicTags(req.Tags))
+	if err != nil {
+		return nil, err
+	}
+	return s.topicRepo.GetTopic(id)
+}
+
+func (s *topicService) UpdateTopic(topicID int, req models.TopicRequest) (*models.Topic, error) {
+	if topicID <= 0 {
+		return nil, models.ErrTopicNotFound
+	}
+	name, err := normalizeTopicName(req.TopicName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.topicRepo.UpdateTopic(topicID, name, normalizeTopicTags(req.Tags)); err != nil {
+		return nil, err
+	}
+	return s.topicRepo.GetTopic(topicID)
+}
+
+func (s *topicService) DeleteTopic(topicID int) error {
+	if topicID <= 0 {
+		return models.ErrTopicNotFound
+	}
+	return s.topicRepo.DeleteTopic(topicID)
+}
+
+func (s *topicService) ListInterests(userID int) ([]models.UserInterest, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user id")
+	}
+	return s.topicRepo.ListInterests(userID)
+}
+
+func (s *topicService) SetInterests(userID int, topicIDs []int) ([]models.UserInterest, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user id")
+	}
+
+	seen := make(map[int]struct{}, len(topicIDs))
+	ids := make([]int, 0, len(topicIDs))
+	for _, id := range topicIDs {
+		if id <= 0 {
+			return nil, models.ErrTopicNotFound
+		}
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	if len(ids) > maxInterests {
+		return nil, models.ErrTooManyInterests
+	}
+
+	if err := s.topicRepo.SetInterests(userID, ids); err != nil {
+		return nil, err
+	}
+	return s.topicRepo.ListInterests(userID)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"chaladshare_backend/internal/pagination"
+	"chaladshare_backend/internal/trending/models"
+	"chaladshare_backend/internal/trending/service"
+)
+
+type TrendingHandler struct {
+	trendingService service.TrendingService
+}
+
+func NewTrendingHandler(trendingService service.TrendingService) *TrendingHandler {
+	return &TrendingHandler{trendingService: trendingService}
+}
+
+func pageQuery(c *gin.Context) (string, int) {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = 0
+	}
+	return c.Query("cursor"), pagination.ClampLimit(limit)
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidWindow), errors.Is(err, pagination.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// GET /api/v1/posts/trending?window=24h|7d|30d&cursor=&limit=
+func (h *TrendingHandler) ListPosts(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	cursor, limit := pageQuery(c)
+	page, err := h.trendingService.ListPosts(uid, c.Query("window"), cursor, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"window":       page.Window,
+		"refreshed_at": page.RefreshedAt,
+		"data":         page.Items,
+		"next_cursor":  page.NextCursor,
+	})
+}
+
+// GET /api/v1/tags/trending?window=24h|7d|30d&limit=
+func (h *TrendingHandler) ListTags(c *gin.Context) {
+	uid := c.GetInt("user_id")
+	if uid == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	_, limit := pageQuery(c)
+	list, err := h.trendingService.ListTags(c.Query("window"), limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"window":       list.Window,
+		"refreshed_at": list.RefreshedAt,
+		"data":         list.Items,
+	})
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	postmodels "chaladshare_backend/internal/posts/models"
+)
+
+var ErrInvalidWindow = errors.New("window must be 24h, 7d or 30d")
+
+// ช่วงเวลาของ trending และ half-life ของคะแนน (ชั่วโมง)
+type Window struct {
+	Key      string
+	Hours    int
+	HalfLife float64
+}
+
+var Windows = []Window{
+	{Key: "24h", Hours: 24, HalfLife: 6},
+	{Key: "7d", Hours: 7 * 24, HalfLife: 48},
+	{Key: "30d", Hours: 30 * 24, HalfLife: 7 * 24},
+}
+
+const DefaultWindow = "24h"
+
+// น้ำหนักของกิจกรรมแต่ละแบบ
+const (
+	WeightLike     = 1.0
+	WeightSave     = 2.0
+	WeightComment  = 1.5
+	WeightRepost   = 2.0
+	WeightView     = 0.2 // นับครั้งเดียวต่อผู้ใช้ต่อวัน
+	WeightReaction = 1.0 // helpful / clear / thanks (like ใช้ WeightLike)
+)
+
+type TrendingTag struct {
+	TagID     int     `json:"tag_id"`
+	Name      string  `json:"tag_name"`
+	Score     float64 `json:"score"`
+	PostCount int     `json:"post_count"`
+	Rank      int     `json:"rank"`
+}
+
+// อันดับโพสต์ใน summary table (ใช้แบ่งหน้า)
+type RankedPost struct {
+	PostID int
+	Rank   int
+}
+
+type TrendingPostPage struct {
+	Window      string                    `json:"window"`
+	RefreshedAt *time.Time                `json:"refreshed_at"`
+	Items       []postmodels.PostResponse `json:"items"`
+	NextCursor  string                    `json:"next_cursor"`
+}
+
+type TrendingTagList struct {
+	Window      string        `json:"window"`
+	RefreshedAt *time.Time    `json:"refreshed_at"`
+	Items       []TrendingTag `json:"items"`
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"chaladshare_backend/internal/trending/models"
+	"chaladshare_backend/internal/visibility"
+)
+
+type TrendingRepository interface {
+	Refresh(w models.Window) (int, error)
+	RefreshedAt(window string) (*time.Time, error)
+	ListPosts(window string, afterRank, limit int) ([]models.RankedPost, error)
+	ListTags(window string, limit int) ([]models.TrendingTag, error)
+}
+
+type trendingRepository struct {
+	db *sql.DB
+}
+
+func NewTrendingRepository(db *sql.DB) TrendingRepository {
+	return &trendingRepository{db: db}
+}
+
+// $1 = window, $2 = ชั่วโมงย้อนหลัง, $3 = half-life (ชั่วโมง)
+// นับเฉพาะโพสต์สาธารณะที่เผยแพร่แล้ว (summary table ใช้ร่วมกันทุกผู้ชม)
+var qRefreshTrendingPosts = fmt.Sprintf(`
+	WITH ev AS (
+		SELECT like_post_id AS post_id, like_created_at AS at, %[1]g::float8 AS w
+		FROM likes WHERE like_created_at >= now() - make_interval(hours => $2)
+		UNION ALL
+		SELECT save_post_id, save_created_at, %[2]g
+		FROM saved_posts WHERE save_created_at >= now() - make_interval(hours => $2)
+		UNION ALL
+		SELECT comment_post_id, comment_created_at, %[3]g
+		FROM comments WHERE comment_created_at >= now() - make_interval(hours => $2)
+		UNION ALL
+		SELECT repost_post_id, repost_created_at, %[4]g
+		FROM reposts WHERE repost_created_at >= now() - make_interval(hours => $2)
+		UNION ALL
+		SELECT event_post_id, event_created_at, %[5]g
+		FROM post_view_events
+		WHERE event_kind = 'view' AND event_created_at >= now() - make_interval(hours => $2)
+		UNION ALL
+		SELECT reaction_post_id, reaction_created_at, %[6]g
+		FROM reactions WHERE reaction_created_at >= now() - make_interval(hours => $2)
+	),
+	scored AS (
+		SELECT ev.post_id,
+			SUM(ev.w * EXP(-LN(2) * EXTRACT(EPOCH FROM now() - ev.at) / 3600 / $3)) AS score
+		FROM ev
+		JOIN posts p ON p.post_id = ev.post_id
+		WHERE p.post_visibility = 'public' AND `+visibility.PostPublished+`
+		GROUP BY ev.post_id
+	)
+	INSERT INTO trending_posts (trending_window, trending_post_id, trending_score, trending_rank)
+	SELECT $1, post_id, score, ROW_NUMBER() OVER (ORDER BY score DESC, post_id DESC)
+	FROM scored;`,
+	models.WeightLike, models.WeightSave, models.WeightComment, models.WeightRepost, models.WeightView, models.WeightReaction)
+
+// คะแนนแท็ก = ผลรวมคะแนนของโพสต์ในแท็กนั้น
+const qRefreshTrendingTags = `
+	INSERT INTO trending_tags (trending_window, trending_tag_id, trending_score, trending_post_count, trending_rank)
+	SELECT $1, pt.post_tag_tag_id, SUM(tp.trending_score), COUNT(*),
+		ROW_NUMBER() OVER (ORDER BY SUM(tp.trending_score) DESC, pt.post_tag_tag_id)
+	FROM trending_posts tp
+	JOIN post_tags pt ON pt.post_tag_post_id = tp.trending_post_id
+	WHERE tp.trending_window = $1
+	GROUP BY pt.post_tag_tag_id;`
+
+// สร้างคะแนนของช่วงเวลาใหม่ใน transaction เดียว (อ่านระหว่างรีเฟรชจะเห็นชุดเดิม)
+func (r *trendingRepository) Refresh(w models.Window) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM trending_tags WHERE trending_window = $1`, w.Key); err != nil {
+		return 0, fmt.Errorf("clear trending_tags: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM trending_posts WHERE trending_window = $1`, w.Key); err != nil {
+		return 0, fmt.Errorf("clear trending_posts: %w", err)
+	}
+
+	res, err := tx.Exec(qRefreshTrendingPosts, w.Key, w.Hours, w.HalfLife)
+	if err != nil {
+		return 0, fmt.Errorf("compute trending_posts: %w", err)
+	}
+	n, _ := res.RowsAffected()
+
+	if _, err := tx.Exec(qRefreshTrendingTags, w.Key); err != nil {
+		return 0, fmt.Errorf("compute trending_tags: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO trending_refreshes (refresh_window, refreshed_at) VALUES ($1, now())
+		ON CONFLICT (refresh_window) DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at;`, w.Key); err != nil {
+		return 0, fmt.Errorf("mark trending refresh: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return int(n), nil
+}
+
+// nil = ยังไม่เคยรีเฟรช
+func (r *trendingRepository) RefreshedAt(window string) (*time.Time, error) {
+	var at time.Time
+	err := r.db.QueryRow(`SELECT refreshed_at FROM trending_refreshes WHERE refresh_window = $1`, window).Scan(&at)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &at, nil
+}
+
+// โพสต์ถัดจากอันดับ afterRank (ผู้ชมกรอง visibility อีกชั้นตอนโหลดโพสต์)
+func (r *trendingRepository) ListPosts(window string, afterRank, limit int) ([]models.RankedPost, error) {
+	rows, err := r.db.Query(`
+		SELECT trending_post_id, trending_rank
+		FROM trending_posts
+		WHERE trending_window = $1 AND trending_rank > $2
+		ORDER BY trending_rank
+		LIMIT $3;`, window, afterRank, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list trending posts: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]models.RankedPost, 0, limit)
+	for rows.Next() {
+		var rp models.RankedPost
+		if err := rows.Scan(&rp.PostID, &rp.Rank); err != nil {
+			return nil, err
+		}
+		out = append(out, rp)
+	}
+	return out, rows.Err()
+}
+
+func (r *trendingRepository) ListTags(window string, limit int) ([]models.TrendingTag, error) {
+	rows, err := r.db.Query(`
+		SELECT t.tag_id, t.tag_name, tt.trending_score, tt.trending_post_count, tt.trending_rank
+		FROM trending_tags tt
+		JOIN tags t ON t.tag_id = tt.trending_tag_id
+		WHERE tt.trending_window = $1
+		ORDER BY tt.trending_rank
+		LIMIT $2;`, window, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list trending tags: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]models.TrendingTag, 0, limit)
+	for rows.Next() {
+		var t models.TrendingTag
+		if err := rows.Scan(&t.TagID, &t.Name, &t.Score, &t.PostCount, &t.Rank); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"chaladshare_backend/internal/pagination"
+	postservice "chaladshare_backend/internal/posts/service"
+	"chaladshare_backend/internal/trending/models"
+	"chaladshare_backend/internal/trending/repository"
+)
+
+type TrendingService interface {
+	RefreshAll() (int, error)
+	ListPosts(viewerID int, window, cursor string, limit int) (*models.TrendingPostPage, error)
+	ListTags(window string, limit int) (*models.TrendingTagList, error)
+}
+
+type trendingService struct {
+	repo        repository.TrendingRepository
+	postService postservice.PostService
+	cursors     *pagination.Codec
+}
+
+func NewTrendingService(repo repository.TrendingRepository, postService postservice.PostService, cursors *pagination.Codec) TrendingService {
+	return &trendingService{repo: repo, postService: postService, cursors: cursors}
+}
+
+func normalizeWindow(window string) (string, error) {
+	w := strings.ToLower(strings.TrimSpace(window))
+	if w == "" {
+		return models.DefaultWindow, nil
+	}
+	for _, win := range models.Windows {
+		if win.Key == w {
+			return w, nil
+		}
+	}
+	return "", models.ErrInvalidWindow
+}
+
+// รีเฟรชทุกช่วงเวลา คืนจำนวนโพสต์ที่มีคะแนนรวมทุกช่วง
+func (s *trendingService) RefreshAll() (int, error) {
+	var (
+		total int
+		errs  []error
+	)
+	for _, w := range models.Windows {
+		n, err := s.repo.Refresh(w)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("window %s: %w", w.Key, err))
+			continue
+		}
+		total += n
+	}
+	return total, errors.Join(errs...)
+}
+
+// cursor เก็บอันดับสุดท้ายของหน้า (Keyset.Offset)
+func (s *trendingService) ListPosts(viewerID int, window, cursor string, limit int) (*models.TrendingPostPage, error) {
+	window, err := normalizeWindow(window)
+	if err != nil {
+		return nil, err
+	}
+	var after pagination.Keyset
+	if _, err := s.cursors.Decode(cursor, &after); err != nil {
+		return nil, err
+	}
+
+	limit = pagination.ClampLimit(limit)
+	ranked, err := s.repo.ListPosts(window, after.Offset, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.TrendingPostPage{Window: window}
+	if page.RefreshedAt, err = s.repo.RefreshedAt(window); err != nil {
+		return nil, err
+	}
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+		if page.NextCursor, err = s.cursors.Encode(pagination.Keyset{Offset: ranked[limit-1].Rank}); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int, len(ranked))
+	for i, rp := range ranked {
+		ids[i] = rp.PostID
+	}
+	if page.Items, err = s.postService.GetPostsByIDs(viewerID, ids); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (s *trendingService) ListTags(window string, limit int) (*models.TrendingTagList, error) {
+	window, err := normalizeWindow(window)
+	if err != nil {
+		return nil, err
+	}
+	limit = pagination.ClampLimit(limit)
+
+	list := &models.TrendingTagList{Window: window}
+	if list.RefreshedAt, err = s.repo.RefreshedAt(window); err != nil {
+		return nil, err
+	}
+	if list.Items, err = s.repo.ListTags(window, limit); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
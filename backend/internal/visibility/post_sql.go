@@ -0,0 +1,37 @@
+package visibility
+
+// เงื่อนไข SQL ของการมองเห็นโพสต์ ใช้ร่วมกันทุก query ที่ดึงโพสต์ให้ผู้ชม
+// ข้อตกลง: โพสต์ใช้ alias p และ $1 = user_id ของผู้ชม
+
+// โพสต์ที่ยังไม่อยู่ในถังขยะ (ใช้กับ query ของผู้เขียนเอง เช่น draft, ดูโพสต์เดี่ยว)
+const PostNotTrashed = `p.post_deleted_at IS NULL`
+
+// โพสต์ที่เผยแพร่แล้ว ไม่อยู่ในถังขยะ และไม่ถูกซ่อนจากการรายงาน
+// (draft / scheduled / ลบแล้ว / ถูกซ่อน ไม่ขึ้นใน feed, ค้นหา, ยอดนิยม, แนะนำ)
+const PostPublished = `(p.post_status = 'published' AND ` + PostNotTrashed + ` AND p.post_hidden_at IS NULL)`
+
+// ผู้ชมเห็นโพสต์ได้ไหม: ผู้เขียนเห็นเสมอ
+// public = ทุกคน, friends = เพื่อนของผู้เขียน, private = ผู้เขียนเท่านั้น,
+// list = สมาชิกของ audience list ที่ผู้เขียนเลือก (list ถูกลบ = เหลือแค่ผู้เขียน)
+const PostVisible = `(
+	p.post_author_user_id = $1
+	OR p.post_visibility = 'public'
+	OR ( p.post_visibility = 'friends'
+		AND EXISTS (
+			SELECT 1
+			FROM friendships f
+			WHERE
+				f.user_id  = LEAST(p.post_author_user_id, $1)
+				AND f.friend_id = GREATEST(p.post_author_user_id, $1)
+		)
+	)
+	OR ( p.post_visibility = 'list'
+		AND EXISTS (
+			SELECT 1
+			FROM audience_list_members alm
+			WHERE
+				alm.member_list_id = p.post_audience_list_id
+				AND alm.member_user_id = $1
+		)
+	)
+)`
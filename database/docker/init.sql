@@ -312,5 +312,549 @@ CREATE INDEX IF NOT EXISTS ix_document_features_stylevec_hnsw
   USING hnsw (style_vector_v16 vector_cosine_ops)
   WHERE style_vector_v16 IS NOT NULL;
 
+-- pgvector HNSW index (content embedding) ใช้คัดผู้สมัครของ similar posts
+CREATE INDEX IF NOT EXISTS ix_document_features_content_hnsw
+  ON document_features
+  USING hnsw (content_embedding vector_cosine_ops)
+  WHERE content_embedding IS NOT NULL;
+
+
+-- ตารางเก็บ feedback ของผู้ใช้ต่อระบบแนะนำ (ไม่สนใจโพสต์ / ซ่อนผู้เขียน / ซ่อนแท็ก)
+create table if not exists recommend_feedback (
+    feedback_id         serial primary key,
+    feedback_user_id    integer not null references users(user_id) on delete cascade, -- เจ้าของ feedback
+    feedback_type       varchar(20) not null
+                        check (feedback_type in ('not_interested','hide_author','hide_tag')),
+    feedback_post_id    integer references posts(post_id) on delete set null,         -- โพสต์ที่กด feedback (ลบโพสต์แล้วกฎ hide_author / hide_tag ยังอยู่)
+    feedback_author_id  integer references users(user_id) on delete cascade,          -- ผู้เขียนที่ถูกซ่อน
+    feedback_tag_id     integer references tags(tag_id) on delete cascade,            -- แท็กที่ถูกซ่อน
+    feedback_created_at timestamptz default now()
+);
+
+-- กันซ้ำแยกตามประเภท
+create unique index if not exists uq_recommend_feedback_post
+  on recommend_feedback(feedback_user_id, feedback_post_id) where feedback_type = 'not_interested';
+create unique index if not exists uq_recommend_feedback_author
+  on recommend_feedback(feedback_user_id, feedback_author_id) where feedback_type = 'hide_author';
+create unique index if not exists uq_recommend_feedback_tag
+  on recommend_feedback(feedback_user_id, feedback_tag_id) where feedback_type = 'hide_tag';
+
+-- not_interested ผูกกับโพสต์โดยตรง: ลบโพสต์ = ลบ feedback นี้ด้วย (ประเภทอื่นแค่ set null ตาม FK)
+create or replace function delete_post_feedback() returns trigger as $$
+begin
+    delete from recommend_feedback
+    where feedback_post_id = old.post_id and feedback_type = 'not_interested';
+    return old;
+end;
+$$ language plpgsql;
+
+drop trigger if exists trg_delete_post_feedback on posts;
+create trigger trg_delete_post_feedback
+before delete on posts
+for each row execute function delete_post_feedback();
+
+
+-- ตาราง item-item similarity จาก likes + saved_posts (collaborative filtering)
+-- เติมโดย background job ใน backend (recommend service)
+create table if not exists post_similarity (
+    post_id          integer not null references posts(post_id) on delete cascade,
+    similar_post_id  integer not null references posts(post_id) on delete cascade,
+    similarity       double precision not null,   -- cosine ของ co-occurrence
+    co_count         integer not null,            -- จำนวนผู้ใช้ที่ like/save ทั้งสองโพสต์
+    updated_at       timestamptz not null default now(),
+    primary key (post_id, similar_post_id),
+    check (post_id <> similar_post_id)
+);
+
+create index if not exists ix_post_similarity_post_score
+  on post_similarity(post_id, similarity desc);
+
+-- เวลาที่คำนวณ post_similarity ล่าสุด (แถวเดียว) ใช้หาโพสต์ที่มี like/save ใหม่
+create table if not exists post_similarity_refreshes (
+    refresh_key  boolean primary key default true check (refresh_key),
+    refreshed_at timestamptz not null default now()
+);
+
+-- บทบาทผู้ใช้ (ใช้กับ endpoint ของ admin)
+alter table users add column if not exists user_role varchar(20) not null default 'user';
+
+do $$
+begin
+  if not exists (select 1 from pg_constraint where conname = 'users_user_role_check') then
+    alter table users add constraint users_user_role_check check (user_role in ('user','admin'));
+  end if;
+end
+$$ language plpgsql;
+
+-- mapping หัวข้อ ↔ แท็ก (โพสต์อยู่ในหัวข้อผ่านแท็กของโพสต์)
+create table if not exists topic_tags (
+    topic_tag_topic_id integer not null references topics(topic_id) on delete cascade,
+    topic_tag_tag_id   integer not null references tags(tag_id) on delete cascade,
+    primary key (topic_tag_topic_id, topic_tag_tag_id)
+);
+create index if not exists ix_topic_tags_tag on topic_tags(topic_tag_tag_id);
+
+-- แท็กใหม่ที่ชื่อตรงกับชื่อหัวข้อ จะถูกผูกเข้าหัวข้อนั้นอัตโนมัติ (เช่น topic "Math" ← tag "math")
+-- ไม่ใช้ prefix เพราะจะผูกผิด (topic "art" ← tag "article")
+create or replace function map_tag_to_topics()
+returns trigger as $$
+begin
+    insert into topic_tags (topic_tag_topic_id, topic_tag_tag_id)
+    select tp.topic_id, new.tag_id
+    from topics tp
+    where new.tag_name = lower(tp.topic_name)
+    on conflict do nothing;
+    return new;
+end;
+$$ language plpgsql;
+
+drop trigger if exists trg_tags_map_topics on tags;
+create trigger trg_tags_map_topics
+after insert on tags
+for each row
+execute function map_tag_to_topics();
+
+-- ผูกแท็กที่มีอยู่ก่อนสร้าง trigger (รันซ้ำได้)
+insert into topic_tags (topic_tag_topic_id, topic_tag_tag_id)
+select tp.topic_id, t.tag_id
+from topics tp
+join tags t on t.tag_name = lower(tp.topic_name)
+on conflict do nothing;
+
+-- เวอร์ชันโมเดล clustering ที่กำหนด cluster_id ล่าสุด (NULL = มาจาก Colab / ยังไม่ทำ)
+alter table document_features add column if not exists cluster_model_version varchar(60);
+
+-- ประวัติการรัน k-means ใน backend (แยกตาม style_label)
+create table if not exists document_cluster_runs (
+    run_id         serial primary key,
+    model_version  varchar(60) not null,
+    style_label    varchar(20) not null,
+    k              integer not null,
+    doc_count      integer not null,
+    inertia        double precision,
+    iterations     integer,
+    run_created_at timestamptz not null default now()
+);
+create index if not exists ix_document_cluster_runs_created on document_cluster_runs(run_created_at desc);
+
+-- cache ผลแนะนำต่อผู้ใช้ (materialized) เรียงตาม rank
+create table if not exists recommend_cache (
+    cache_user_id    integer not null references users(user_id) on delete cascade,
+    cache_rank       integer not null,                                          -- ลำดับ 1..N
+    cache_post_id    integer not null references posts(post_id) on delete cascade,
+    primary key (cache_user_id, cache_rank)
+);
+
+-- สถานะ cache ต่อผู้ใช้ (TTL + ถูก invalidate หลัง like/save/follow)
+create table if not exists recommend_cache_meta (
+    meta_user_id      integer primary key references users(user_id) on delete cascade,
+    meta_generated_at timestamptz not null default now(),
+    meta_expires_at   timestamptz not null,
+    meta_stale        boolean not null default false
+);
+
+-- A/B experiments: ทดลองกลยุทธ์การจัดอันดับต่อ surface (recommend / popular / search)
+create table if not exists experiments (
+    experiment_id         serial primary key,
+    experiment_key        varchar(50) not null unique,
+    experiment_surface    varchar(20) not null
+        check (experiment_surface in ('recommend', 'popular', 'search')),
+    experiment_status     varchar(10) not null default 'draft'
+        check (experiment_status in ('draft', 'running', 'stopped')),
+    experiment_created_at timestamptz not null default now(),
+    experiment_started_at timestamptz,
+    experiment_stopped_at timestamptz
+);
+-- รันได้ทีละ experiment ต่อ surface
+create unique index if not exists uq_experiments_running_surface
+    on experiments(experiment_surface) where experiment_status = 'running';
+
+-- variant ของแต่ละ experiment (weight ใช้แบ่งสัดส่วนผู้ใช้, config = พารามิเตอร์ของกลยุทธ์)
+create table if not exists experiment_variants (
+    variant_id            serial primary key,
+    variant_experiment_id integer not null references experiments(experiment_id) on delete cascade,
+    variant_key           varchar(30) not null,
+    variant_weight        integer not null default 1 check (variant_weight > 0),
+    variant_config        jsonb not null default '{}'::jsonb,
+    unique (variant_experiment_id, variant_key)
+);
+
+-- โพสต์ที่ถูกแสดงให้ผู้ใช้เห็นภายใต้ variant
+create table if not exists experiment_impressions (
+    impression_id            bigserial primary key,
+    impression_experiment_id integer not null references experiments(experiment_id) on delete cascade,
+    impression_variant_key   varchar(30) not null,
+    impression_user_id       integer not null references users(user_id) on delete cascade,
+    impression_post_id       integer not null references posts(post_id) on delete cascade,
+    impression_position      integer not null,
+    impression_created_at    timestamptz not null default now()
+);
+create index if not exists ix_experiment_impressions_exp
+    on experiment_impressions(impression_experiment_id, impression_variant_key, impression_user_id, impression_post_id);
+
+-- การคลิกโพสต์จาก surface ที่อยู่ใน experiment
+create table if not exists experiment_clicks (
+    click_id            bigserial primary key,
+    click_experiment_id integer not null references experiments(experiment_id) on delete cascade,
+    click_variant_key   varchar(30) not null,
+    click_user_id       integer not null references users(user_id) on delete cascade,
+    click_post_id       integer not null references posts(post_id) on delete cascade,
+    click_created_at    timestamptz not null default now()
+);
+create index if not exists ix_experiment_clicks_exp
+    on experiment_clicks(click_experiment_id, click_variant_key, click_user_id, click_post_id);
+
+-- keyset pagination ของรายการโพสต์ (post_created_at, post_id) เรียงใหม่ → เก่า
+create index if not exists ix_posts_created_id on posts(post_created_at desc, post_id desc);
+
+-- แท็กที่ผู้ใช้ติดตาม (ใช้เป็นแหล่งโพสต์ของ home feed แบบ ranked)
+create table if not exists tag_follows (
+    tag_follow_user_id    integer not null references users(user_id) on delete cascade,
+    tag_follow_tag_id     integer not null references tags(tag_id) on delete cascade,
+    tag_follow_created_at timestamptz not null default now(),
+    primary key (tag_follow_user_id, tag_follow_tag_id)
+);
+create index if not exists ix_tag_follows_tag on tag_follows(tag_follow_tag_id);
+
+-- snapshot ลำดับของ home feed แบบ ranked ต่อการเปิดฟีดหนึ่งครั้ง
+-- หน้าถัดไปอ่านจาก snapshot เดิม จึงไม่มีโพสต์ซ้ำข้ามหน้าแม้คะแนนเปลี่ยน
+create table if not exists home_feed_snapshots (
+    snapshot_id         bigserial primary key,
+    snapshot_user_id    integer not null references users(user_id) on delete cascade,
+    snapshot_created_at timestamptz not null default now()
+);
+create index if not exists ix_home_feed_snapshots_user on home_feed_snapshots(snapshot_user_id, snapshot_created_at);
+
+create table if not exists home_feed_items (
+    item_snapshot_id bigint not null references home_feed_snapshots(snapshot_id) on delete cascade,
+    item_rank        integer not null,
+    item_post_id     integer not null references posts(post_id) on delete cascade,
+    primary key (item_snapshot_id, item_rank)
+);
+create index if not exists ix_home_feed_items_post on home_feed_items(item_snapshot_id, item_post_id);
+
+-- จำนวนคอมเมนต์ (รวม reply) ต่อโพสต์
+alter table post_stats add column if not exists post_comment_count integer default 0;
+
+-- คอมเมนต์บนโพสต์ + reply ได้หนึ่งชั้น (comment_parent_id ชี้ไปคอมเมนต์ระดับบนเท่านั้น)
+create table if not exists comments (
+    comment_id         serial primary key,
+    comment_post_id    integer not null references posts(post_id) on delete cascade,
+    comment_user_id    integer not null references users(user_id) on delete cascade,
+    comment_parent_id  integer references comments(comment_id) on delete cascade,
+    comment_body       text not null check (length(comment_body) between 1 and 2000),
+    comment_created_at timestamptz not null default now(),
+    comment_updated_at timestamptz not null default now(),
+    comment_edited     boolean not null default false
+);
+create index if not exists ix_comments_post_top
+    on comments(comment_post_id, comment_created_at, comment_id) where comment_parent_id is null;
+create index if not exists ix_comments_parent
+    on comments(comment_parent_id, comment_created_at, comment_id) where comment_parent_id is not null;
+
+-- annotation บนหน้าเอกสาร PDF (ยึดตำแหน่งด้วย bbox หรือช่วงข้อความบนหน้า)
+-- reply (annotation_parent_id) ใช้ตำแหน่งและ visibility ของ annotation แม่
+create table if not exists annotations (
+    annotation_id          serial primary key,
+    annotation_document_id integer not null references documents(document_id) on delete cascade,
+    annotation_user_id     integer not null references users(user_id) on delete cascade,
+    annotation_parent_id   integer references annotations(annotation_id) on delete cascade,
+    annotation_page        integer not null check (annotation_page >= 1),
+    -- bbox แบบ normalized (0..1 ของความกว้าง/สูงหน้า)
+    annotation_bbox_x      double precision,
+    annotation_bbox_y      double precision,
+    annotation_bbox_w      double precision,
+    annotation_bbox_h      double precision,
+    -- ช่วงข้อความใน text layer ของหน้า (offset ตัวอักษร) + ข้อความที่อ้างถึง
+    annotation_text_start  integer,
+    annotation_text_end    integer,
+    annotation_quote       text,
+    annotation_body        text not null check (length(annotation_body) between 1 and 2000),
+    annotation_visibility  varchar(10) not null default 'public'
+        check (annotation_visibility in ('public', 'friends', 'private')),
+    annotation_created_at  timestamptz not null default now(),
+    annotation_updated_at  timestamptz not null default now(),
+    check (annotation_text_start is null or (annotation_text_end is not null and annotation_text_end >= annotation_text_start))
+);
+create index if not exists ix_annotations_doc_page on annotations(annotation_document_id, annotation_page);
+create index if not exists ix_annotations_parent on annotations(annotation_parent_id) where annotation_parent_id is not null;
+
+-- สถานะการเผยแพร่โพสต์: draft = ร่าง, scheduled = รอเผยแพร่ตาม post_publish_at
+-- ทุก feed / ค้นหา / แนะนำ แสดงเฉพาะ published
+alter table posts add column if not exists post_status varchar(10) not null default 'published';
+alter table posts add column if not exists post_publish_at timestamptz;
+
+do $$
+begin
+  if not exists (select 1 from pg_constraint where conname = 'posts_post_status_check') then
+    alter table posts add constraint posts_post_status_check
+      check (post_status in ('draft','scheduled','published'));
+  end if;
+end
+$$ language plpgsql;
+
+create index if not exists ix_posts_scheduled on posts(post_publish_at) where post_status = 'scheduled';
+create index if not exists ix_posts_author_unpublished
+    on posts(post_author_user_id, post_updated_at desc) where post_status <> 'published';
+
+-- audience list: กลุ่มผู้ชมที่ผู้เขียนตั้งชื่อเอง ใช้กับโพสต์ visibility = 'list'
+create table if not exists audience_lists (
+    list_id            serial primary key,
+    list_owner_user_id integer not null references users(user_id) on delete cascade,
+    list_name          varchar(60) not null,
+    list_created_at    timestamptz not null default now(),
+    unique (list_owner_user_id, list_name)
+);
+
+create table if not exists audience_list_members (
+    member_list_id  integer not null references audience_lists(list_id) on delete cascade,
+    member_user_id  integer not null references users(user_id) on delete cascade,
+    member_added_at timestamptz not null default now(),
+    primary key (member_list_id, member_user_id)
+);
+create index if not exists ix_audience_list_members_user on audience_list_members(member_user_id);
+
+-- visibility เพิ่ม private (ผู้เขียนเท่านั้น) และ list (สมาชิกของ audience list)
+alter table posts add column if not exists post_audience_list_id integer
+    references audience_lists(list_id) on delete set null;
+alter table posts drop constraint if exists posts_post_visibility_check;
+alter table posts add constraint posts_post_visibility_check
+    check (post_visibility in ('public','friends','private','list'));
+create index if not exists ix_posts_audience_list on posts(post_audience_list_id) where post_audience_list_id is not null;
+
+-- ชนิดไฟล์: pdf (สกัด feature / สรุปได้) หรือ image
+alter table documents add column if not exists document_kind varchar(10) not null default 'pdf';
+
+do $$
+begin
+  if not exists (select 1 from pg_constraint where conname = 'documents_document_kind_check') then
+    alter table documents add constraint documents_document_kind_check
+      check (document_kind in ('pdf','image'));
+  end if;
+end
+$$ language plpgsql;
+
+-- ไฟล์แนบของโพสต์ (หลายไฟล์ เรียงตาม attachment_position)
+-- posts.post_document_id = เอกสารหลัก (PDF แรก) ใช้กับ feature / similar / recommend
+create table if not exists post_documents (
+    attachment_post_id     integer not null references posts(post_id) on delete cascade,
+    attachment_document_id integer not null references documents(document_id) on delete cascade,
+    attachment_position    integer not null check (attachment_position >= 0),
+    primary key (attachment_post_id, attachment_document_id),
+    unique (attachment_post_id, attachment_position)
+);
+create index if not exists ix_post_documents_document on post_documents(attachment_document_id);
+
+-- โพสต์เดิมมีไฟล์เดียว
+insert into post_documents (attachment_post_id, attachment_document_id, attachment_position)
+select post_id, post_document_id, 0 from posts where post_document_id is not null
+on conflict do nothing;
+
+-- ประวัติการแก้ไขโพสต์: ทุกครั้งที่แก้จะเก็บสถานะก่อนแก้ไว้หนึ่งแถว
+alter table posts add column if not exists post_edited_at timestamptz; -- แก้ไขหลังเผยแพร่ล่าสุด
+
+create table if not exists post_revisions (
+    revision_id               serial primary key,
+    revision_post_id          integer not null references posts(post_id) on delete cascade,
+    revision_number           integer not null,
+    revision_title            varchar(120) not null,
+    revision_description      text,
+    revision_visibility       varchar(10) not null,
+    revision_audience_list_id integer,
+    revision_tags             text[] not null default '{}',
+    revision_attachment_ids   integer[] not null default '{}',
+    revision_created_at       timestamptz not null default now(),
+    unique (revision_post_id, revision_number)
+);
+
+-- ถังขยะ: ลบแล้วกู้คืนได้ภายใน 30 วัน หลังจากนั้น purge job ลบจริง (รวมไฟล์ใน storage)
+alter table posts add column if not exists post_deleted_at timestamptz;
+alter table documents add column if not exists document_deleted_at timestamptz;
+create index if not exists ix_posts_trash on posts(post_deleted_at) where post_deleted_at is not null;
+create index if not exists ix_documents_trash on documents(document_deleted_at) where document_deleted_at is not null;
+
+-- รีโพสต์ (แชร์โพสต์ของคนอื่นให้ผู้ติดตาม พร้อมข้อความได้) หายไปพร้อมโพสต์ต้นทาง
+-- ผู้ติดตามเห็นรีโพสต์ได้ก็ต่อเมื่อเห็นโพสต์ต้นทางได้ตาม visibility ของต้นทาง
+create table if not exists reposts (
+    repost_id         serial primary key,
+    repost_user_id    integer not null references users(user_id) on delete cascade,
+    repost_post_id    integer not null references posts(post_id) on delete cascade,
+    repost_quote      text check (length(repost_quote) <= 500),
+    repost_created_at timestamptz not null default now(),
+    unique (repost_user_id, repost_post_id)
+);
+create index if not exists ix_reposts_post on reposts(repost_post_id);
+create index if not exists ix_reposts_user_created on reposts(repost_user_id, repost_created_at desc);
+
+alter table post_stats add column if not exists post_repost_count integer default 0;
+
+-- collection ของโพสต์ที่บันทึก (โฟลเดอร์) โพสต์ที่บันทึกอยู่ได้ collection เดียว
+-- save_collection_id = NULL = ยังไม่จัดเข้า collection
+create table if not exists save_collections (
+    collection_id            serial primary key,
+    collection_owner_user_id integer not null references users(user_id) on delete cascade,
+    collection_name          varchar(60) not null,
+    collection_visibility    varchar(10) not null default 'private'
+                             check (collection_visibility in ('private','friends','public')),
+    collection_created_at    timestamptz not null default now(),
+    collection_updated_at    timestamptz not null default now(),
+    unique (collection_owner_user_id, collection_name)
+);
+
+alter table saved_posts add column if not exists save_collection_id integer
+    references save_collections(collection_id) on delete set null;
+alter table saved_posts add column if not exists save_position integer not null default 0; -- ลำดับใน collection
+create index if not exists ix_saved_posts_collection on saved_posts(save_collection_id, save_position)
+    where save_collection_id is not null;
+
+-- คะแนน trending ต่อช่วงเวลา (24h / 7d / 30d) รีเฟรชเป็นระยะโดย background job
+-- คะแนน = ผลรวมน้ำหนักกิจกรรม × exp(-ln2 × อายุ / half-life) เฉพาะกิจกรรมในช่วงเวลา
+create table if not exists trending_posts (
+    trending_window  varchar(3) not null check (trending_window in ('24h','7d','30d')),
+    trending_post_id integer not null references posts(post_id) on delete cascade,
+    trending_score   double precision not null,
+    trending_rank    integer not null,
+    primary key (trending_window, trending_post_id)
+);
+create index if not exists ix_trending_posts_rank on trending_posts(trending_window, trending_rank);
+
+create table if not exists trending_tags (
+    trending_window     varchar(3) not null check (trending_window in ('24h','7d','30d')),
+    trending_tag_id     integer not null references tags(tag_id) on delete cascade,
+    trending_score      double precision not null,
+    trending_post_count integer not null, -- จำนวนโพสต์ที่มีกิจกรรมในช่วงเวลา
+    trending_rank       integer not null,
+    primary key (trending_window, trending_tag_id)
+);
+create index if not exists ix_trending_tags_rank on trending_tags(trending_window, trending_rank);
+
+-- เวลารีเฟรชล่าสุดของแต่ละช่วงเวลา
+create table if not exists trending_refreshes (
+    refresh_window varchar(3) primary key,
+    refreshed_at   timestamptz not null default now()
+);
+
+-- สแกนกิจกรรมตามช่วงเวลา
+create index if not exists ix_likes_created on likes(like_created_at);
+create index if not exists ix_saved_posts_created on saved_posts(save_created_at);
+create index if not exists ix_comments_created on comments(comment_created_at);
+create index if not exists ix_reposts_created on reposts(repost_created_at);
+
+-- ชื่อพ้องของแท็ก (calc, แคลคูลัส → calculus) ชื่อ alias ต้องไม่ซ้ำกับแท็กจริง
+create table if not exists tag_aliases (
+    alias_name       varchar(50) primary key,
+    alias_tag_id     integer not null references tags(tag_id) on delete cascade, -- แท็ก canonical
+    alias_created_by integer references users(user_id) on delete set null,
+    alias_created_at timestamptz not null default now()
+);
+create index if not exists ix_tag_aliases_tag on tag_aliases(alias_tag_id);
+
+-- ชื่อแท็กที่ห้ามใช้ (แบนแล้วแท็กเดิมถูกลบออกจากทุกโพสต์)
+create table if not exists banned_tags (
+    banned_name       varchar(50) primary key,
+    banned_reason     text,
+    banned_by_user_id integer references users(user_id) on delete set null,
+    banned_at         timestamptz not null default now()
+);
+
+-- เหตุการณ์เปิดดู / ดาวน์โหลดโพสต์ (ไม่นับผู้เขียนเอง)
+-- กันซ้ำ: ผู้ใช้หนึ่งคนนับได้ครั้งเดียวต่อโพสต์ต่อประเภทต่อวัน, source = ที่มาของครั้งแรกของวัน
+create table if not exists post_view_events (
+    event_id         bigserial primary key,
+    event_post_id    integer not null references posts(post_id) on delete cascade,
+    event_user_id    integer not null references users(user_id) on delete cascade,
+    event_kind       varchar(10) not null check (event_kind in ('view','download')),
+    event_source     varchar(20) not null default 'direct', -- feed / search / profile / tag / ...
+    event_day        date not null default current_date,
+    event_created_at timestamptz not null default now(),
+    unique (event_post_id, event_user_id, event_kind, event_day)
+);
+create index if not exists ix_post_view_events_day on post_view_events(event_day);
+create index if not exists ix_post_view_events_created on post_view_events(event_created_at);
+create index if not exists ix_post_view_events_user on post_view_events(event_user_id, event_post_id, event_created_at);
+
+-- rollup รายวันต่อโพสต์ (สร้างใหม่จาก events / likes / saved_posts / follows เป็นระยะ)
+-- stat_follower_gain = ผู้ติดตามใหม่ของผู้เขียนที่เปิดดูโพสต์นี้ภายใน 24 ชม. ก่อนกดติดตาม
+create table if not exists post_daily_stats (
+    stat_post_id       integer not null references posts(post_id) on delete cascade,
+    stat_day           date not null,
+    stat_views         integer not null default 0,
+    stat_downloads     integer not null default 0,
+    stat_likes         integer not null default 0,
+    stat_saves         integer not null default 0,
+    stat_follower_gain integer not null default 0,
+    primary key (stat_post_id, stat_day)
+);
+create index if not exists ix_post_daily_stats_day on post_daily_stats(stat_day);
+
+-- จำนวนการเปิดดูรายวันแยกตามที่มา
+create table if not exists post_daily_sources (
+    stat_post_id integer not null references posts(post_id) on delete cascade,
+    stat_day     date not null,
+    stat_source  varchar(20) not null,
+    stat_views   integer not null default 0,
+    primary key (stat_post_id, stat_day, stat_source)
+);
+create index if not exists ix_post_daily_sources_day on post_daily_sources(stat_day);
+
+-- reaction อื่นนอกจาก like (reaction ค่าเริ่มต้น "like" ยังเก็บในตาราง likes เพื่อให้ของเดิมใช้ได้ต่อ)
+-- ผู้ใช้หนึ่งคนกดได้หลายแบบต่อโพสต์ แบบละครั้ง
+create table if not exists reactions (
+    reaction_user_id    integer not null references users(user_id) on delete cascade,
+    reaction_post_id    integer not null references posts(post_id) on delete cascade,
+    reaction_type       varchar(20) not null check (reaction_type in ('helpful','clear','thanks')),
+    reaction_created_at timestamptz not null default now(),
+    primary key (reaction_user_id, reaction_post_id, reaction_type)
+);
+create index if not exists ix_reactions_post on reactions(reaction_post_id);
+create index if not exists ix_reactions_created on reactions(reaction_created_at);
+
+-- จำนวน reaction ต่อแบบ เช่น {"helpful": 3, "thanks": 1} (like ใช้ post_like_count)
+alter table post_stats add column if not exists post_reaction_counts jsonb not null default '{}'::jsonb;
+
+-- การรายงานเนื้อหา (โพสต์ / คอมเมนต์ / ผู้ใช้)
+-- กันซ้ำ: ผู้รายงานหนึ่งคนมีรายงานที่ยังเปิดอยู่ได้ครั้งเดียวต่อเป้าหมาย (รายงานซ้ำ = อัปเดตเหตุผล)
+-- report_action = ผลการตัดสินของผู้ดูแล (dismiss / hide / suspend)
+create table if not exists reports (
+    report_id          bigserial primary key,
+    report_reporter_id integer not null references users(user_id) on delete cascade,
+    report_target_type varchar(10) not null check (report_target_type in ('post','comment','user')),
+    report_target_id   integer not null,
+    report_reason      varchar(20) not null check (report_reason in ('spam','plagiarism','inappropriate','harassment','other')),
+    report_details     varchar(1000),
+    report_status      varchar(10) not null default 'open' check (report_status in ('open','dismissed','actioned')),
+    report_action      varchar(10) check (report_action in ('dismiss','hide','suspend')),
+    report_note        varchar(1000),
+    report_resolved_by integer references users(user_id) on delete set null,
+    report_resolved_at timestamptz,
+    report_created_at  timestamptz not null default now(),
+    report_updated_at  timestamptz not null default now()
+);
+create unique index if not exists ux_reports_open_per_reporter
+    on reports(report_reporter_id, report_target_type, report_target_id)
+    where report_status = 'open';
+create index if not exists ix_reports_target on reports(report_target_type, report_target_id, report_status);
+create index if not exists ix_reports_open_created on reports(report_created_at) where report_status = 'open';
+
+-- ซ่อนเนื้อหาที่ถูกรายงาน (อัตโนมัติเมื่อครบจำนวน หรือโดยผู้ดูแล)
+alter table posts add column if not exists post_hidden_at timestamptz;
+alter table comments add column if not exists comment_hidden_at timestamptz;
+-- ที่มาของการซ่อน: auto = ครบจำนวนรายงาน, moderator = ผู้ดูแลสั่งซ่อน (dismiss เลิกซ่อนได้เฉพาะ auto)
+alter table posts add column if not exists post_hidden_reason varchar(10);
+alter table comments add column if not exists comment_hidden_reason varchar(10);
+
+-- การแจ้งเตือนถึงผู้ใช้ เช่น ผลการพิจารณารายงาน
+create table if not exists notifications (
+    notification_id         bigserial primary key,
+    notification_user_id    integer not null references users(user_id) on delete cascade,
+    notification_type       varchar(30) not null,
+    notification_message    text not null,
+    notification_data       jsonb not null default '{}'::jsonb,
+    notification_read_at    timestamptz,
+    notification_created_at timestamptz not null default now()
+);
+create index if not exists ix_notifications_user_created on notifications(notification_user_id, notification_created_at desc, notification_id desc);
+create index if not exists ix_notifications_unread on notifications(notification_user_id) where notification_read_at is null;
+
 
 commit;
\ No newline at end of file